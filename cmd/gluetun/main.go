@@ -25,9 +25,11 @@ import (
 	"github.com/qdm12/gluetun/internal/constants"
 	copenvpn "github.com/qdm12/gluetun/internal/constants/openvpn"
 	"github.com/qdm12/gluetun/internal/dns"
+	"github.com/qdm12/gluetun/internal/events"
 	"github.com/qdm12/gluetun/internal/firewall"
 	"github.com/qdm12/gluetun/internal/healthcheck"
 	"github.com/qdm12/gluetun/internal/httpproxy"
+	"github.com/qdm12/gluetun/internal/leaktest"
 	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/netlink"
 	"github.com/qdm12/gluetun/internal/openvpn"
@@ -39,6 +41,8 @@ import (
 	"github.com/qdm12/gluetun/internal/routing"
 	"github.com/qdm12/gluetun/internal/server"
 	"github.com/qdm12/gluetun/internal/shadowsocks"
+	"github.com/qdm12/gluetun/internal/socks5"
+	"github.com/qdm12/gluetun/internal/splittunnel"
 	"github.com/qdm12/gluetun/internal/storage"
 	"github.com/qdm12/gluetun/internal/tun"
 	updater "github.com/qdm12/gluetun/internal/updater/loop"
@@ -159,8 +163,12 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 			return cli.Update(ctx, args[2:], logger)
 		case "format-servers":
 			return cli.FormatServers(args[2:])
+		case "servers":
+			return cli.Servers(args[2:], logger)
 		case "genkey":
 			return cli.GenKey(args[2:])
+		case "leaktest":
+			return cli.LeakTest(ctx, reader, netLinker, logger)
 		default:
 			return fmt.Errorf("%w: %s", errCommandUnknown, args[1])
 		}
@@ -227,7 +235,7 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 		firewallLogger.Patch(log.SetLevel(log.LevelDebug))
 	}
 	firewallConf, err := firewall.NewConfig(ctx, firewallLogger, cmder,
-		defaultRoutes, localNetworks)
+		defaultRoutes, localNetworks, allSettings.Firewall.Backend)
 	if err != nil {
 		return err
 	}
@@ -260,6 +268,12 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 		return err
 	}
 
+	err = storage.SetBlacklistSettings(*allSettings.Storage.BlacklistDuration,
+		*allSettings.Storage.BlacklistFailures)
+	if err != nil {
+		return fmt.Errorf("setting servers blacklist: %w", err)
+	}
+
 	allSettings.Pprof.HTTPServer.Logger = logger.New(log.SetComponent("pprof"))
 	pprofServer, err := pprof.New(allSettings.Pprof)
 	if err != nil {
@@ -389,17 +403,32 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 		<-pprofReady
 	}
 
+	eventsBroker := events.New()
+
 	portForwardLogger := logger.New(log.SetComponent("port forwarding"))
 	portForwardLooper := portforward.NewLoop(allSettings.VPN.Provider.PortForwarding,
-		routingConf, httpClient, firewallConf, portForwardLogger, cmder, puid, pgid)
+		routingConf, httpClient, firewallConf, portForwardLogger, cmder, eventsBroker, puid, pgid)
 	portForwardRunError, err := portForwardLooper.Start(ctx)
 	if err != nil {
 		return fmt.Errorf("starting port forwarding loop: %w", err)
 	}
 
 	dnsLogger := logger.New(log.SetComponent("dns"))
+	splitTunnel := splittunnel.New(allSettings.Firewall.OutboundDomains,
+		allSettings.Firewall.OutboundSubnets, firewallConf, routingConf,
+		logger.New(log.SetComponent("split tunnel")))
+	if len(allSettings.Firewall.OutboundDomains) > 0 &&
+		(!*allSettings.DNS.ServerEnabled || *allSettings.DNS.KeepNameserver) {
+		logger.Warn("outbound domains require the built-in DNS server to be enabled " +
+			"and used as nameserver, so they will not be routed outside the VPN")
+	}
+	splitTunnelHandler, splitTunnelCtx, splitTunnelDone := goshutdown.NewGoRoutineHandler(
+		"split tunnel", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go splitTunnel.Run(splitTunnelCtx, splitTunnelDone)
+	tickersGroupHandler.Add(splitTunnelHandler)
+
 	dnsLooper, err := dns.NewLoop(allSettings.DNS, httpClient,
-		dnsLogger)
+		splitTunnel, dnsLogger)
 	if err != nil {
 		return fmt.Errorf("creating DNS loop: %w", err)
 	}
@@ -416,7 +445,7 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	controlGroupHandler.Add(dnsTickerHandler)
 
 	publicIPLooper, err := publicip.NewLoop(allSettings.PublicIP, puid, pgid, httpClient,
-		logger.New(log.SetComponent("ip getter")))
+		eventsBroker, logger.New(log.SetComponent("ip getter")))
 	if err != nil {
 		return fmt.Errorf("creating public ip loop: %w", err)
 	}
@@ -444,14 +473,14 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	vpnLogger := logger.New(log.SetComponent("vpn"))
 	vpnLooper := vpn.NewLoop(allSettings.VPN, ipv6Supported, allSettings.Firewall.VPNInputPorts,
 		providers, storage, allSettings.Health, healthChecker, healthcheckServer, ovpnConf, netLinker, firewallConf,
-		routingConf, portForwardLooper, cmder, publicIPLooper, dnsLooper, vpnLogger, httpClient,
+		routingConf, portForwardLooper, cmder, publicIPLooper, dnsLooper, eventsBroker, vpnLogger, httpClient,
 		buildInfo, *allSettings.Version.Enabled)
 	vpnHandler, vpnCtx, vpnDone := goshutdown.NewGoRoutineHandler(
 		"vpn", goroutine.OptionTimeout(time.Second))
 	go vpnLooper.Run(vpnCtx, vpnDone)
 
 	updaterLooper := updater.NewLoop(allSettings.Updater,
-		providers, storage, httpClient, updaterLogger)
+		providers, storage, httpClient, eventsBroker, updaterLogger)
 	updaterHandler, updaterCtx, updaterDone := goshutdown.NewGoRoutineHandler(
 		"updater", goroutine.OptionTimeout(defaultShutdownTimeout))
 	// wait for updaterLooper.Restart() or its ticket launched with RunRestartTicker
@@ -478,12 +507,21 @@ func _main(ctx context.Context, buildInfo models.BuildInformation,
 	go shadowsocksLooper.Run(shadowsocksCtx, shadowsocksDone)
 	otherGroupHandler.Add(shadowsocksHandler)
 
+	socks5Looper := socks5.NewLoop(logger.New(log.SetComponent("socks5 proxy")),
+		allSettings.Socks5)
+	socks5Handler, socks5Ctx, socks5Done := goshutdown.NewGoRoutineHandler(
+		"socks5 proxy", goroutine.OptionTimeout(defaultShutdownTimeout))
+	go socks5Looper.Run(socks5Ctx, socks5Done)
+	otherGroupHandler.Add(socks5Handler)
+
+	leakTester := leaktest.New(defaultRoutes, firewallConf)
+
 	httpServerHandler, httpServerCtx, httpServerDone := goshutdown.NewGoRoutineHandler(
 		"http server", goroutine.OptionTimeout(defaultShutdownTimeout))
 	httpServer, err := server.New(httpServerCtx, allSettings.ControlServer,
 		logger.New(log.SetComponent("http server")),
 		buildInfo, vpnLooper, portForwardLooper, dnsLooper, updaterLooper, publicIPLooper,
-		storage, ipv6Supported)
+		healthChecker, httpProxyLooper, shadowsocksLooper, socks5Looper, eventsBroker, leakTester, storage, ipv6Supported)
 	if err != nil {
 		return fmt.Errorf("setting up control server: %w", err)
 	}
@@ -597,10 +635,13 @@ type Linker interface {
 type clier interface {
 	ClientKey(args []string) error
 	FormatServers(args []string) error
+	Servers(args []string, logger cli.ServersLogger) error
 	OpenvpnConfig(logger cli.OpenvpnConfigLogger, reader *reader.Reader, ipv6Checker cli.IPv6Checker) error
 	HealthCheck(ctx context.Context, reader *reader.Reader, warner cli.Warner) error
 	Update(ctx context.Context, args []string, logger cli.UpdaterLogger) error
 	GenKey(args []string) error
+	LeakTest(ctx context.Context, reader *reader.Reader, netLinker routing.NetLinker,
+		logger cli.LeakTestLogger) error
 }
 
 type Tun interface {
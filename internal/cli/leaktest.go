@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/leaktest"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/qdm12/gosettings/reader"
+)
+
+type LeakTestLogger interface {
+	Debug(s string)
+	Info(s string)
+	Warn(s string)
+	Error(s string)
+}
+
+var ErrLeakDetected = errors.New("leak detected")
+
+// LeakTest sends packets through the default route interfaces, bypassing
+// the VPN interface, and prints a JSON report of the packets which escaped.
+// Since the firewall state of the running gluetun process is not available,
+// the firewall settings are used to explain the results.
+func (c *CLI) LeakTest(ctx context.Context, reader *reader.Reader,
+	netLinker routing.NetLinker, logger LeakTestLogger,
+) error {
+	var allSettings settings.Settings
+	err := allSettings.Read(reader, logger)
+	if err != nil {
+		return err
+	}
+	allSettings.SetDefaults()
+
+	defaultRoutes, err := routing.New(netLinker, logger).DefaultRoutes()
+	if err != nil {
+		return fmt.Errorf("getting default routes: %w", err)
+	}
+
+	firewall := firewallSettingsState{settings: allSettings.Firewall}
+	report := leaktest.New(defaultRoutes, firewall).Run(ctx)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return fmt.Errorf("encoding report: %w", err)
+	}
+
+	if report.Leaked {
+		return fmt.Errorf("%w", ErrLeakDetected)
+	}
+	return nil
+}
+
+type firewallSettingsState struct {
+	settings settings.Firewall
+}
+
+func (f firewallSettingsState) GetState() (state models.FirewallState) {
+	return models.FirewallState{
+		Enabled:         *f.settings.Enabled,
+		OutboundSubnets: f.settings.OutboundSubnets,
+	}
+}
@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/storage"
+)
+
+var (
+	ErrServersCommandMissing = errors.New("servers command is missing, it can be 'export' or 'import'")
+	ErrServersCommandUnknown = errors.New("servers command is unknown")
+	ErrProviderNotValid      = errors.New("provider is not valid")
+)
+
+type ServersLogger interface {
+	Info(s string)
+	Warn(s string)
+}
+
+// Servers runs the servers command given as first argument,
+// which can be 'export' or 'import'.
+func (c *CLI) Servers(args []string, logger ServersLogger) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w", ErrServersCommandMissing)
+	}
+
+	switch args[0] {
+	case "export":
+		return c.serversExport(args[1:], logger)
+	case "import":
+		return c.serversImport(args[1:], logger)
+	default:
+		return fmt.Errorf("%w: %s", ErrServersCommandUnknown, args[0])
+	}
+}
+
+func (c *CLI) serversExport(args []string, logger ServersLogger) error {
+	var exportAll bool
+	var csvProviders, output, signKeyPath string
+	flagSet := flag.NewFlagSet("servers export", flag.ExitOnError)
+	flagSet.BoolVar(&exportAll, "all", false, "Export servers for all VPN providers")
+	flagSet.StringVar(&csvProviders, "providers", "", "CSV string of VPN providers to export servers for")
+	flagSet.StringVar(&output, "output", "/dev/stdout", "Output file to write the servers bundle to")
+	flagSet.StringVar(&signKeyPath, "sign-key", "",
+		"Path to a PEM encoded ed25519 private key file to sign the bundle with")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	var providerNames []string
+	if exportAll {
+		providerNames = providers.All()
+	} else {
+		if csvProviders == "" {
+			return fmt.Errorf("%w", ErrNoProviderSpecified)
+		}
+		providerNames = strings.Split(csvProviders, ",")
+		allProviders := providers.All()
+		for _, provider := range providerNames {
+			if !slices.Contains(allProviders, provider) {
+				return fmt.Errorf("%w: %s", ErrProviderNotValid, provider)
+			}
+		}
+	}
+
+	var privateKey ed25519.PrivateKey
+	if signKeyPath != "" {
+		var err error
+		privateKey, err = readEd25519PrivateKey(signKeyPath)
+		if err != nil {
+			return fmt.Errorf("reading signing key: %w", err)
+		}
+	}
+
+	storage, err := storage.New(logger, constants.ServersData)
+	if err != nil {
+		return fmt.Errorf("creating servers storage: %w", err)
+	}
+
+	serversData, err := storage.ExportServers(providerNames)
+	if err != nil {
+		return fmt.Errorf("exporting servers: %w", err)
+	}
+
+	bundle := newServersBundle(serversData, privateKey)
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding servers bundle: %w", err)
+	}
+	data = append(data, '\n')
+
+	output = filepath.Clean(output)
+	const permission = fs.FileMode(0o644)
+	err = os.WriteFile(output, data, permission)
+	if err != nil {
+		return fmt.Errorf("writing servers bundle: %w", err)
+	}
+
+	return nil
+}
+
+func (c *CLI) serversImport(args []string, logger ServersLogger) error {
+	var input, verifyKeyPath string
+	flagSet := flag.NewFlagSet("servers import", flag.ExitOnError)
+	flagSet.StringVar(&input, "input", "/dev/stdin", "Input file to read the servers bundle from")
+	flagSet.StringVar(&verifyKeyPath, "verify-key", "",
+		"Path to a PEM encoded ed25519 public key file to verify the bundle signature with")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	var publicKey ed25519.PublicKey
+	if verifyKeyPath != "" {
+		var err error
+		publicKey, err = readEd25519PublicKey(verifyKeyPath)
+		if err != nil {
+			return fmt.Errorf("reading verification key: %w", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Clean(input))
+	if err != nil {
+		return fmt.Errorf("reading servers bundle: %w", err)
+	}
+
+	var bundle serversBundle
+	err = json.Unmarshal(data, &bundle)
+	if err != nil {
+		return fmt.Errorf("decoding servers bundle: %w", err)
+	}
+
+	err = bundle.verify(publicKey)
+	if err != nil {
+		return fmt.Errorf("verifying servers bundle: %w", err)
+	} else if publicKey == nil && bundle.Signature != "" {
+		logger.Warn("servers bundle is signed but its signature is not verified " +
+			"since no verification key is specified")
+	}
+
+	storage, err := storage.New(logger, constants.ServersData)
+	if err != nil {
+		return fmt.Errorf("creating servers storage: %w", err)
+	}
+
+	importedProviders, err := storage.ImportServers(bundle.Servers)
+	if err != nil {
+		return fmt.Errorf("importing servers: %w", err)
+	}
+	logger.Info("imported servers for providers: " + strings.Join(importedProviders, ", "))
+
+	return nil
+}
+
+// serversBundle is the format used to move servers data between hosts.
+type serversBundle struct {
+	// Servers is the servers data encoded in the servers file format.
+	Servers json.RawMessage `json:"servers"`
+	// SHA256 is the hex encoded SHA256 checksum of the servers data.
+	SHA256 string `json:"sha256"`
+	// Signature is the optional base64 encoded ed25519 signature
+	// of the servers data.
+	Signature string `json:"signature,omitempty"`
+}
+
+func newServersBundle(serversData []byte, privateKey ed25519.PrivateKey) (bundle serversBundle) {
+	checksum := sha256.Sum256(serversData)
+	bundle = serversBundle{
+		Servers: serversData,
+		SHA256:  hex.EncodeToString(checksum[:]),
+	}
+	if privateKey != nil {
+		signature := ed25519.Sign(privateKey, serversData)
+		bundle.Signature = base64.StdEncoding.EncodeToString(signature)
+	}
+	return bundle
+}
+
+var (
+	ErrBundleChecksumMismatch  = errors.New("servers bundle checksum mismatch")
+	ErrBundleNotSigned         = errors.New("servers bundle is not signed")
+	ErrBundleSignatureNotValid = errors.New("servers bundle signature is not valid")
+)
+
+// verify verifies the checksum of the bundle and, if the public key
+// is not nil, its signature.
+func (b *serversBundle) verify(publicKey ed25519.PublicKey) (err error) {
+	checksum := sha256.Sum256(b.Servers)
+	if hex.EncodeToString(checksum[:]) != strings.ToLower(b.SHA256) {
+		return fmt.Errorf("%w", ErrBundleChecksumMismatch)
+	}
+
+	if publicKey == nil {
+		return nil
+	} else if b.Signature == "" {
+		return fmt.Errorf("%w", ErrBundleNotSigned)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(b.Signature)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+	if !ed25519.Verify(publicKey, b.Servers, signature) {
+		return fmt.Errorf("%w", ErrBundleSignatureNotValid)
+	}
+	return nil
+}
+
+var (
+	ErrPEMBlockNotFound = errors.New("no PEM block found")
+	ErrKeyNotEd25519    = errors.New("key is not an ed25519 key")
+)
+
+// readEd25519PrivateKey reads a PEM encoded PKCS8 ed25519 private key
+// file, such as created with `openssl genpkey -algorithm ed25519`.
+func readEd25519PrivateKey(path string) (privateKey ed25519.PrivateKey, err error) {
+	der, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrKeyNotEd25519, key)
+	}
+	return privateKey, nil
+}
+
+// readEd25519PublicKey reads a PEM encoded PKIX ed25519 public key
+// file, such as created with `openssl pkey -pubout`.
+func readEd25519PublicKey(path string) (publicKey ed25519.PublicKey, err error) {
+	der, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrKeyNotEd25519, key)
+	}
+	return publicKey, nil
+}
+
+func readPEMFile(path string) (der []byte, err error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: in %s", ErrPEMBlockNotFound, path)
+	}
+	return block.Bytes, nil
+}
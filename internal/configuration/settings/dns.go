@@ -36,6 +36,9 @@ type DNS struct {
 	// Blacklist contains settings to configure the filter
 	// block lists.
 	Blacklist DNSBlacklist
+	// QueryLog contains settings to log DNS queries
+	// to a file.
+	QueryLog DNSQueryLog
 	// ServerAddress is the DNS server to use inside
 	// the Go program and for the system.
 	// It defaults to '127.0.0.1' to be used with the
@@ -56,8 +59,9 @@ type DNS struct {
 }
 
 var (
-	ErrDNSUpstreamTypeNotValid = errors.New("DNS upstream type is not valid")
-	ErrDNSUpdatePeriodTooShort = errors.New("update period is too short")
+	ErrDNSUpstreamTypeNotValid    = errors.New("DNS upstream type is not valid")
+	ErrDNSUpdatePeriodTooShort    = errors.New("update period is too short")
+	ErrDNSQueryLogMaxSizeTooSmall = errors.New("query log maximum size is too small")
 )
 
 func (d DNS) validate() (err error) {
@@ -84,6 +88,11 @@ func (d DNS) validate() (err error) {
 		return err
 	}
 
+	err = d.QueryLog.validate()
+	if err != nil {
+		return fmt.Errorf("query log: %w", err)
+	}
+
 	return nil
 }
 
@@ -96,6 +105,7 @@ func (d *DNS) Copy() (copied DNS) {
 		Caching:        gosettings.CopyPointer(d.Caching),
 		IPv6:           gosettings.CopyPointer(d.IPv6),
 		Blacklist:      d.Blacklist.copy(),
+		QueryLog:       d.QueryLog.copy(),
 		ServerAddress:  d.ServerAddress,
 		KeepNameserver: gosettings.CopyPointer(d.KeepNameserver),
 	}
@@ -112,6 +122,7 @@ func (d *DNS) overrideWith(other DNS) {
 	d.Caching = gosettings.OverrideWithPointer(d.Caching, other.Caching)
 	d.IPv6 = gosettings.OverrideWithPointer(d.IPv6, other.IPv6)
 	d.Blacklist.overrideWith(other.Blacklist)
+	d.QueryLog.overrideWith(other.QueryLog)
 	d.ServerAddress = gosettings.OverrideWithValidator(d.ServerAddress, other.ServerAddress)
 	d.KeepNameserver = gosettings.OverrideWithPointer(d.KeepNameserver, other.KeepNameserver)
 }
@@ -127,6 +138,7 @@ func (d *DNS) setDefaults() {
 	d.Caching = gosettings.DefaultPointer(d.Caching, true)
 	d.IPv6 = gosettings.DefaultPointer(d.IPv6, false)
 	d.Blacklist.setDefaults()
+	d.QueryLog.setDefaults()
 	d.ServerAddress = gosettings.DefaultValidator(d.ServerAddress,
 		netip.AddrFrom4([4]byte{127, 0, 0, 1}))
 	d.KeepNameserver = gosettings.DefaultPointer(d.KeepNameserver, false)
@@ -184,6 +196,10 @@ func (d DNS) toLinesNode() (node *gotree.Node) {
 
 	node.AppendNode(d.Blacklist.toLinesNode())
 
+	if queryLogNode := d.QueryLog.toLinesNode(); queryLogNode != nil {
+		node.AppendNode(queryLogNode)
+	}
+
 	return node
 }
 
@@ -217,6 +233,11 @@ func (d *DNS) read(r *reader.Reader) (err error) {
 		return err
 	}
 
+	err = d.QueryLog.read(r)
+	if err != nil {
+		return err
+	}
+
 	d.ServerAddress, err = r.NetipAddr("DNS_ADDRESS", reader.RetroKeys("DNS_PLAINTEXT_ADDRESS"))
 	if err != nil {
 		return err
@@ -0,0 +1,90 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gosettings"
+	"github.com/qdm12/gosettings/reader"
+	"github.com/qdm12/gotree"
+)
+
+// DNSQueryLog contains settings to log DNS queries
+// answered by the DNS server to a file.
+type DNSQueryLog struct {
+	// Path is the path of the file to log queries to,
+	// as JSON lines. It defaults to the empty string
+	// which disables query logging, and cannot be nil
+	// in the internal state.
+	Path *string
+	// MaxSize is the size in bytes above which the
+	// query log file is rotated. It defaults to 10MB
+	// and cannot be nil in the internal state.
+	MaxSize *uint64
+	// MaxBackups is the number of rotated query log files
+	// to keep. It can be set to 0 to keep no rotated file.
+	// It defaults to 3 and cannot be nil in the internal state.
+	MaxBackups *uint
+}
+
+func (q *DNSQueryLog) setDefaults() {
+	q.Path = gosettings.DefaultPointer(q.Path, "")
+	const defaultMaxSize = 10 * 1000 * 1000
+	q.MaxSize = gosettings.DefaultPointer(q.MaxSize, defaultMaxSize)
+	const defaultMaxBackups = 3
+	q.MaxBackups = gosettings.DefaultPointer(q.MaxBackups, defaultMaxBackups)
+}
+
+func (q DNSQueryLog) validate() (err error) {
+	const minMaxSize = 1000
+	if *q.MaxSize < minMaxSize {
+		return fmt.Errorf("%w: %d bytes must be at least %d bytes",
+			ErrDNSQueryLogMaxSizeTooSmall, *q.MaxSize, minMaxSize)
+	}
+	return nil
+}
+
+func (q DNSQueryLog) copy() (copied DNSQueryLog) {
+	return DNSQueryLog{
+		Path:       gosettings.CopyPointer(q.Path),
+		MaxSize:    gosettings.CopyPointer(q.MaxSize),
+		MaxBackups: gosettings.CopyPointer(q.MaxBackups),
+	}
+}
+
+func (q *DNSQueryLog) overrideWith(other DNSQueryLog) {
+	q.Path = gosettings.OverrideWithPointer(q.Path, other.Path)
+	q.MaxSize = gosettings.OverrideWithPointer(q.MaxSize, other.MaxSize)
+	q.MaxBackups = gosettings.OverrideWithPointer(q.MaxBackups, other.MaxBackups)
+}
+
+func (q DNSQueryLog) String() string {
+	return q.toLinesNode().String()
+}
+
+func (q DNSQueryLog) toLinesNode() (node *gotree.Node) {
+	if *q.Path == "" {
+		return nil
+	}
+
+	node = gotree.New("Query log settings:")
+	node.Appendf("File path: %s", *q.Path)
+	node.Appendf("Maximum size: %d bytes", *q.MaxSize)
+	node.Appendf("Maximum backups: %d", *q.MaxBackups)
+	return node
+}
+
+func (q *DNSQueryLog) read(r *reader.Reader) (err error) {
+	q.Path = r.Get("DNS_QUERY_LOG_PATH", reader.ForceLowercase(false))
+
+	q.MaxSize, err = r.Uint64Ptr("DNS_QUERY_LOG_MAX_SIZE")
+	if err != nil {
+		return err
+	}
+
+	q.MaxBackups, err = r.UintPtr("DNS_QUERY_LOG_MAX_BACKUPS")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -10,7 +10,9 @@ var (
 	ErrCountryNotValid                 = errors.New("the country specified is not valid")
 	ErrFilepathMissing                 = errors.New("filepath is missing")
 	ErrFirewallZeroPort                = errors.New("cannot have a zero port")
+	ErrFirewallBackendNotValid         = errors.New("firewall backend is not valid")
 	ErrFirewallPublicOutboundSubnet    = errors.New("outbound subnet has an unspecified address")
+	ErrFirewallOutboundDomainNotValid  = errors.New("outbound domain is not valid")
 	ErrHostnameNotValid                = errors.New("the hostname specified is not valid")
 	ErrISPNotValid                     = errors.New("the ISP specified is not valid")
 	ErrMinRatioNotValid                = errors.New("minimum ratio is not valid")
@@ -30,11 +32,16 @@ var (
 	ErrPortForwardingEnabled           = errors.New("port forwarding cannot be enabled")
 	ErrPortForwardingUserEmpty         = errors.New("port forwarding username is empty")
 	ErrPortForwardingPasswordEmpty     = errors.New("port forwarding password is empty")
+	ErrPortForwardingHeaderNotValid    = errors.New("port forwarding webhook header is not valid")
 	ErrRegionNotValid                  = errors.New("the region specified is not valid")
 	ErrServerAddressNotValid           = errors.New("server listening address is not valid")
+	ErrSocks5UserMissing               = errors.New("user is missing but password is set")
+	ErrSocks5UserTooLong               = errors.New("user is too long")
+	ErrSocks5PasswordTooLong           = errors.New("password is too long")
 	ErrSystemPGIDNotValid              = errors.New("process group id is not valid")
 	ErrSystemPUIDNotValid              = errors.New("process user id is not valid")
 	ErrSystemTimezoneNotValid          = errors.New("timezone is not valid")
+	ErrURLNotValid                     = errors.New("URL is not valid")
 	ErrUpdaterPeriodTooSmall           = errors.New("VPN server data updater period is too small")
 	ErrUpdaterProtonPasswordMissing    = errors.New("proton password is missing")
 	ErrUpdaterProtonEmailMissing       = errors.New("proton email is missing")
@@ -3,9 +3,11 @@ package settings
 import (
 	"fmt"
 	"net/netip"
+	"strings"
 
 	"github.com/qdm12/gosettings"
 	"github.com/qdm12/gosettings/reader"
+	"github.com/qdm12/gosettings/validate"
 	"github.com/qdm12/gotree"
 )
 
@@ -14,10 +16,28 @@ type Firewall struct {
 	VPNInputPorts   []uint16
 	InputPorts      []uint16
 	OutboundSubnets []netip.Prefix
+	// OutboundDomains is a list of domain names whose resolved IP
+	// addresses, as observed by the built-in DNS server, are routed
+	// outside the VPN tunnel until their DNS record TTL expires.
+	// It can contain parent domains which are of the form
+	// "*.example.com". Note the wildcard can only be used at the
+	// start of the domain name.
+	OutboundDomains []string
 	Enabled         *bool
 	Debug           *bool
+	// Backend is the firewall implementation to use, and can be
+	// 'auto', 'iptables' or 'nftables'. The 'auto' backend uses
+	// iptables if available, and falls back on nftables otherwise.
+	// It cannot be the empty string in the internal state.
+	Backend string
 }
 
+const (
+	FirewallBackendAuto     = "auto"
+	FirewallBackendIptables = "iptables"
+	FirewallBackendNftables = "nftables"
+)
+
 func (f Firewall) validate() (err error) {
 	if hasZeroPort(f.VPNInputPorts) {
 		return fmt.Errorf("VPN input ports: %w", ErrFirewallZeroPort)
@@ -33,6 +53,19 @@ func (f Firewall) validate() (err error) {
 		}
 	}
 
+	for _, domain := range f.OutboundDomains {
+		host := strings.TrimPrefix(domain, "*.")
+		if !hostRegex.MatchString(host) {
+			return fmt.Errorf("%w: %s", ErrFirewallOutboundDomainNotValid, domain)
+		}
+	}
+
+	err = validate.IsOneOf(f.Backend, FirewallBackendAuto,
+		FirewallBackendIptables, FirewallBackendNftables)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFirewallBackendNotValid, err)
+	}
+
 	return nil
 }
 
@@ -50,8 +83,10 @@ func (f *Firewall) copy() (copied Firewall) {
 		VPNInputPorts:   gosettings.CopySlice(f.VPNInputPorts),
 		InputPorts:      gosettings.CopySlice(f.InputPorts),
 		OutboundSubnets: gosettings.CopySlice(f.OutboundSubnets),
+		OutboundDomains: gosettings.CopySlice(f.OutboundDomains),
 		Enabled:         gosettings.CopyPointer(f.Enabled),
 		Debug:           gosettings.CopyPointer(f.Debug),
+		Backend:         f.Backend,
 	}
 }
 
@@ -62,13 +97,16 @@ func (f *Firewall) overrideWith(other Firewall) {
 	f.VPNInputPorts = gosettings.OverrideWithSlice(f.VPNInputPorts, other.VPNInputPorts)
 	f.InputPorts = gosettings.OverrideWithSlice(f.InputPorts, other.InputPorts)
 	f.OutboundSubnets = gosettings.OverrideWithSlice(f.OutboundSubnets, other.OutboundSubnets)
+	f.OutboundDomains = gosettings.OverrideWithSlice(f.OutboundDomains, other.OutboundDomains)
 	f.Enabled = gosettings.OverrideWithPointer(f.Enabled, other.Enabled)
 	f.Debug = gosettings.OverrideWithPointer(f.Debug, other.Debug)
+	f.Backend = gosettings.OverrideWithComparable(f.Backend, other.Backend)
 }
 
 func (f *Firewall) setDefaults() {
 	f.Enabled = gosettings.DefaultPointer(f.Enabled, true)
 	f.Debug = gosettings.DefaultPointer(f.Debug, false)
+	f.Backend = gosettings.DefaultComparable(f.Backend, FirewallBackendAuto)
 }
 
 func (f Firewall) String() string {
@@ -83,6 +121,10 @@ func (f Firewall) toLinesNode() (node *gotree.Node) {
 		return node
 	}
 
+	if f.Backend != FirewallBackendAuto {
+		node.Appendf("Backend: %s", f.Backend)
+	}
+
 	if *f.Debug {
 		node.Appendf("Debug mode: on")
 	}
@@ -108,6 +150,13 @@ func (f Firewall) toLinesNode() (node *gotree.Node) {
 		}
 	}
 
+	if len(f.OutboundDomains) > 0 {
+		outboundDomains := node.Appendf("Outbound domains:")
+		for _, domain := range f.OutboundDomains {
+			outboundDomains.Appendf("%s", domain)
+		}
+	}
+
 	return node
 }
 
@@ -128,6 +177,8 @@ func (f *Firewall) read(r *reader.Reader) (err error) {
 		return err
 	}
 
+	f.OutboundDomains = r.CSV("FIREWALL_OUTBOUND_DOMAINS")
+
 	f.Enabled, err = r.BoolPtr("FIREWALL_ENABLED_DISABLING_IT_SHOOTS_YOU_IN_YOUR_FOOT")
 	if err != nil {
 		return err
@@ -138,5 +189,7 @@ func (f *Firewall) read(r *reader.Reader) (err error) {
 		return err
 	}
 
+	f.Backend = r.String("FIREWALL_BACKEND")
+
 	return nil
 }
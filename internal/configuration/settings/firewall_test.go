@@ -15,7 +15,11 @@ func Test_Firewall_validate(t *testing.T) {
 		errWrapped error
 		errMessage string
 	}{
-		"empty": {},
+		"empty": {
+			firewall: Firewall{
+				Backend: FirewallBackendAuto,
+			},
+		},
 		"zero_vpn_input_port": {
 			firewall: Firewall{
 				VPNInputPorts: []uint16{0},
@@ -44,8 +48,16 @@ func Test_Firewall_validate(t *testing.T) {
 				OutboundSubnets: []netip.Prefix{
 					netip.MustParsePrefix("1.2.3.4/32"),
 				},
+				Backend: FirewallBackendAuto,
 			},
 		},
+		"outbound_domain_wildcard_not_at_start": {
+			firewall: Firewall{
+				OutboundDomains: []string{"example.*.com"},
+			},
+			errWrapped: ErrFirewallOutboundDomainNotValid,
+			errMessage: "outbound domain is not valid: example.*.com",
+		},
 		"valid_settings": {
 			firewall: Firewall{
 				VPNInputPorts: []uint16{100, 101},
@@ -54,7 +66,17 @@ func Test_Firewall_validate(t *testing.T) {
 					netip.MustParsePrefix("192.168.1.0/24"),
 					netip.MustParsePrefix("10.10.1.1/32"),
 				},
+				OutboundDomains: []string{"example.com", "*.example.org"},
+				Backend:         FirewallBackendNftables,
+			},
+		},
+		"backend_not_valid": {
+			firewall: Firewall{
+				Backend: "ufw",
 			},
+			errWrapped: ErrFirewallBackendNotValid,
+			errMessage: "firewall backend is not valid: value is not one of the possible choices: " +
+				"ufw must be one of auto, iptables or nftables",
 		},
 	}
 
@@ -88,7 +88,7 @@ type OpenVPN struct {
 
 var ivpnAccountID = regexp.MustCompile(`^(i|ivpn)\-[a-zA-Z0-9]{4}\-[a-zA-Z0-9]{4}\-[a-zA-Z0-9]{4}$`)
 
-func (o OpenVPN) validate(vpnProvider string) (err error) {
+func (o OpenVPN) validate(vpnProvider string, customConfigDir bool) (err error) {
 	// Validate version
 	validVersions := []string{openvpn.Openvpn25, openvpn.Openvpn26}
 	if err = validate.IsOneOf(o.Version, validVersions...); err != nil {
@@ -111,7 +111,7 @@ func (o OpenVPN) validate(vpnProvider string) (err error) {
 		return fmt.Errorf("%w", ErrOpenVPNPasswordIsEmpty)
 	}
 
-	err = validateOpenVPNConfigFilepath(isCustom, *o.ConfFile)
+	err = validateOpenVPNConfigFilepath(isCustom && !customConfigDir, *o.ConfFile)
 	if err != nil {
 		return fmt.Errorf("custom configuration file: %w", err)
 	}
@@ -45,6 +45,9 @@ type PortForwarding struct {
 	Username string `json:"username"`
 	// Password is only used for Private Internet Access port forwarding.
 	Password string `json:"password"`
+	// Hooks contains settings to notify other programs
+	// of the forwarded ports.
+	Hooks PortForwardingHooks `json:"hooks"`
 }
 
 func (p PortForwarding) Validate(vpnProvider string) (err error) {
@@ -84,6 +87,11 @@ func (p PortForwarding) Validate(vpnProvider string) (err error) {
 		}
 	}
 
+	err = p.Hooks.validate()
+	if err != nil {
+		return fmt.Errorf("hooks: %w", err)
+	}
+
 	return nil
 }
 
@@ -97,6 +105,7 @@ func (p *PortForwarding) Copy() (copied PortForwarding) {
 		ListeningPort: gosettings.CopyPointer(p.ListeningPort),
 		Username:      p.Username,
 		Password:      p.Password,
+		Hooks:         p.Hooks.copy(),
 	}
 }
 
@@ -109,6 +118,7 @@ func (p *PortForwarding) OverrideWith(other PortForwarding) {
 	p.ListeningPort = gosettings.OverrideWithPointer(p.ListeningPort, other.ListeningPort)
 	p.Username = gosettings.OverrideWithComparable(p.Username, other.Username)
 	p.Password = gosettings.OverrideWithComparable(p.Password, other.Password)
+	p.Hooks.overrideWith(other.Hooks)
 }
 
 func (p *PortForwarding) setDefaults() {
@@ -118,6 +128,7 @@ func (p *PortForwarding) setDefaults() {
 	p.UpCommand = gosettings.DefaultPointer(p.UpCommand, "")
 	p.DownCommand = gosettings.DefaultPointer(p.DownCommand, "")
 	p.ListeningPort = gosettings.DefaultPointer(p.ListeningPort, 0)
+	p.Hooks.setDefaults()
 }
 
 func (p PortForwarding) String() string {
@@ -162,6 +173,8 @@ func (p PortForwarding) toLinesNode() (node *gotree.Node) {
 		credentialsNode.Appendf("Password: %s", gosettings.ObfuscateKey(p.Password))
 	}
 
+	p.Hooks.appendToNode(node)
+
 	return node
 }
 
@@ -211,5 +224,10 @@ func (p *PortForwarding) read(r *reader.Reader) (err error) {
 		}
 	}
 
+	err = p.Hooks.read(r)
+	if err != nil {
+		return fmt.Errorf("hooks: %w", err)
+	}
+
 	return nil
 }
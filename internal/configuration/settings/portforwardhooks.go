@@ -0,0 +1,222 @@
+package settings
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/qdm12/gosettings"
+	"github.com/qdm12/gosettings/reader"
+	"github.com/qdm12/gosettings/validate"
+	"github.com/qdm12/gotree"
+)
+
+// PortForwardingHooks contains settings to notify other programs
+// of the forwarded ports, using HTTP webhooks and built-in
+// integrations for BitTorrent clients.
+type PortForwardingHooks struct {
+	// WebhookURLs are the URLs to send an HTTP request to when
+	// the forwarded ports go up, go down or change.
+	// It defaults to an empty slice, meaning no webhook is used.
+	WebhookURLs []string `json:"webhook_urls"`
+	// WebhookMethod is the HTTP method to use for the webhook requests.
+	// It cannot be nil in the internal state.
+	WebhookMethod *string `json:"webhook_method"`
+	// WebhookHeaders are HTTP headers in the form `Name: value`
+	// to add to each webhook request.
+	WebhookHeaders []string `json:"webhook_headers"`
+	// WebhookBody is the template of the webhook request body.
+	// The placeholders {{EVENT}}, {{PORTS}}, {{PORT}},
+	// {{VPN_INTERFACE}} and {{VPN_SERVER}} are replaced before
+	// sending the request. It cannot be nil in the internal state.
+	WebhookBody *string `json:"webhook_body"`
+	// Retries is the number of retries for each failed webhook
+	// or integration call, with an exponential backoff between
+	// attempts. It cannot be nil in the internal state.
+	Retries *uint `json:"retries"`
+	// QBittorrent contains settings to set the listening port
+	// of a qBittorrent client using its web API.
+	QBittorrent PortForwardingClient `json:"qbittorrent"`
+	// Transmission contains settings to set the peer port
+	// of a Transmission client using its RPC API.
+	Transmission PortForwardingClient `json:"transmission"`
+	// Deluge contains settings to set the listening port
+	// of a Deluge client using its web JSON-RPC API.
+	// Its username field is ignored.
+	Deluge PortForwardingClient `json:"deluge"`
+}
+
+// PortForwardingClient contains settings to reach the web API
+// of a BitTorrent client.
+type PortForwardingClient struct {
+	// URL is the base URL of the client web API.
+	// It can be the empty string to disable the integration.
+	URL string `json:"url"`
+	// Username is the username to authenticate with.
+	Username string `json:"username"`
+	// Password is the password to authenticate with.
+	Password string `json:"password"`
+}
+
+const defaultPortForwardingWebhookBody = `{"event":"{{EVENT}}","ports":[{{PORTS}}],` +
+	`"interface":"{{VPN_INTERFACE}}","server":"{{VPN_SERVER}}"}`
+
+func (p PortForwardingHooks) validate() (err error) {
+	for _, webhookURL := range p.WebhookURLs {
+		err = validateHTTPURL(webhookURL)
+		if err != nil {
+			return fmt.Errorf("webhook URL: %w", err)
+		}
+	}
+
+	err = validate.IsOneOf(*p.WebhookMethod, http.MethodGet, http.MethodPost,
+		http.MethodPut, http.MethodPatch)
+	if err != nil {
+		return fmt.Errorf("webhook method: %w", err)
+	}
+
+	for _, header := range p.WebhookHeaders {
+		name, _, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%w: %s", ErrPortForwardingHeaderNotValid, header)
+		}
+	}
+
+	clients := map[string]PortForwardingClient{
+		"qBittorrent":  p.QBittorrent,
+		"Transmission": p.Transmission,
+		"Deluge":       p.Deluge,
+	}
+	for name, client := range clients {
+		if client.URL == "" {
+			continue
+		}
+		err = validateHTTPURL(client.URL)
+		if err != nil {
+			return fmt.Errorf("%s URL: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateHTTPURL(s string) (err error) {
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrURLNotValid, err)
+	}
+	switch {
+	case parsed.Scheme != "http" && parsed.Scheme != "https":
+		return fmt.Errorf("%w: scheme %q is not http or https", ErrURLNotValid, parsed.Scheme)
+	case parsed.Host == "":
+		return fmt.Errorf("%w: host is empty", ErrURLNotValid)
+	}
+	return nil
+}
+
+func (p *PortForwardingHooks) copy() (copied PortForwardingHooks) {
+	return PortForwardingHooks{
+		WebhookURLs:    gosettings.CopySlice(p.WebhookURLs),
+		WebhookMethod:  gosettings.CopyPointer(p.WebhookMethod),
+		WebhookHeaders: gosettings.CopySlice(p.WebhookHeaders),
+		WebhookBody:    gosettings.CopyPointer(p.WebhookBody),
+		Retries:        gosettings.CopyPointer(p.Retries),
+		QBittorrent:    p.QBittorrent,
+		Transmission:   p.Transmission,
+		Deluge:         p.Deluge,
+	}
+}
+
+func (p *PortForwardingHooks) overrideWith(other PortForwardingHooks) {
+	p.WebhookURLs = gosettings.OverrideWithSlice(p.WebhookURLs, other.WebhookURLs)
+	p.WebhookMethod = gosettings.OverrideWithPointer(p.WebhookMethod, other.WebhookMethod)
+	p.WebhookHeaders = gosettings.OverrideWithSlice(p.WebhookHeaders, other.WebhookHeaders)
+	p.WebhookBody = gosettings.OverrideWithPointer(p.WebhookBody, other.WebhookBody)
+	p.Retries = gosettings.OverrideWithPointer(p.Retries, other.Retries)
+	p.QBittorrent.overrideWith(other.QBittorrent)
+	p.Transmission.overrideWith(other.Transmission)
+	p.Deluge.overrideWith(other.Deluge)
+}
+
+func (p *PortForwardingClient) overrideWith(other PortForwardingClient) {
+	p.URL = gosettings.OverrideWithComparable(p.URL, other.URL)
+	p.Username = gosettings.OverrideWithComparable(p.Username, other.Username)
+	p.Password = gosettings.OverrideWithComparable(p.Password, other.Password)
+}
+
+func (p *PortForwardingHooks) setDefaults() {
+	p.WebhookURLs = gosettings.DefaultSlice(p.WebhookURLs, []string{})
+	p.WebhookMethod = gosettings.DefaultPointer(p.WebhookMethod, http.MethodPost)
+	p.WebhookHeaders = gosettings.DefaultSlice(p.WebhookHeaders, []string{})
+	p.WebhookBody = gosettings.DefaultPointer(p.WebhookBody, defaultPortForwardingWebhookBody)
+	const defaultRetries = 3
+	p.Retries = gosettings.DefaultPointer(p.Retries, defaultRetries)
+}
+
+// appendToNode appends the hooks settings to the port forwarding
+// node given, only for the hooks configured.
+func (p PortForwardingHooks) appendToNode(node *gotree.Node) {
+	if len(p.WebhookURLs) > 0 {
+		webhooksNode := node.Appendf("Webhooks:")
+		for _, webhookURL := range p.WebhookURLs {
+			webhooksNode.Appendf("%s %s", *p.WebhookMethod, webhookURL)
+		}
+	}
+
+	clients := []struct {
+		name   string
+		client PortForwardingClient
+	}{
+		{name: "qBittorrent", client: p.QBittorrent},
+		{name: "Transmission", client: p.Transmission},
+		{name: "Deluge", client: p.Deluge},
+	}
+	for _, client := range clients {
+		if client.client.URL == "" {
+			continue
+		}
+		clientNode := node.Appendf("%s integration:", client.name)
+		clientNode.Appendf("URL: %s", client.client.URL)
+		if client.client.Username != "" {
+			clientNode.Appendf("Username: %s", client.client.Username)
+		}
+		if client.client.Password != "" {
+			clientNode.Appendf("Password: %s", gosettings.ObfuscateKey(client.client.Password))
+		}
+	}
+
+	if len(p.WebhookURLs) > 0 || p.QBittorrent.URL != "" ||
+		p.Transmission.URL != "" || p.Deluge.URL != "" {
+		node.Appendf("Hooks retries: %d", *p.Retries)
+	}
+}
+
+func (p *PortForwardingHooks) read(r *reader.Reader) (err error) {
+	p.WebhookURLs = r.CSV("VPN_PORT_FORWARDING_WEBHOOK_URLS",
+		reader.ForceLowercase(false))
+	p.WebhookMethod = r.Get("VPN_PORT_FORWARDING_WEBHOOK_METHOD")
+	if p.WebhookMethod != nil {
+		*p.WebhookMethod = strings.ToUpper(*p.WebhookMethod)
+	}
+	p.WebhookHeaders = r.CSV("VPN_PORT_FORWARDING_WEBHOOK_HEADERS",
+		reader.ForceLowercase(false))
+	p.WebhookBody = r.Get("VPN_PORT_FORWARDING_WEBHOOK_BODY",
+		reader.ForceLowercase(false))
+
+	p.Retries, err = r.UintPtr("VPN_PORT_FORWARDING_HOOKS_RETRIES")
+	if err != nil {
+		return err
+	}
+
+	p.QBittorrent.read(r, "VPN_PORT_FORWARDING_QBITTORRENT")
+	p.Transmission.read(r, "VPN_PORT_FORWARDING_TRANSMISSION")
+	p.Deluge.read(r, "VPN_PORT_FORWARDING_DELUGE")
+	return nil
+}
+
+func (p *PortForwardingClient) read(r *reader.Reader, prefix string) {
+	p.URL = r.String(prefix+"_URL", reader.ForceLowercase(false))
+	p.Username = r.String(prefix+"_USERNAME", reader.ForceLowercase(false))
+	p.Password = r.String(prefix+"_PASSWORD", reader.ForceLowercase(false))
+}
@@ -0,0 +1,156 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gosettings"
+	"github.com/qdm12/gosettings/reader"
+	"github.com/qdm12/gosettings/validate"
+	"github.com/qdm12/gotree"
+)
+
+// Rotation contains settings to reconnect the VPN
+// to a different server on a schedule.
+type Rotation struct {
+	// Period is the period after which the VPN reconnects to a
+	// different server, counted from the time the tunnel is up.
+	// It cannot be nil in the internal state, and 0 disables
+	// the periodic rotation.
+	Period *time.Duration `json:"period"`
+	// Times is the list of times of the day, in the HH:MM format
+	// and in the local timezone, at which the VPN reconnects to
+	// a different server. It cannot be nil in the internal state.
+	Times []string `json:"times"`
+	// RequireDifferent is the public IP data which must differ
+	// after a rotation, and can be 'none', 'ip' or 'city'.
+	// If it does not differ, the rotation is retried a few times.
+	// It cannot be the empty string in the internal state.
+	RequireDifferent string `json:"require_different"`
+}
+
+const (
+	RotationRequireDifferentNone = "none"
+	RotationRequireDifferentIP   = "ip"
+	RotationRequireDifferentCity = "city"
+)
+
+// RotationTimeLayout is the layout of rotation times of the day.
+const RotationTimeLayout = "15:04"
+
+var (
+	ErrRotationPeriodTooSmall           = errors.New("rotation period is too small")
+	ErrRotationTimeNotValid             = errors.New("rotation time is not valid")
+	ErrRotationRequireDifferentNotValid = errors.New("rotation require different value is not valid")
+)
+
+func (rt Rotation) validate() (err error) {
+	const minPeriod = time.Minute
+	if *rt.Period != 0 && *rt.Period < minPeriod {
+		return fmt.Errorf("%w: %s must be at least %s",
+			ErrRotationPeriodTooSmall, *rt.Period, minPeriod)
+	}
+
+	for _, timeOfDay := range rt.Times {
+		_, err = time.Parse(RotationTimeLayout, timeOfDay)
+		if err != nil {
+			return fmt.Errorf("%w: %s does not match the HH:MM format",
+				ErrRotationTimeNotValid, timeOfDay)
+		}
+	}
+
+	err = validate.IsOneOf(rt.RequireDifferent, RotationRequireDifferentNone,
+		RotationRequireDifferentIP, RotationRequireDifferentCity)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRotationRequireDifferentNotValid, err)
+	}
+
+	return nil
+}
+
+// Enabled returns true if the periodic or the
+// times of the day rotation is enabled.
+func (rt Rotation) Enabled() bool {
+	return *rt.Period > 0 || len(rt.Times) > 0
+}
+
+// NextRotation returns the time of the next rotation after the
+// tunnel went up at the time given, or the zero time if the
+// rotation is disabled.
+func (rt Rotation) NextRotation(tunnelUpTime time.Time) (next time.Time) {
+	if *rt.Period > 0 {
+		next = tunnelUpTime.Add(*rt.Period)
+	}
+
+	for _, timeOfDay := range rt.Times {
+		parsed, err := time.Parse(RotationTimeLayout, timeOfDay)
+		if err != nil {
+			panic(fmt.Sprintf("rotation time is not valid: %s", err))
+		}
+		year, month, day := tunnelUpTime.Date()
+		candidate := time.Date(year, month, day, parsed.Hour(), parsed.Minute(),
+			0, 0, tunnelUpTime.Location())
+		if !candidate.After(tunnelUpTime) {
+			candidate = candidate.AddDate(0, 0, 1)
+		}
+		if next.IsZero() || candidate.Before(next) {
+			next = candidate
+		}
+	}
+
+	return next
+}
+
+func (rt *Rotation) copy() (copied Rotation) {
+	return Rotation{
+		Period:           gosettings.CopyPointer(rt.Period),
+		Times:            gosettings.CopySlice(rt.Times),
+		RequireDifferent: rt.RequireDifferent,
+	}
+}
+
+func (rt *Rotation) overrideWith(other Rotation) {
+	rt.Period = gosettings.OverrideWithPointer(rt.Period, other.Period)
+	rt.Times = gosettings.OverrideWithSlice(rt.Times, other.Times)
+	rt.RequireDifferent = gosettings.OverrideWithComparable(rt.RequireDifferent, other.RequireDifferent)
+}
+
+func (rt *Rotation) setDefaults() {
+	rt.Period = gosettings.DefaultPointer(rt.Period, 0)
+	rt.Times = gosettings.DefaultSlice(rt.Times, []string{})
+	rt.RequireDifferent = gosettings.DefaultComparable(rt.RequireDifferent, RotationRequireDifferentNone)
+}
+
+func (rt Rotation) String() string {
+	return rt.toLinesNode().String()
+}
+
+func (rt Rotation) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("Server rotation:")
+
+	if *rt.Period > 0 {
+		node.Appendf("Period: %s", *rt.Period)
+	}
+
+	if len(rt.Times) > 0 {
+		node.Appendf("Times of the day: %s", strings.Join(rt.Times, ", "))
+	}
+
+	node.Appendf("Require different: %s", rt.RequireDifferent)
+
+	return node
+}
+
+func (rt *Rotation) read(r *reader.Reader) (err error) {
+	rt.Period, err = r.DurationPtr("VPN_ROTATION_PERIOD")
+	if err != nil {
+		return err
+	}
+
+	rt.Times = r.CSV("VPN_ROTATION_TIMES")
+	rt.RequireDifferent = r.String("VPN_ROTATION_REQUIRE_DIFFERENT")
+
+	return nil
+}
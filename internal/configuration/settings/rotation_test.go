@@ -0,0 +1,54 @@
+package settings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Rotation_NextRotation(t *testing.T) {
+	t.Parallel()
+
+	tunnelUpTime := time.Date(2024, time.March, 10, 15, 30, 0, 0, time.UTC)
+	durationPtr := func(d time.Duration) *time.Duration { return &d }
+
+	testCases := map[string]struct {
+		rotation Rotation
+		next     time.Time
+	}{
+		"disabled": {
+			rotation: Rotation{Period: durationPtr(0)},
+		},
+		"period": {
+			rotation: Rotation{Period: durationPtr(time.Hour)},
+			next:     time.Date(2024, time.March, 10, 16, 30, 0, 0, time.UTC),
+		},
+		"time_later_today": {
+			rotation: Rotation{Period: durationPtr(0), Times: []string{"04:00", "18:15"}},
+			next:     time.Date(2024, time.March, 10, 18, 15, 0, 0, time.UTC),
+		},
+		"time_tomorrow": {
+			rotation: Rotation{Period: durationPtr(0), Times: []string{"04:00", "15:30"}},
+			next:     time.Date(2024, time.March, 11, 4, 0, 0, 0, time.UTC),
+		},
+		"period_before_time": {
+			rotation: Rotation{Period: durationPtr(time.Hour), Times: []string{"18:15"}},
+			next:     time.Date(2024, time.March, 10, 16, 30, 0, 0, time.UTC),
+		},
+		"time_before_period": {
+			rotation: Rotation{Period: durationPtr(24 * time.Hour), Times: []string{"18:15"}},
+			next:     time.Date(2024, time.March, 10, 18, 15, 0, 0, time.UTC),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			next := testCase.rotation.NextRotation(tunnelUpTime)
+
+			assert.Equal(t, testCase.next, next)
+		})
+	}
+}
@@ -3,10 +3,13 @@ package settings
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/qdm12/gluetun/internal/server/middlewares/auth"
 	"github.com/qdm12/gosettings"
@@ -17,6 +20,9 @@ import (
 // ControlServer contains settings to customize the control server operation.
 type ControlServer struct {
 	// Address is the listening address to use.
+	// It can be a Unix domain socket path prefixed with
+	// 'unix:', for example 'unix:/gluetun/control.sock',
+	// to not listen on a TCP port.
 	// It cannot be nil in the internal state.
 	Address *string
 	// Log can be true or false to enable logging on requests.
@@ -30,24 +36,45 @@ type ControlServer struct {
 	// AuthDefaultRole is a JSON encoded object defining the default role
 	// that applies to all routes without a previously user-defined role assigned to.
 	AuthDefaultRole string
+	// TLSCertPath is the path to the PEM encoded TLS certificate
+	// file to serve HTTPS. The certificate and key are reloaded
+	// when their files change.
+	// It defaults to the empty string to serve plaintext HTTP.
+	TLSCertPath string
+	// TLSKeyPath is the path to the PEM encoded TLS private key file.
+	// It must be set if TLSCertPath is set.
+	TLSKeyPath string
+	// TLSClientCAPath is the path to the PEM encoded certificate
+	// authorities file used to verify client certificates, for
+	// roles using the 'mtls' authentication method.
+	// It defaults to the empty string and requires TLS to be enabled.
+	TLSClientCAPath string
 }
 
-func (c ControlServer) validate() (err error) {
-	_, portStr, err := net.SplitHostPort(*c.Address)
-	if err != nil {
-		return fmt.Errorf("listening address is not valid: %w", err)
-	}
+var (
+	ErrControlServerUnixSocketNotAbsolute = errors.New("unix socket path is not absolute")
+	ErrControlServerTLSKeyPairIncomplete  = errors.New("TLS certificate and key file paths must be set together")
+	ErrControlServerTLSClientCAWithoutTLS = errors.New("TLS client CA file path requires TLS to be enabled")
+)
 
-	port, err := strconv.Atoi(portStr)
-	if err != nil {
-		return fmt.Errorf("listening port it not valid: %w", err)
+func (c ControlServer) validate() (err error) {
+	unixSocketPath, isUnixSocket := strings.CutPrefix(*c.Address, "unix:")
+	if isUnixSocket {
+		if !filepath.IsAbs(unixSocketPath) {
+			return fmt.Errorf("%w: %s", ErrControlServerUnixSocketNotAbsolute, unixSocketPath)
+		}
+	} else {
+		err = validateControlServerPort(*c.Address)
+		if err != nil {
+			return err
+		}
 	}
 
-	uid := os.Getuid()
-	const maxPrivilegedPort = 1023
-	if uid != 0 && port != 0 && port <= maxPrivilegedPort {
-		return fmt.Errorf("%w: %d when running with user ID %d",
-			ErrControlServerPrivilegedPort, port, uid)
+	switch {
+	case (c.TLSCertPath == "") != (c.TLSKeyPath == ""):
+		return fmt.Errorf("%w", ErrControlServerTLSKeyPairIncomplete)
+	case c.TLSClientCAPath != "" && c.TLSCertPath == "":
+		return fmt.Errorf("%w", ErrControlServerTLSClientCAWithoutTLS)
 	}
 
 	jsonDecoder := json.NewDecoder(bytes.NewBufferString(c.AuthDefaultRole))
@@ -68,12 +95,36 @@ func (c ControlServer) validate() (err error) {
 	return nil
 }
 
+func validateControlServerPort(address string) (err error) {
+	_, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("listening address is not valid: %w", err)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return fmt.Errorf("listening port it not valid: %w", err)
+	}
+
+	uid := os.Getuid()
+	const maxPrivilegedPort = 1023
+	if uid != 0 && port != 0 && port <= maxPrivilegedPort {
+		return fmt.Errorf("%w: %d when running with user ID %d",
+			ErrControlServerPrivilegedPort, port, uid)
+	}
+
+	return nil
+}
+
 func (c *ControlServer) copy() (copied ControlServer) {
 	return ControlServer{
 		Address:         gosettings.CopyPointer(c.Address),
 		Log:             gosettings.CopyPointer(c.Log),
 		AuthFilePath:    c.AuthFilePath,
 		AuthDefaultRole: c.AuthDefaultRole,
+		TLSCertPath:     c.TLSCertPath,
+		TLSKeyPath:      c.TLSKeyPath,
+		TLSClientCAPath: c.TLSClientCAPath,
 	}
 }
 
@@ -85,6 +136,9 @@ func (c *ControlServer) overrideWith(other ControlServer) {
 	c.Log = gosettings.OverrideWithPointer(c.Log, other.Log)
 	c.AuthFilePath = gosettings.OverrideWithComparable(c.AuthFilePath, other.AuthFilePath)
 	c.AuthDefaultRole = gosettings.OverrideWithComparable(c.AuthDefaultRole, other.AuthDefaultRole)
+	c.TLSCertPath = gosettings.OverrideWithComparable(c.TLSCertPath, other.TLSCertPath)
+	c.TLSKeyPath = gosettings.OverrideWithComparable(c.TLSKeyPath, other.TLSKeyPath)
+	c.TLSClientCAPath = gosettings.OverrideWithComparable(c.TLSClientCAPath, other.TLSClientCAPath)
 }
 
 func (c *ControlServer) setDefaults() {
@@ -110,6 +164,14 @@ func (c ControlServer) toLinesNode() (node *gotree.Node) {
 	node.Appendf("Listening address: %s", *c.Address)
 	node.Appendf("Logging: %s", gosettings.BoolToYesNo(c.Log))
 	node.Appendf("Authentication file path: %s", c.AuthFilePath)
+	if c.TLSCertPath != "" {
+		tlsNode := node.Appendf("TLS:")
+		tlsNode.Appendf("Certificate file path: %s", c.TLSCertPath)
+		tlsNode.Appendf("Key file path: %s", c.TLSKeyPath)
+		if c.TLSClientCAPath != "" {
+			tlsNode.Appendf("Client CA file path: %s", c.TLSClientCAPath)
+		}
+	}
 	if c.AuthDefaultRole != "{}" {
 		var role auth.Role
 		_ = json.Unmarshal([]byte(c.AuthDefaultRole), &role)
@@ -124,10 +186,14 @@ func (c *ControlServer) read(r *reader.Reader) (err error) {
 		return err
 	}
 
-	c.Address = r.Get("HTTP_CONTROL_SERVER_ADDRESS")
+	c.Address = r.Get("HTTP_CONTROL_SERVER_ADDRESS", reader.ForceLowercase(false))
 
 	c.AuthFilePath = r.String("HTTP_CONTROL_SERVER_AUTH_CONFIG_FILEPATH")
 	c.AuthDefaultRole = r.String("HTTP_CONTROL_SERVER_AUTH_DEFAULT_ROLE", reader.ForceLowercase(false))
 
+	c.TLSCertPath = r.String("HTTP_CONTROL_SERVER_TLS_CERT_FILEPATH", reader.ForceLowercase(false))
+	c.TLSKeyPath = r.String("HTTP_CONTROL_SERVER_TLS_KEY_FILEPATH", reader.ForceLowercase(false))
+	c.TLSClientCAPath = r.String("HTTP_CONTROL_SERVER_TLS_CLIENT_CA_FILEPATH", reader.ForceLowercase(false))
+
 	return nil
 }
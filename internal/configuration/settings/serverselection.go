@@ -69,8 +69,27 @@ type ServerSelection struct {
 	// Wireguard contains settings to select Wireguard servers
 	// and the final connection.
 	Wireguard WireguardSelection `json:"wireguard"`
+	// CustomConfigDir is the directory of OpenVPN and Wireguard
+	// configuration files to use as servers with the custom provider.
+	// It cannot be nil in the internal state, and the empty string
+	// indicates to use a single configuration instead.
+	CustomConfigDir *string `json:"custom_config_dir"`
+	// Strategy is the strategy to pick a connection from the
+	// filtered servers, and can be 'random' or 'latency'.
+	// It cannot be the empty string in the internal state.
+	Strategy string `json:"strategy"`
 }
 
+const (
+	// SelectionStrategyRandom picks a random connection
+	// from the filtered servers.
+	SelectionStrategyRandom = "random"
+	// SelectionStrategyLatency probes a sample of the filtered
+	// servers and picks the one with the lowest latency, skipping
+	// servers which previously failed.
+	SelectionStrategyLatency = "latency"
+)
+
 var (
 	ErrOwnedOnlyNotSupported       = errors.New("owned only filter is not supported")
 	ErrFreeOnlyNotSupported        = errors.New("free only filter is not supported")
@@ -81,8 +100,11 @@ var (
 	ErrFreePremiumBothSet          = errors.New("free only and premium only filters are both set")
 	ErrSecureCoreOnlyNotSupported  = errors.New("secure core only filter is not supported")
 	ErrTorOnlyNotSupported         = errors.New("tor only filter is not supported")
+	ErrStrategyNotSupported        = errors.New("selection strategy is not supported")
 )
 
+var ErrCustomConfigDirNotSupported = errors.New("custom configuration directory is only supported with the custom provider")
+
 func (ss *ServerSelection) validate(vpnServiceProvider string,
 	filterChoicesGetter FilterChoicesGetter, warner Warner,
 ) (err error) {
@@ -92,6 +114,16 @@ func (ss *ServerSelection) validate(vpnServiceProvider string,
 		return fmt.Errorf("%w: %s", ErrVPNTypeNotValid, ss.VPN)
 	}
 
+	if *ss.CustomConfigDir != "" {
+		if vpnServiceProvider != providers.Custom {
+			return fmt.Errorf("%w: not %s", ErrCustomConfigDirNotSupported, vpnServiceProvider)
+		}
+		err = validate.DirectoryExists(*ss.CustomConfigDir)
+		if err != nil {
+			return fmt.Errorf("custom configuration directory: %w", err)
+		}
+	}
+
 	filterChoices, err := getLocationFilterChoices(vpnServiceProvider, ss, filterChoicesGetter, warner)
 	if err != nil {
 		return err // already wrapped error
@@ -120,12 +152,21 @@ func (ss *ServerSelection) validate(vpnServiceProvider string,
 		return fmt.Errorf("for VPN service provider %s: %w", vpnServiceProvider, err)
 	}
 
+	err = validate.IsOneOf(ss.Strategy, SelectionStrategyRandom, SelectionStrategyLatency)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStrategyNotSupported, err)
+	} else if ss.Strategy == SelectionStrategyLatency && vpnServiceProvider == providers.Custom {
+		return fmt.Errorf("%w: %s for the custom provider", ErrStrategyNotSupported, ss.Strategy)
+	}
+
 	if ss.VPN == vpn.OpenVPN {
 		err = ss.OpenVPN.validate(vpnServiceProvider)
 		if err != nil {
 			return fmt.Errorf("OpenVPN server selection settings: %w", err)
 		}
-	} else {
+	} else if *ss.CustomConfigDir == "" {
+		// Endpoints and public keys are otherwise read from
+		// the Wireguard configuration files of the directory.
 		err = ss.Wireguard.validate(vpnServiceProvider)
 		if err != nil {
 			return fmt.Errorf("Wireguard server selection settings: %w", err)
@@ -162,6 +203,14 @@ func getLocationFilterChoices(vpnServiceProvider string,
 func validateServerFilters(settings ServerSelection, filterChoices models.FilterChoices,
 	vpnServiceProvider string, warner Warner,
 ) (err error) {
+	if vpnServiceProvider == providers.Custom && *settings.CustomConfigDir != "" {
+		// Configuration files are only read when connecting, so filters
+		// are matched against the files names and tags at that time.
+		filterChoices.Countries = settings.Countries
+		filterChoices.Cities = settings.Cities
+		filterChoices.Names = settings.Names
+	}
+
 	err = atLeastOneIsOneOfCaseInsensitive(settings.Countries, filterChoices.Countries, warner)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrCountryNotValid, err)
@@ -187,7 +236,7 @@ func validateServerFilters(settings ServerSelection, filterChoices models.Filter
 		return fmt.Errorf("%w: %w", ErrHostnameNotValid, err)
 	}
 
-	if vpnServiceProvider == providers.Custom {
+	if vpnServiceProvider == providers.Custom && *settings.CustomConfigDir == "" {
 		switch len(settings.Names) {
 		case 0:
 		case 1:
@@ -310,6 +359,8 @@ func (ss *ServerSelection) copy() (copied ServerSelection) {
 		MultiHopOnly:    gosettings.CopyPointer(ss.MultiHopOnly),
 		OpenVPN:         ss.OpenVPN.copy(),
 		Wireguard:       ss.Wireguard.copy(),
+		CustomConfigDir: gosettings.CopyPointer(ss.CustomConfigDir),
+		Strategy:        ss.Strategy,
 	}
 }
 
@@ -333,6 +384,8 @@ func (ss *ServerSelection) overrideWith(other ServerSelection) {
 	ss.PortForwardOnly = gosettings.OverrideWithPointer(ss.PortForwardOnly, other.PortForwardOnly)
 	ss.OpenVPN.overrideWith(other.OpenVPN)
 	ss.Wireguard.overrideWith(other.Wireguard)
+	ss.CustomConfigDir = gosettings.OverrideWithPointer(ss.CustomConfigDir, other.CustomConfigDir)
+	ss.Strategy = gosettings.OverrideWithComparable(ss.Strategy, other.Strategy)
 }
 
 func (ss *ServerSelection) setDefaults(vpnProvider string, portForwardingEnabled bool) {
@@ -349,6 +402,8 @@ func (ss *ServerSelection) setDefaults(vpnProvider string, portForwardingEnabled
 	ss.PortForwardOnly = gosettings.DefaultPointer(ss.PortForwardOnly, defaultPortForwardOnly)
 	ss.OpenVPN.setDefaults(vpnProvider)
 	ss.Wireguard.setDefaults()
+	ss.CustomConfigDir = gosettings.DefaultPointer(ss.CustomConfigDir, "")
+	ss.Strategy = gosettings.DefaultComparable(ss.Strategy, SelectionStrategyRandom)
 }
 
 func (ss ServerSelection) String() string {
@@ -426,6 +481,14 @@ func (ss ServerSelection) toLinesNode() (node *gotree.Node) {
 		node.Appendf("Port forwarding only servers: yes")
 	}
 
+	if *ss.CustomConfigDir != "" {
+		node.Appendf("Custom configuration directory: %s", *ss.CustomConfigDir)
+	}
+
+	if ss.Strategy != SelectionStrategyRandom {
+		node.Appendf("Selection strategy: %s", ss.Strategy)
+	}
+
 	if ss.VPN == vpn.OpenVPN {
 		node.AppendNode(ss.OpenVPN.toLinesNode())
 	} else {
@@ -513,6 +576,11 @@ func (ss *ServerSelection) read(r *reader.Reader,
 		return err
 	}
 
+	ss.Strategy = r.String("SERVER_SELECTION_STRATEGY")
+
+	// Custom provider only
+	ss.CustomConfigDir = r.Get("CUSTOM_CONFIG_DIR", reader.ForceLowercase(false))
+
 	err = ss.OpenVPN.read(r)
 	if err != nil {
 		return err
@@ -82,12 +82,15 @@ func Test_Settings_String(t *testing.T) {
 |   └── Enabled: no
 ├── HTTP proxy settings:
 |   └── Enabled: no
+├── SOCKS5 proxy settings:
+|   └── Enabled: no
 ├── Control server settings:
 |   ├── Listening address: :8000
 |   ├── Logging: yes
 |   └── Authentication file path: /gluetun/auth/config.toml
 ├── Storage settings:
-|   └── Filepath: /gluetun/servers.json
+|   ├── Filepath: /gluetun/servers.json
+|   └── Server blacklist: 2 failures within 1h0m0s
 ├── OS Alpine settings:
 |   ├── Process UID: 1000
 |   └── Process GID: 1000
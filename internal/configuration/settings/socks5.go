@@ -0,0 +1,139 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/qdm12/gosettings"
+	"github.com/qdm12/gosettings/reader"
+	"github.com/qdm12/gosettings/validate"
+	"github.com/qdm12/gotree"
+)
+
+// Socks5 contains settings to configure the SOCKS5 proxy.
+type Socks5 struct {
+	// User is the username to use for the SOCKS5 proxy.
+	// If it is the empty string, no authentication is required.
+	// It cannot be nil in the internal state.
+	User *string
+	// Password is the password to use for the SOCKS5 proxy.
+	// It cannot be nil in the internal state.
+	Password *string
+	// ListeningAddress is the listening address
+	// of the SOCKS5 proxy server.
+	// It cannot be the empty string in the internal state.
+	ListeningAddress string
+	// Enabled is true if the SOCKS5 proxy server should run,
+	// and false otherwise. It cannot be nil in the
+	// internal state.
+	Enabled *bool
+	// UDP is true if the SOCKS5 proxy server should accept
+	// UDP ASSOCIATE commands to relay UDP datagrams.
+	// It cannot be nil in the internal state.
+	UDP *bool
+	// Log is true if the SOCKS5 proxy server should log
+	// each connection. It cannot be nil in the
+	// internal state.
+	Log *bool
+}
+
+func (s Socks5) validate() (err error) {
+	err = validate.ListeningAddress(s.ListeningAddress, os.Getuid())
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrServerAddressNotValid, s.ListeningAddress)
+	}
+
+	// See RFC 1929 section 2
+	const maxCredentialLength = 255
+	switch {
+	case *s.User == "" && *s.Password != "":
+		return ErrSocks5UserMissing
+	case len(*s.User) > maxCredentialLength:
+		return fmt.Errorf("%w: %d bytes exceeds the maximum of %d bytes",
+			ErrSocks5UserTooLong, len(*s.User), maxCredentialLength)
+	case len(*s.Password) > maxCredentialLength:
+		return fmt.Errorf("%w: %d bytes exceeds the maximum of %d bytes",
+			ErrSocks5PasswordTooLong, len(*s.Password), maxCredentialLength)
+	}
+
+	return nil
+}
+
+func (s *Socks5) copy() (copied Socks5) {
+	return Socks5{
+		User:             gosettings.CopyPointer(s.User),
+		Password:         gosettings.CopyPointer(s.Password),
+		ListeningAddress: s.ListeningAddress,
+		Enabled:          gosettings.CopyPointer(s.Enabled),
+		UDP:              gosettings.CopyPointer(s.UDP),
+		Log:              gosettings.CopyPointer(s.Log),
+	}
+}
+
+// overrideWith overrides fields of the receiver
+// settings object with any field set in the other
+// settings.
+func (s *Socks5) overrideWith(other Socks5) {
+	s.User = gosettings.OverrideWithPointer(s.User, other.User)
+	s.Password = gosettings.OverrideWithPointer(s.Password, other.Password)
+	s.ListeningAddress = gosettings.OverrideWithComparable(s.ListeningAddress, other.ListeningAddress)
+	s.Enabled = gosettings.OverrideWithPointer(s.Enabled, other.Enabled)
+	s.UDP = gosettings.OverrideWithPointer(s.UDP, other.UDP)
+	s.Log = gosettings.OverrideWithPointer(s.Log, other.Log)
+}
+
+func (s *Socks5) setDefaults() {
+	s.User = gosettings.DefaultPointer(s.User, "")
+	s.Password = gosettings.DefaultPointer(s.Password, "")
+	s.ListeningAddress = gosettings.DefaultComparable(s.ListeningAddress, ":1080")
+	s.Enabled = gosettings.DefaultPointer(s.Enabled, false)
+	s.UDP = gosettings.DefaultPointer(s.UDP, false)
+	s.Log = gosettings.DefaultPointer(s.Log, false)
+}
+
+func (s Socks5) String() string {
+	return s.toLinesNode().String()
+}
+
+func (s Socks5) toLinesNode() (node *gotree.Node) {
+	node = gotree.New("SOCKS5 proxy settings:")
+	node.Appendf("Enabled: %s", gosettings.BoolToYesNo(s.Enabled))
+	if !*s.Enabled {
+		return node
+	}
+
+	node.Appendf("Listening address: %s", s.ListeningAddress)
+	if *s.User != "" {
+		node.Appendf("User: %s", *s.User)
+		node.Appendf("Password: %s", gosettings.ObfuscateKey(*s.Password))
+	} else {
+		node.Appendf("Authentication: none")
+	}
+	node.Appendf("UDP associate: %s", gosettings.BoolToYesNo(s.UDP))
+	node.Appendf("Log: %s", gosettings.BoolToYesNo(s.Log))
+
+	return node
+}
+
+func (s *Socks5) read(r *reader.Reader) (err error) {
+	s.User = r.Get("SOCKS5PROXY_USER", reader.ForceLowercase(false))
+	s.Password = r.Get("SOCKS5PROXY_PASSWORD", reader.ForceLowercase(false))
+	s.ListeningAddress = r.String("SOCKS5PROXY_LISTENING_ADDRESS")
+
+	s.Enabled, err = r.BoolPtr("SOCKS5PROXY")
+	if err != nil {
+		return err
+	}
+
+	s.UDP, err = r.BoolPtr("SOCKS5PROXY_UDP")
+	if err != nil {
+		return err
+	}
+
+	s.Log, err = r.BoolPtr("SOCKS5PROXY_LOG")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
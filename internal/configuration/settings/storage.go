@@ -1,8 +1,10 @@
 package settings
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/qdm12/gosettings"
 	"github.com/qdm12/gosettings/reader"
@@ -13,8 +15,20 @@ import (
 type Storage struct {
 	// Filepath is the path to the servers.json file. An empty string disables on-disk storage.
 	Filepath *string
+	// BlacklistDuration is the duration for which a server IP address
+	// with recent failures is excluded from the server selection.
+	// The failures are stored in blacklist.json next to the servers.json
+	// file. It can be set to 0 to disable the blacklist, and cannot be
+	// nil in the internal state.
+	BlacklistDuration *time.Duration
+	// BlacklistFailures is the number of failures within the blacklist
+	// duration for a server IP address to be blacklisted.
+	// It cannot be nil or 0 in the internal state.
+	BlacklistFailures *uint
 }
 
+var ErrStorageBlacklistFailuresZero = errors.New("blacklist failures cannot be 0")
+
 func (s Storage) validate() (err error) {
 	if *s.Filepath != "" { // optional
 		_, err := filepath.Abs(*s.Filepath)
@@ -22,22 +36,34 @@ func (s Storage) validate() (err error) {
 			return fmt.Errorf("filepath is not valid: %w", err)
 		}
 	}
+
+	if *s.BlacklistFailures == 0 {
+		return fmt.Errorf("%w", ErrStorageBlacklistFailuresZero)
+	}
 	return nil
 }
 
 func (s *Storage) copy() (copied Storage) {
 	return Storage{
-		Filepath: gosettings.CopyPointer(s.Filepath),
+		Filepath:          gosettings.CopyPointer(s.Filepath),
+		BlacklistDuration: gosettings.CopyPointer(s.BlacklistDuration),
+		BlacklistFailures: gosettings.CopyPointer(s.BlacklistFailures),
 	}
 }
 
 func (s *Storage) overrideWith(other Storage) {
 	s.Filepath = gosettings.OverrideWithPointer(s.Filepath, other.Filepath)
+	s.BlacklistDuration = gosettings.OverrideWithPointer(s.BlacklistDuration, other.BlacklistDuration)
+	s.BlacklistFailures = gosettings.OverrideWithPointer(s.BlacklistFailures, other.BlacklistFailures)
 }
 
 func (s *Storage) setDefaults() {
 	const defaultFilepath = "/gluetun/servers.json"
 	s.Filepath = gosettings.DefaultPointer(s.Filepath, defaultFilepath)
+	const defaultBlacklistDuration = time.Hour
+	s.BlacklistDuration = gosettings.DefaultPointer(s.BlacklistDuration, defaultBlacklistDuration)
+	const defaultBlacklistFailures = 2
+	s.BlacklistFailures = gosettings.DefaultPointer(s.BlacklistFailures, defaultBlacklistFailures)
 }
 
 func (s Storage) String() string {
@@ -50,10 +76,26 @@ func (s Storage) toLinesNode() (node *gotree.Node) {
 	}
 	node = gotree.New("Storage settings:")
 	node.Appendf("Filepath: %s", *s.Filepath)
+	if *s.BlacklistDuration == 0 {
+		node.Appendf("Server blacklist: disabled")
+	} else {
+		node.Appendf("Server blacklist: %d failures within %s", *s.BlacklistFailures, *s.BlacklistDuration)
+	}
 	return node
 }
 
 func (s *Storage) read(r *reader.Reader) (err error) {
 	s.Filepath = r.Get("STORAGE_FILEPATH", reader.AcceptEmpty(true))
+
+	s.BlacklistDuration, err = r.DurationPtr("STORAGE_BLACKLIST_DURATION")
+	if err != nil {
+		return err
+	}
+
+	s.BlacklistFailures, err = r.UintPtr("STORAGE_BLACKLIST_FAILURES")
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
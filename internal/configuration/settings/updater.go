@@ -1,7 +1,9 @@
 package settings
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"time"
@@ -21,6 +23,11 @@ type Updater struct {
 	// updater. It cannot be nil in the internal state.
 	// TODO change to value and add Enabled field.
 	Period *time.Duration
+	// ProviderPeriods maps providers to their specific update
+	// period, overriding Period for these providers. A period
+	// of 0 disables periodic updates for the provider.
+	// It defaults to an empty map.
+	ProviderPeriods map[string]time.Duration
 	// DNSAddress is the DNS server address to use
 	// to resolve VPN server hostnames to IP addresses.
 	// It cannot be the empty string in the internal state.
@@ -45,6 +52,16 @@ func (u Updater) Validate() (err error) {
 			ErrUpdaterPeriodTooSmall, *u.Period, minPeriod)
 	}
 
+	for provider, period := range u.ProviderPeriods {
+		if !slices.Contains(u.Providers, provider) {
+			return fmt.Errorf("%w: %s", ErrUpdaterProviderPeriodNotUpdated, provider)
+		}
+		if period > 0 && period < minPeriod {
+			return fmt.Errorf("%w: %s for %s must be larger than %s",
+				ErrUpdaterPeriodTooSmall, period, provider, minPeriod)
+		}
+	}
+
 	if u.MinRatio <= 0 || u.MinRatio > 1 {
 		return fmt.Errorf("%w: %.2f must be between 0+ and 1",
 			ErrMinRatioNotValid, u.MinRatio)
@@ -75,12 +92,13 @@ func (u Updater) Validate() (err error) {
 
 func (u *Updater) copy() (copied Updater) {
 	return Updater{
-		Period:         gosettings.CopyPointer(u.Period),
-		DNSAddress:     u.DNSAddress,
-		MinRatio:       u.MinRatio,
-		Providers:      gosettings.CopySlice(u.Providers),
-		ProtonEmail:    gosettings.CopyPointer(u.ProtonEmail),
-		ProtonPassword: gosettings.CopyPointer(u.ProtonPassword),
+		Period:          gosettings.CopyPointer(u.Period),
+		ProviderPeriods: maps.Clone(u.ProviderPeriods),
+		DNSAddress:      u.DNSAddress,
+		MinRatio:        u.MinRatio,
+		Providers:       gosettings.CopySlice(u.Providers),
+		ProtonEmail:     gosettings.CopyPointer(u.ProtonEmail),
+		ProtonPassword:  gosettings.CopyPointer(u.ProtonPassword),
 	}
 }
 
@@ -89,6 +107,9 @@ func (u *Updater) copy() (copied Updater) {
 // settings.
 func (u *Updater) overrideWith(other Updater) {
 	u.Period = gosettings.OverrideWithPointer(u.Period, other.Period)
+	if other.ProviderPeriods != nil {
+		u.ProviderPeriods = maps.Clone(other.ProviderPeriods)
+	}
 	u.DNSAddress = gosettings.OverrideWithComparable(u.DNSAddress, other.DNSAddress)
 	u.MinRatio = gosettings.OverrideWithComparable(u.MinRatio, other.MinRatio)
 	u.Providers = gosettings.OverrideWithSlice(u.Providers, other.Providers)
@@ -98,6 +119,9 @@ func (u *Updater) overrideWith(other Updater) {
 
 func (u *Updater) SetDefaults(vpnProvider string) {
 	u.Period = gosettings.DefaultPointer(u.Period, 0)
+	if u.ProviderPeriods == nil {
+		u.ProviderPeriods = map[string]time.Duration{}
+	}
 	u.DNSAddress = gosettings.DefaultComparable(u.DNSAddress, "1.1.1.1:53")
 
 	if u.MinRatio == 0 {
@@ -114,17 +138,40 @@ func (u *Updater) SetDefaults(vpnProvider string) {
 	u.ProtonPassword = gosettings.DefaultPointer(u.ProtonPassword, "")
 }
 
+// ProviderPeriod returns the update period for the provider given,
+// which is its specific period if set, and the global period otherwise.
+func (u Updater) ProviderPeriod(provider string) time.Duration {
+	period, ok := u.ProviderPeriods[provider]
+	if ok {
+		return period
+	}
+	return *u.Period
+}
+
 func (u Updater) String() string {
 	return u.toLinesNode().String()
 }
 
 func (u Updater) toLinesNode() (node *gotree.Node) {
-	if *u.Period == 0 || len(u.Providers) == 0 {
+	enabled := false
+	for _, provider := range u.Providers {
+		if u.ProviderPeriod(provider) > 0 {
+			enabled = true
+			break
+		}
+	}
+	if !enabled {
 		return nil
 	}
 
 	node = gotree.New("Server data updater settings:")
 	node.Appendf("Update period: %s", *u.Period)
+	if len(u.ProviderPeriods) > 0 {
+		periodsNode := node.Append("Provider update periods:")
+		for _, provider := range slices.Sorted(maps.Keys(u.ProviderPeriods)) {
+			periodsNode.Appendf("%s: %s", provider, u.ProviderPeriods[provider])
+		}
+	}
 	node.Appendf("DNS address: %s", u.DNSAddress)
 	node.Appendf("Minimum ratio: %.1f", u.MinRatio)
 	node.Appendf("Providers to update: %s", strings.Join(u.Providers, ", "))
@@ -142,6 +189,11 @@ func (u *Updater) read(r *reader.Reader) (err error) {
 		return err
 	}
 
+	u.ProviderPeriods, err = readUpdaterProviderPeriods(r)
+	if err != nil {
+		return err
+	}
+
 	u.DNSAddress, err = readUpdaterDNSAddress()
 	if err != nil {
 		return err
@@ -167,6 +219,40 @@ func (u *Updater) read(r *reader.Reader) (err error) {
 	return nil
 }
 
+var (
+	ErrUpdaterProviderPeriodNotUpdated = errors.New("provider with an update period is not in the providers to update")
+	ErrUpdaterProviderPeriodMalformed  = errors.New("provider update period is malformed")
+)
+
+// readUpdaterProviderPeriods reads provider update periods
+// in the comma separated format `provider=period`, for
+// example `mullvad=12h,protonvpn=24h`.
+func readUpdaterProviderPeriods(r *reader.Reader) (
+	providerPeriods map[string]time.Duration, err error,
+) {
+	const key = "UPDATER_PROVIDER_PERIODS"
+	values := r.CSV(key)
+	if values == nil {
+		return nil, nil
+	}
+
+	providerPeriods = make(map[string]time.Duration, len(values))
+	for _, value := range values {
+		provider, periodString, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s: %w: %s",
+				key, ErrUpdaterProviderPeriodMalformed, value)
+		}
+		period, err := time.ParseDuration(periodString)
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w: %w",
+				key, ErrUpdaterProviderPeriodMalformed, err)
+		}
+		providerPeriods[strings.TrimSpace(provider)] = period
+	}
+	return providerPeriods, nil
+}
+
 func readUpdaterDNSAddress() (address string, err error) {
 	// TODO this is currently using Cloudflare in
 	// plaintext to not be blocked by DNS over TLS by default.
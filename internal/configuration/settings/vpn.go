@@ -3,6 +3,7 @@ package settings
 import (
 	"fmt"
 
+	"github.com/qdm12/gluetun/internal/constants/providers"
 	"github.com/qdm12/gluetun/internal/constants/vpn"
 	"github.com/qdm12/gosettings"
 	"github.com/qdm12/gosettings/reader"
@@ -19,6 +20,7 @@ type VPN struct {
 	OpenVPN   OpenVPN   `json:"openvpn"`
 	Wireguard Wireguard `json:"wireguard"`
 	PMTUD     PMTUD     `json:"pmtud"`
+	Rotation  Rotation  `json:"rotation"`
 }
 
 // TODO v4 remove pointer for receiver (because of Surfshark).
@@ -34,13 +36,18 @@ func (v *VPN) Validate(filterChoicesGetter FilterChoicesGetter, ipv6Supported bo
 		return fmt.Errorf("provider settings: %w", err)
 	}
 
+	// With a custom configuration directory, the configuration file, keys
+	// and addresses can be set in each file of the directory instead.
+	customConfigDir := v.Provider.Name == providers.Custom &&
+		*v.Provider.ServerSelection.CustomConfigDir != ""
+
 	if v.Type == vpn.OpenVPN {
-		err := v.OpenVPN.validate(v.Provider.Name)
+		err := v.OpenVPN.validate(v.Provider.Name, customConfigDir)
 		if err != nil {
 			return fmt.Errorf("OpenVPN settings: %w", err)
 		}
 	} else {
-		err := v.Wireguard.validate(v.Provider.Name, ipv6Supported)
+		err := v.Wireguard.validate(v.Provider.Name, ipv6Supported, customConfigDir)
 		if err != nil {
 			return fmt.Errorf("Wireguard settings: %w", err)
 		}
@@ -51,6 +58,11 @@ func (v *VPN) Validate(filterChoicesGetter FilterChoicesGetter, ipv6Supported bo
 		return fmt.Errorf("PMTUD settings: %w", err)
 	}
 
+	err = v.Rotation.validate()
+	if err != nil {
+		return fmt.Errorf("rotation settings: %w", err)
+	}
+
 	return nil
 }
 
@@ -61,6 +73,7 @@ func (v *VPN) Copy() (copied VPN) {
 		OpenVPN:   v.OpenVPN.copy(),
 		Wireguard: v.Wireguard.copy(),
 		PMTUD:     v.PMTUD.copy(),
+		Rotation:  v.Rotation.copy(),
 	}
 }
 
@@ -70,6 +83,7 @@ func (v *VPN) OverrideWith(other VPN) {
 	v.OpenVPN.overrideWith(other.OpenVPN)
 	v.Wireguard.overrideWith(other.Wireguard)
 	v.PMTUD.overrideWith(other.PMTUD)
+	v.Rotation.overrideWith(other.Rotation)
 }
 
 func (v *VPN) setDefaults() {
@@ -78,6 +92,7 @@ func (v *VPN) setDefaults() {
 	v.OpenVPN.setDefaults(v.Provider.Name)
 	v.Wireguard.setDefaults(v.Provider.Name)
 	v.PMTUD.setDefaults()
+	v.Rotation.setDefaults()
 }
 
 func (v VPN) String() string {
@@ -95,6 +110,9 @@ func (v VPN) toLinesNode() (node *gotree.Node) {
 		node.AppendNode(v.Wireguard.toLinesNode())
 	}
 	node.AppendNode(v.PMTUD.toLinesNode())
+	if v.Rotation.Enabled() {
+		node.AppendNode(v.Rotation.toLinesNode())
+	}
 
 	return node
 }
@@ -122,5 +140,10 @@ func (v *VPN) read(r *reader.Reader) (err error) {
 		return fmt.Errorf("PMTUD: %w", err)
 	}
 
+	err = v.Rotation.read(r)
+	if err != nil {
+		return fmt.Errorf("rotation: %w", err)
+	}
+
 	return nil
 }
@@ -52,7 +52,7 @@ var regexpInterfaceName = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 
 // Validate validates Wireguard settings.
 // It should only be ran if the VPN type chosen is Wireguard.
-func (w Wireguard) validate(vpnProvider string, ipv6Supported bool) (err error) {
+func (w Wireguard) validate(vpnProvider string, ipv6Supported, customConfigDir bool) (err error) {
 	if !helpers.IsOneOf(vpnProvider,
 		providers.Airvpn,
 		providers.Custom,
@@ -68,18 +68,21 @@ func (w Wireguard) validate(vpnProvider string, ipv6Supported bool) (err error)
 		return nil
 	}
 
-	// Validate PrivateKey
-	if *w.PrivateKey == "" {
-		return fmt.Errorf("%w", ErrWireguardPrivateKeyNotSet)
-	}
-	_, err = wgtypes.ParseKey(*w.PrivateKey)
-	if err != nil {
-		err = fmt.Errorf("private key is not valid: %w", err)
-		if vpnProvider == providers.Nordvpn &&
-			err.Error() == "wgtypes: incorrect key size: 48" {
-			err = fmt.Errorf("%w - you might be using your access token instead of the Wireguard private key", err)
+	// Validate PrivateKey, which can be left empty with a custom
+	// configuration directory to use the key of each file instead.
+	if *w.PrivateKey != "" || !customConfigDir {
+		if *w.PrivateKey == "" {
+			return fmt.Errorf("%w", ErrWireguardPrivateKeyNotSet)
+		}
+		_, err = wgtypes.ParseKey(*w.PrivateKey)
+		if err != nil {
+			err = fmt.Errorf("private key is not valid: %w", err)
+			if vpnProvider == providers.Nordvpn &&
+				err.Error() == "wgtypes: incorrect key size: 48" {
+				err = fmt.Errorf("%w - you might be using your access token instead of the Wireguard private key", err)
+			}
+			return err
 		}
-		return err
 	}
 
 	if vpnProvider == providers.Airvpn {
@@ -97,7 +100,7 @@ func (w Wireguard) validate(vpnProvider string, ipv6Supported bool) (err error)
 	}
 
 	// Validate Addresses
-	if len(w.Addresses) == 0 {
+	if len(w.Addresses) == 0 && !customConfigDir {
 		return fmt.Errorf("%w", ErrWireguardInterfaceAddressNotSet)
 	}
 	for i, ipNet := range w.Addresses {
@@ -0,0 +1,27 @@
+package dns
+
+import "sync"
+
+type blockListSizes struct {
+	hostnames  int
+	ips        int
+	ipPrefixes int
+	mutex      sync.RWMutex
+}
+
+func (b *blockListSizes) set(hostnames, ips, ipPrefixes int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.hostnames = hostnames
+	b.ips = ips
+	b.ipPrefixes = ipPrefixes
+}
+
+// GetBlockListSizes returns the number of blocked hostnames, IP addresses
+// and IP prefixes from the last successful block lists update.
+func (l *Loop) GetBlockListSizes() (hostnames, ips, ipPrefixes int) {
+	l.blockListSizes.mutex.RLock()
+	defer l.blockListSizes.mutex.RUnlock()
+	return l.blockListSizes.hostnames, l.blockListSizes.ips,
+		l.blockListSizes.ipPrefixes
+}
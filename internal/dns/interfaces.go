@@ -0,0 +1,12 @@
+package dns
+
+import (
+	"context"
+	"net/netip"
+	"time"
+)
+
+type SplitTunnel interface {
+	Matches(name string) bool
+	Add(ctx context.Context, addrs []netip.Addr, ttl time.Duration) (err error)
+}
@@ -21,7 +21,10 @@ type Loop struct {
 	state          *state.State
 	server         *server.Server
 	filter         *mapfilter.Filter
+	blockListSizes blockListSizes
 	localResolvers []netip.Addr
+	splitTunnel    SplitTunnel
+	stats          *queryStats
 	resolvConf     string
 	client         *http.Client
 	logger         Logger
@@ -39,7 +42,7 @@ type Loop struct {
 const defaultBackoffTime = 10 * time.Second
 
 func NewLoop(settings settings.DNS,
-	client *http.Client, logger Logger,
+	client *http.Client, splitTunnel SplitTunnel, logger Logger,
 ) (loop *Loop, err error) {
 	start := make(chan struct{})
 	running := make(chan models.LoopStatus)
@@ -47,7 +50,7 @@ func NewLoop(settings settings.DNS,
 	stopped := make(chan struct{})
 	updateTicker := make(chan struct{})
 
-	statusManager := loopstate.New(constants.Stopped, start, running, stop, stopped)
+	statusManager := loopstate.New(constants.Stopped, start, running, stop, stopped, nil)
 	state := state.New(statusManager, settings, updateTicker)
 
 	filter, err := mapfilter.New(mapfilter.Settings{
@@ -62,6 +65,8 @@ func NewLoop(settings settings.DNS,
 		state:         state,
 		server:        nil,
 		filter:        filter,
+		splitTunnel:   splitTunnel,
+		stats:         newQueryStats(time.Now()),
 		resolvConf:    "/etc/resolv.conf",
 		client:        client,
 		logger:        logger,
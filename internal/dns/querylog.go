@@ -0,0 +1,163 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+type queryOutcome string
+
+const (
+	queryOutcomeAllowed queryOutcome = "allowed"
+	// queryOutcomeBlocked is for queries refused,
+	// which is how the filter middleware blocks queries.
+	queryOutcomeBlocked queryOutcome = "blocked"
+	queryOutcomeFailed  queryOutcome = "failed"
+)
+
+// queryLogMiddleware records statistics on each query answered,
+// and optionally logs each query to a rotating file.
+type queryLogMiddleware struct {
+	stats   *queryStats
+	logFile *rotatingFile
+	logger  Logger
+	timeNow func() time.Time
+}
+
+func newQueryLogMiddleware(settings settings.DNSQueryLog,
+	stats *queryStats, logger Logger, timeNow func() time.Time,
+) (middleware *queryLogMiddleware, err error) {
+	middleware = &queryLogMiddleware{
+		stats:   stats,
+		logger:  logger,
+		timeNow: timeNow,
+	}
+
+	if *settings.Path != "" {
+		middleware.logFile, err = openRotatingFile(*settings.Path,
+			*settings.MaxSize, *settings.MaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("opening query log file: %w", err)
+		}
+	}
+
+	return middleware, nil
+}
+
+func (m *queryLogMiddleware) String() string {
+	return "query log"
+}
+
+func (m *queryLogMiddleware) Wrap(next dns.Handler) dns.Handler { //nolint:ireturn
+	return &queryLogHandler{
+		next:       next,
+		middleware: m,
+	}
+}
+
+func (m *queryLogMiddleware) Stop() (err error) {
+	if m.logFile == nil {
+		return nil
+	}
+	return m.logFile.Close()
+}
+
+type queryLogEntry struct {
+	Time    time.Time    `json:"time"`
+	Client  string       `json:"client"`
+	Name    string       `json:"name"`
+	Type    string       `json:"type"`
+	Outcome queryOutcome `json:"outcome"`
+	Rcode   string       `json:"rcode"`
+	// LatencyMS is the time taken to answer the query in milliseconds,
+	// which is the upstream latency unless the answer is cached or local.
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+type queryLogHandler struct {
+	next       dns.Handler
+	middleware *queryLogMiddleware
+}
+
+func (h *queryLogHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	if len(r.Question) == 0 {
+		h.next.ServeDNS(w, r)
+		return
+	}
+
+	start := h.middleware.timeNow()
+	recorder := &responseRecorder{ResponseWriter: w}
+	h.next.ServeDNS(recorder, r)
+	latency := h.middleware.timeNow().Sub(start)
+
+	question := r.Question[0]
+	name := strings.ToLower(strings.TrimSuffix(question.Name, "."))
+	rcode := dns.RcodeServerFailure
+	if recorder.response != nil {
+		rcode = recorder.response.Rcode
+	}
+	var outcome queryOutcome
+	switch rcode {
+	case dns.RcodeSuccess, dns.RcodeNameError:
+		outcome = queryOutcomeAllowed
+	case dns.RcodeRefused:
+		outcome = queryOutcomeBlocked
+	default:
+		outcome = queryOutcomeFailed
+	}
+
+	h.middleware.stats.record(name, outcome)
+
+	if h.middleware.logFile == nil {
+		return
+	}
+
+	entry := queryLogEntry{
+		Time:      start.UTC(),
+		Client:    clientIP(w),
+		Name:      name,
+		Type:      dns.TypeToString[question.Qtype],
+		Outcome:   outcome,
+		Rcode:     dns.RcodeToString[rcode],
+		LatencyMS: float64(latency.Microseconds()) / float64(time.Millisecond/time.Microsecond),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		h.middleware.logger.Warn("encoding query log entry: " + err.Error())
+		return
+	}
+	data = append(data, '\n')
+	_, err = h.middleware.logFile.Write(data)
+	if err != nil {
+		h.middleware.logger.Warn("writing query log entry: " + err.Error())
+	}
+}
+
+func clientIP(w dns.ResponseWriter) string {
+	remoteAddress := w.RemoteAddr()
+	if remoteAddress == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(remoteAddress.String())
+	if err != nil {
+		return remoteAddress.String()
+	}
+	return host
+}
+
+// responseRecorder records the response written.
+type responseRecorder struct {
+	dns.ResponseWriter
+	response *dns.Msg
+}
+
+func (r *responseRecorder) WriteMsg(response *dns.Msg) error {
+	r.response = response
+	return r.ResponseWriter.WriteMsg(response)
+}
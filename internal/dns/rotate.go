@@ -0,0 +1,111 @@
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// rotatingFile is a file writer rotating the file when its size
+// would exceed a maximum size, keeping a maximum number of backups
+// named with the suffixes .1 (most recent) to .N (oldest).
+type rotatingFile struct {
+	path       string
+	maxSize    uint64
+	maxBackups uint
+	file       *os.File
+	size       uint64
+	mutex      sync.Mutex
+}
+
+func openRotatingFile(path string, maxSize uint64, maxBackups uint) (
+	rotating *rotatingFile, err error,
+) {
+	const dirPerms = 0o755
+	err = os.MkdirAll(filepath.Dir(path), dirPerms)
+	if err != nil {
+		return nil, fmt.Errorf("creating directory: %w", err)
+	}
+
+	const perms = 0o600
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, perms)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("getting file information: %w", err)
+	}
+
+	return &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		file:       file,
+		size:       uint64(stat.Size()), //nolint:gosec
+	}, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (n int, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.size > 0 && r.size+uint64(len(p)) > r.maxSize {
+		err = r.rotate()
+		if err != nil {
+			return 0, fmt.Errorf("rotating file: %w", err)
+		}
+	}
+
+	n, err = r.file.Write(p)
+	r.size += uint64(n) //nolint:gosec
+	return n, err
+}
+
+// rotate must be called with the mutex locked.
+func (r *rotatingFile) rotate() (err error) {
+	err = r.file.Close()
+	if err != nil {
+		return fmt.Errorf("closing file: %w", err)
+	}
+
+	if r.maxBackups == 0 {
+		err = os.Remove(r.path)
+		if err != nil {
+			return fmt.Errorf("removing file: %w", err)
+		}
+	} else {
+		for i := r.maxBackups - 1; i > 0; i-- {
+			err = os.Rename(r.backupPath(i), r.backupPath(i+1))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("renaming backup file: %w", err)
+			}
+		}
+		err = os.Rename(r.path, r.backupPath(1))
+		if err != nil {
+			return fmt.Errorf("renaming file: %w", err)
+		}
+	}
+
+	const perms = 0o600
+	r.file, err = os.OpenFile(r.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perms)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	r.size = 0
+	return nil
+}
+
+func (r *rotatingFile) backupPath(index uint) string {
+	return fmt.Sprintf("%s.%d", r.path, index)
+}
+
+func (r *rotatingFile) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.file.Close()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"time"
 
 	"github.com/qdm12/dns/v2/pkg/doh"
 	"github.com/qdm12/dns/v2/pkg/dot"
@@ -28,7 +29,7 @@ func (l *Loop) SetSettings(ctx context.Context, settings settings.DNS) (
 
 func buildServerSettings(settings settings.DNS,
 	filter *mapfilter.Filter, localResolvers []netip.Addr,
-	logger Logger) (
+	splitTunnel SplitTunnel, stats *queryStats, logger Logger) (
 	serverSettings server.Settings, err error,
 ) {
 	serverSettings.Logger = logger
@@ -121,5 +122,19 @@ func buildServerSettings(settings settings.DNS,
 	// Place after filter middleware to avoid conflicts with the rebinding protection.
 	serverSettings.Middlewares = append(serverSettings.Middlewares, localDNSMiddleware)
 
+	// Place last so it wraps all other middlewares, to see the response
+	// written to the client, including cached responses.
+	splitTunnelMiddleware := newSplitTunnelMiddleware(splitTunnel, logger)
+	serverSettings.Middlewares = append(serverSettings.Middlewares, splitTunnelMiddleware)
+
+	// Place last so it sees queries blocked by the filter middleware
+	// and measures the time taken by all other middlewares.
+	// It is created last since it opens the query log file.
+	queryLogMiddleware, err := newQueryLogMiddleware(settings.QueryLog, stats, logger, time.Now)
+	if err != nil {
+		return server.Settings{}, fmt.Errorf("creating query log middleware: %w", err)
+	}
+	serverSettings.Middlewares = append(serverSettings.Middlewares, queryLogMiddleware)
+
 	return serverSettings, nil
 }
@@ -0,0 +1,110 @@
+package dns
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// splitTunnelMiddleware adds the IP addresses answered for questions
+// matching split tunnel domains to the split tunnel, before writing
+// the response to the client. This way, the client connects to these
+// addresses outside the VPN tunnel.
+type splitTunnelMiddleware struct {
+	splitTunnel SplitTunnel
+	logger      Logger
+}
+
+func newSplitTunnelMiddleware(splitTunnel SplitTunnel, logger Logger) *splitTunnelMiddleware {
+	return &splitTunnelMiddleware{
+		splitTunnel: splitTunnel,
+		logger:      logger,
+	}
+}
+
+func (m *splitTunnelMiddleware) String() string {
+	return "split tunnel"
+}
+
+func (m *splitTunnelMiddleware) Wrap(next dns.Handler) dns.Handler { //nolint:ireturn
+	return &splitTunnelHandler{
+		next:        next,
+		splitTunnel: m.splitTunnel,
+		logger:      m.logger,
+	}
+}
+
+func (m *splitTunnelMiddleware) Stop() (err error) {
+	return nil
+}
+
+type splitTunnelHandler struct {
+	next        dns.Handler
+	splitTunnel SplitTunnel
+	logger      Logger
+}
+
+func (h *splitTunnelHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	if len(r.Question) == 0 || !h.splitTunnel.Matches(r.Question[0].Name) {
+		h.next.ServeDNS(w, r)
+		return
+	}
+
+	h.next.ServeDNS(&splitTunnelWriter{
+		ResponseWriter: w,
+		splitTunnel:    h.splitTunnel,
+		logger:         h.logger,
+	}, r)
+}
+
+type splitTunnelWriter struct {
+	dns.ResponseWriter
+	splitTunnel SplitTunnel
+	logger      Logger
+}
+
+func (w *splitTunnelWriter) WriteMsg(response *dns.Msg) error {
+	addrs, ttl := extractAddresses(response)
+	if len(addrs) > 0 {
+		const timeout = 5 * time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := w.splitTunnel.Add(ctx, addrs, ttl)
+		cancel()
+		if err != nil {
+			w.logger.Warn("adding resolved addresses to split tunnel: " + err.Error())
+		}
+	}
+	return w.ResponseWriter.WriteMsg(response)
+}
+
+// extractAddresses returns the IP addresses of the A and AAAA records
+// of the response answer, and the smallest TTL of these records.
+func extractAddresses(response *dns.Msg) (addrs []netip.Addr, ttl time.Duration) {
+	if response == nil {
+		return nil, 0
+	}
+
+	var minTTL uint32
+	for _, record := range response.Answer {
+		var addr netip.Addr
+		switch typed := record.(type) {
+		case *dns.A:
+			addr, _ = netip.AddrFromSlice(typed.A.To4())
+		case *dns.AAAA:
+			addr, _ = netip.AddrFromSlice(typed.AAAA.To16())
+		default:
+			continue
+		}
+		if !addr.IsValid() {
+			continue
+		}
+		addrs = append(addrs, addr)
+		recordTTL := record.Header().Ttl
+		if len(addrs) == 1 || recordTTL < minTTL {
+			minTTL = recordTTL
+		}
+	}
+	return addrs, time.Duration(minTTL) * time.Second
+}
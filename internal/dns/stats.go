@@ -0,0 +1,97 @@
+package dns
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// GetStats returns statistics on the DNS queries answered since
+// the program started, with the top queried and top blocked
+// domain names limited to the top number given.
+func (l *Loop) GetStats(top int) (stats models.DNSStats) {
+	return l.stats.snapshot(top)
+}
+
+// maxStatsNames is the maximum number of distinct domain names
+// counted, to bound memory usage. Queries for new domain names
+// are still counted in the totals once this limit is reached.
+const maxStatsNames = 10000
+
+type queryStats struct {
+	since        time.Time
+	queries      uint64
+	blocked      uint64
+	failed       uint64
+	nameToCount  map[string]uint64
+	blockedNames map[string]uint64
+	mutex        sync.Mutex
+}
+
+func newQueryStats(now time.Time) *queryStats {
+	return &queryStats{
+		since:        now,
+		nameToCount:  make(map[string]uint64),
+		blockedNames: make(map[string]uint64),
+	}
+}
+
+func (s *queryStats) record(name string, outcome queryOutcome) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.queries++
+	incrementBounded(s.nameToCount, name)
+	switch outcome {
+	case queryOutcomeBlocked:
+		s.blocked++
+		incrementBounded(s.blockedNames, name)
+	case queryOutcomeFailed:
+		s.failed++
+	case queryOutcomeAllowed:
+	}
+}
+
+func incrementBounded(nameToCount map[string]uint64, name string) {
+	_, exists := nameToCount[name]
+	if !exists && len(nameToCount) >= maxStatsNames {
+		return
+	}
+	nameToCount[name]++
+}
+
+func (s *queryStats) snapshot(top int) (stats models.DNSStats) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return models.DNSStats{
+		Since:      s.since,
+		Queries:    s.queries,
+		Blocked:    s.blocked,
+		Failed:     s.failed,
+		TopQueried: topDomains(s.nameToCount, top),
+		TopBlocked: topDomains(s.blockedNames, top),
+	}
+}
+
+// topDomains returns the domain names with the highest counts,
+// sorted by decreasing count and then by name.
+func topDomains(nameToCount map[string]uint64, top int) (domains []models.DomainCount) {
+	domains = make([]models.DomainCount, 0, len(nameToCount))
+	for name, count := range nameToCount {
+		domains = append(domains, models.DomainCount{Name: name, Count: count})
+	}
+	slices.SortFunc(domains, func(a, b models.DomainCount) int {
+		if a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	if len(domains) > top {
+		domains = domains[:top]
+	}
+	return domains
+}
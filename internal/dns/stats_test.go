@@ -0,0 +1,40 @@
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_queryStats(t *testing.T) {
+	t.Parallel()
+
+	since := time.Unix(1700000000, 0)
+	stats := newQueryStats(since)
+	stats.record("a.com", queryOutcomeAllowed)
+	stats.record("b.com", queryOutcomeBlocked)
+	stats.record("b.com", queryOutcomeBlocked)
+	stats.record("c.com", queryOutcomeFailed)
+	stats.record("c.com", queryOutcomeAllowed)
+	stats.record("d.com", queryOutcomeAllowed)
+
+	const top = 2
+	snapshot := stats.snapshot(top)
+
+	expected := models.DNSStats{
+		Since:   since,
+		Queries: 6,
+		Blocked: 2,
+		Failed:  1,
+		TopQueried: []models.DomainCount{
+			{Name: "b.com", Count: 2},
+			{Name: "c.com", Count: 2},
+		},
+		TopBlocked: []models.DomainCount{
+			{Name: "b.com", Count: 2},
+		},
+	}
+	assert.Equal(t, expected, snapshot)
+}
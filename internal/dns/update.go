@@ -41,5 +41,8 @@ func (l *Loop) updateFiles(ctx context.Context, settings settings.DNS) (err erro
 		return fmt.Errorf("updating filter: %w", err)
 	}
 
+	l.blockListSizes.set(len(result.BlockedHostnames),
+		len(result.BlockedIPs), len(result.BlockedIPPrefixes))
+
 	return nil
 }
@@ -0,0 +1,72 @@
+// Package events implements an in-memory publish-subscribe broker
+// used to notify clients of the control server of state changes.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Broker fans out published events to all its subscribers.
+// It is safe for concurrent use.
+type Broker struct {
+	subscribers map[uint64]chan Event
+	nextSubID   uint64
+	lastEventID uint64
+	mutex       sync.Mutex
+	timeNow     func() time.Time
+}
+
+func New() *Broker {
+	return &Broker{
+		subscribers: make(map[uint64]chan Event),
+		timeNow:     time.Now,
+	}
+}
+
+// Publish sends an event with the type and data given to all subscribers.
+// It never blocks: if a subscriber is not consuming its events fast enough,
+// the event is dropped for this subscriber.
+func (b *Broker) Publish(eventType string, data any) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.lastEventID++
+	event := Event{
+		ID:   b.lastEventID,
+		Type: eventType,
+		Time: b.timeNow(),
+		Data: data,
+	}
+	for _, subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving all events published from now on,
+// and an unsubscribe function which must be called once done to release
+// resources. The channel is closed by the unsubscribe function.
+func (b *Broker) Subscribe() (events <-chan Event, unsubscribe func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	const bufferSize = 32
+	ch := make(chan Event, bufferSize)
+	id := b.nextSubID
+	b.nextSubID++
+	b.subscribers[id] = ch
+
+	var once sync.Once
+	unsubscribe = func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+			delete(b.subscribers, id)
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Broker(t *testing.T) {
+	t.Parallel()
+
+	broker := New()
+	now := time.Unix(1, 0)
+	broker.timeNow = func() time.Time { return now }
+
+	broker.Publish(TypeTunnelDown, nil) // no subscriber
+
+	eventsA, unsubscribeA := broker.Subscribe()
+	eventsB, unsubscribeB := broker.Subscribe()
+
+	broker.Publish(TypeVPNStatus, VPNStatus{Status: "running"})
+
+	expected := Event{
+		ID:   2,
+		Type: TypeVPNStatus,
+		Time: now,
+		Data: VPNStatus{Status: "running"},
+	}
+	assert.Equal(t, expected, <-eventsA)
+	assert.Equal(t, expected, <-eventsB)
+
+	unsubscribeA()
+	unsubscribeA() // idempotent
+	_, ok := <-eventsA
+	assert.False(t, ok)
+
+	broker.Publish(TypeTunnelDown, nil)
+	assert.Equal(t, uint64(3), (<-eventsB).ID)
+	unsubscribeB()
+}
+
+func Test_Broker_Publish_slowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	broker := New()
+	events, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	const published = 100
+	for range published {
+		broker.Publish(TypeTunnelDown, nil)
+	}
+
+	assert.Len(t, events, cap(events))
+	assert.Equal(t, uint64(1), (<-events).ID)
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type Event struct {
+	// ID is the event identifier, strictly increasing
+	// for each event published.
+	ID uint64 `json:"id"`
+	// Type is the event type, for example [TypeVPNStatus].
+	Type string `json:"type"`
+	// Time is the time the event was published at.
+	Time time.Time `json:"time"`
+	// Data is the event type specific data, for example
+	// [VPNStatus] for the [TypeVPNStatus] type.
+	Data any `json:"data"`
+}
+
+const (
+	// TypeVPNStatus is the type for VPN loop status transitions,
+	// with the data being [VPNStatus].
+	TypeVPNStatus = "vpn_status"
+	// TypeTunnelUp is the type for when the VPN tunnel is up,
+	// with the data being [TunnelUp].
+	TypeTunnelUp = "tunnel_up"
+	// TypeTunnelDown is the type for when the VPN tunnel is torn down,
+	// with the data being nil.
+	TypeTunnelDown = "tunnel_down"
+	// TypeHealthCheck is the type for health check failures and
+	// recoveries, with the data being [HealthCheck].
+	TypeHealthCheck = "healthcheck"
+	// TypePortForwarded is the type for forwarded ports changes,
+	// with the data being [PortForwarded].
+	TypePortForwarded = "port_forwarded"
+	// TypePublicIP is the type for public IP address information updates,
+	// with the data being [models.PublicIP].
+	TypePublicIP = "public_ip"
+	// TypeUpdaterCompleted is the type for when the servers updater
+	// completes, with the data being [UpdaterCompleted].
+	TypeUpdaterCompleted = "updater_completed"
+)
+
+// Types returns all the event types.
+func Types() []string {
+	return []string{
+		TypeVPNStatus,
+		TypeTunnelUp,
+		TypeTunnelDown,
+		TypeHealthCheck,
+		TypePortForwarded,
+		TypePublicIP,
+		TypeUpdaterCompleted,
+	}
+}
+
+type VPNStatus struct {
+	Status models.LoopStatus `json:"status"`
+}
+
+type TunnelUp struct {
+	ServerIP   netip.Addr `json:"server_ip"`
+	ServerName string     `json:"server_name,omitempty"`
+	Interface  string     `json:"interface"`
+}
+
+type HealthCheck struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+type PortForwarded struct {
+	Ports []uint16 `json:"ports"`
+}
+
+type UpdaterCompleted struct {
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,51 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/firewall/iptables"
+	"github.com/qdm12/gluetun/internal/firewall/nftables"
+)
+
+var ErrBackendUnknown = errors.New("firewall backend is unknown")
+
+// newImpl creates the firewall implementation for the backend given.
+// The auto backend uses iptables if available, and falls back on nftables
+// programmed over netlink otherwise, for example for minimal images
+// without iptables programs.
+func newImpl(ctx context.Context, backend string, runner CmdRunner,
+	logger Logger,
+) (impl firewallImpl, err error) {
+	switch backend {
+	case settings.FirewallBackendIptables:
+		impl, err = iptables.New(ctx, runner, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating iptables firewall: %w", err)
+		}
+		return impl, nil
+	case settings.FirewallBackendNftables:
+		impl, err = nftables.New(runner, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating nftables firewall: %w", err)
+		}
+		return impl, nil
+	case settings.FirewallBackendAuto:
+		iptablesImpl, iptablesErr := iptables.New(ctx, runner, logger)
+		if iptablesErr == nil {
+			return iptablesImpl, nil
+		}
+
+		nftablesImpl, nftablesErr := nftables.New(runner, logger)
+		if nftablesErr != nil {
+			return nil, fmt.Errorf("creating firewall: iptables: %w; nftables: %w",
+				iptablesErr, nftablesErr)
+		}
+		logger.Info("using nftables since iptables is not available: " + iptablesErr.Error())
+		return nftablesImpl, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrBackendUnknown, backend)
+	}
+}
@@ -2,11 +2,9 @@ package firewall
 
 import (
 	"context"
-	"fmt"
 	"net/netip"
 	"sync"
 
-	"github.com/qdm12/gluetun/internal/firewall/iptables"
 	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/routing"
 )
@@ -33,14 +31,15 @@ type Config struct {
 }
 
 // NewConfig creates a new Config instance and returns an error
-// if no iptables implementation is available.
+// if no implementation is available for the firewall backend given,
+// which can be 'auto', 'iptables' or 'nftables'.
 func NewConfig(ctx context.Context, logger Logger,
 	runner CmdRunner, defaultRoutes []routing.DefaultRoute,
-	localNetworks []routing.LocalNetwork,
+	localNetworks []routing.LocalNetwork, backend string,
 ) (config *Config, err error) {
-	impl, err := iptables.New(ctx, runner, logger)
+	impl, err := newImpl(ctx, backend, runner, logger)
 	if err != nil {
-		return nil, fmt.Errorf("creating iptables firewall: %w", err)
+		return nil, err
 	}
 
 	return &Config{
@@ -0,0 +1,278 @@
+package nftables
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+
+	"github.com/mdlayher/netlink"
+)
+
+// Netlink attributes and values from linux/netfilter/nf_tables.h.
+const (
+	nftaListElem    = 1
+	nftaExprName    = 1
+	nftaExprData    = 2
+	nftaDataValue   = 1
+	nftaDataVerdict = 2
+	nftaVerdictCode = 1
+
+	nftaMetaDreg   = 1
+	nftaMetaKey    = 2
+	metaKeyMark    = 3
+	metaKeyIIFName = 6
+	metaKeyOIFName = 7
+	metaKeyNFProto = 15
+	metaKeyL4Proto = 16
+
+	nftaCmpSreg = 1
+	nftaCmpOp   = 2
+	nftaCmpData = 3
+	cmpOpEq     = 0
+	cmpOpNeq    = 1
+
+	nftaPayloadDreg      = 1
+	nftaPayloadBase      = 2
+	nftaPayloadOffset    = 3
+	nftaPayloadLen       = 4
+	payloadBaseNetwork   = 1
+	payloadBaseTransport = 2
+
+	nftaBitwiseSreg = 1
+	nftaBitwiseDreg = 2
+	nftaBitwiseLen  = 3
+	nftaBitwiseMask = 4
+	nftaBitwiseXor  = 5
+
+	nftaCtDreg         = 1
+	nftaCtKey          = 2
+	ctKeyState         = 0
+	ctStateEstablished = 1 << 1
+	ctStateRelated     = 1 << 2
+
+	nftaImmediateDreg = 1
+	nftaImmediateData = 2
+
+	nftaRedirRegProtoMin = 1
+
+	registerVerdict = 0
+	register1       = 1
+)
+
+// interfaceNameSize is the size of network interface names
+// loaded by meta expressions, including the null terminator.
+const interfaceNameSize = 16
+
+type expression struct {
+	name   string
+	encode func(ae *netlink.AttributeEncoder)
+}
+
+// expressions returns the expressions of the rule, where all matches
+// load data in the first register, followed by the verdict or redirection.
+func (r rule) expressions() (expressions []expression) {
+	family := r.ipFamily()
+	if family != 0 {
+		expressions = append(expressions,
+			metaLoad(metaKeyNFProto), compare(cmpOpEq, []byte{byte(family)}))
+	}
+
+	if r.inputInterface != "" {
+		expressions = append(expressions,
+			metaLoad(metaKeyIIFName), compare(cmpOpEq, interfaceNameBytes(r.inputInterface)))
+	}
+
+	if r.outputInterface != "" {
+		expressions = append(expressions,
+			metaLoad(metaKeyOIFName), compare(cmpOpEq, interfaceNameBytes(r.outputInterface)))
+	}
+
+	if r.source.IsValid() {
+		const isSource = true
+		expressions = append(expressions, prefixMatch(r.source, isSource)...)
+	}
+
+	if r.destination.IsValid() {
+		const isSource = false
+		expressions = append(expressions, prefixMatch(r.destination, isSource)...)
+	}
+
+	if r.protocol != 0 {
+		expressions = append(expressions,
+			metaLoad(metaKeyL4Proto), compare(cmpOpEq, []byte{r.protocol}))
+	}
+
+	const portLength = 2
+	if r.sourcePort != 0 {
+		const sourcePortOffset = 0
+		expressions = append(expressions,
+			payloadLoad(payloadBaseTransport, sourcePortOffset, portLength),
+			compare(cmpOpEq, binary.BigEndian.AppendUint16(nil, r.sourcePort)))
+	}
+
+	if r.destinationPort != 0 {
+		const destinationPortOffset = 2
+		expressions = append(expressions,
+			payloadLoad(payloadBaseTransport, destinationPortOffset, portLength),
+			compare(cmpOpEq, binary.BigEndian.AppendUint16(nil, r.destinationPort)))
+	}
+
+	if r.tcpFlagRST {
+		const flagsOffset, flagsLength, flagRST = 13, 1, 0x04
+		expressions = append(expressions,
+			payloadLoad(payloadBaseTransport, flagsOffset, flagsLength),
+			bitwiseAnd([]byte{flagRST}),
+			compare(cmpOpNeq, []byte{0}))
+	}
+
+	if r.matchExcludeMark {
+		expressions = append(expressions,
+			metaLoad(metaKeyMark),
+			compare(cmpOpNeq, binary.NativeEndian.AppendUint32(nil, r.excludeMark)))
+	}
+
+	if r.established {
+		const states = ctStateEstablished | ctStateRelated
+		expressions = append(expressions,
+			ctStateLoad(),
+			bitwiseAnd(binary.NativeEndian.AppendUint32(nil, states)),
+			compare(cmpOpNeq, make([]byte, 4))) //nolint:mnd
+	}
+
+	if r.redirectPort != 0 {
+		return append(expressions, immediatePort(r.redirectPort), redirect())
+	}
+	return append(expressions, immediateVerdict(r.verdict))
+}
+
+func interfaceNameBytes(name string) []byte {
+	b := make([]byte, interfaceNameSize)
+	copy(b, name)
+	return b
+}
+
+// prefixMatch returns expressions matching the source or
+// destination address of packets against the prefix given.
+func prefixMatch(prefix netip.Prefix, isSource bool) (expressions []expression) {
+	address := prefix.Masked().Addr()
+	var offset uint32
+	switch {
+	case address.Is4() && isSource:
+		offset = 12 //nolint:mnd
+	case address.Is4():
+		offset = 16 //nolint:mnd
+	case isSource:
+		offset = 8 //nolint:mnd
+	default:
+		offset = 24 //nolint:mnd
+	}
+	const bitsPerByte = 8
+	length := uint32(address.BitLen() / bitsPerByte) //nolint:gosec
+
+	expressions = append(expressions, payloadLoad(payloadBaseNetwork, offset, length))
+	if prefix.Bits() < address.BitLen() {
+		mask := net.CIDRMask(prefix.Bits(), address.BitLen())
+		expressions = append(expressions, bitwiseAnd(mask))
+	}
+	return append(expressions, compare(cmpOpEq, address.AsSlice()))
+}
+
+func metaLoad(key uint32) expression {
+	return expression{
+		name: "meta",
+		encode: func(ae *netlink.AttributeEncoder) {
+			ae.Uint32(nftaMetaKey, key)
+			ae.Uint32(nftaMetaDreg, register1)
+		},
+	}
+}
+
+func payloadLoad(base, offset, length uint32) expression {
+	return expression{
+		name: "payload",
+		encode: func(ae *netlink.AttributeEncoder) {
+			ae.Uint32(nftaPayloadDreg, register1)
+			ae.Uint32(nftaPayloadBase, base)
+			ae.Uint32(nftaPayloadOffset, offset)
+			ae.Uint32(nftaPayloadLen, length)
+		},
+	}
+}
+
+func ctStateLoad() expression {
+	return expression{
+		name: "ct",
+		encode: func(ae *netlink.AttributeEncoder) {
+			ae.Uint32(nftaCtKey, ctKeyState)
+			ae.Uint32(nftaCtDreg, register1)
+		},
+	}
+}
+
+func compare(op uint32, data []byte) expression {
+	return expression{
+		name: "cmp",
+		encode: func(ae *netlink.AttributeEncoder) {
+			ae.Uint32(nftaCmpSreg, register1)
+			ae.Uint32(nftaCmpOp, op)
+			ae.Nested(nftaCmpData, encodeValue(data))
+		},
+	}
+}
+
+// bitwiseAnd applies the mask given to the first register.
+func bitwiseAnd(mask []byte) expression {
+	return expression{
+		name: "bitwise",
+		encode: func(ae *netlink.AttributeEncoder) {
+			ae.Uint32(nftaBitwiseSreg, register1)
+			ae.Uint32(nftaBitwiseDreg, register1)
+			ae.Uint32(nftaBitwiseLen, uint32(len(mask))) //nolint:gosec
+			ae.Nested(nftaBitwiseMask, encodeValue(mask))
+			ae.Nested(nftaBitwiseXor, encodeValue(make([]byte, len(mask))))
+		},
+	}
+}
+
+func immediateVerdict(v verdict) expression {
+	return expression{
+		name: "immediate",
+		encode: func(ae *netlink.AttributeEncoder) {
+			ae.Uint32(nftaImmediateDreg, registerVerdict)
+			ae.Nested(nftaImmediateData, func(nae *netlink.AttributeEncoder) error {
+				nae.Nested(nftaDataVerdict, func(nae *netlink.AttributeEncoder) error {
+					nae.Uint32(nftaVerdictCode, uint32(v))
+					return nil
+				})
+				return nil
+			})
+		},
+	}
+}
+
+func immediatePort(port uint16) expression {
+	return expression{
+		name: "immediate",
+		encode: func(ae *netlink.AttributeEncoder) {
+			ae.Uint32(nftaImmediateDreg, register1)
+			ae.Nested(nftaImmediateData, encodeValue(binary.BigEndian.AppendUint16(nil, port)))
+		},
+	}
+}
+
+// redirect redirects packets to the local port stored in the first register.
+func redirect() expression {
+	return expression{
+		name: "redir",
+		encode: func(ae *netlink.AttributeEncoder) {
+			ae.Uint32(nftaRedirRegProtoMin, register1)
+		},
+	}
+}
+
+func encodeValue(data []byte) func(nae *netlink.AttributeEncoder) error {
+	return func(nae *netlink.AttributeEncoder) error {
+		nae.Bytes(nftaDataValue, data)
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+package nftables
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_rule_expressions(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		rule  rule
+		names []string
+	}{
+		"accept_all": {
+			rule:  rule{verdict: verdictAccept},
+			names: []string{"immediate"},
+		},
+		"input_interface": {
+			rule:  rule{inputInterface: "lo", verdict: verdictAccept},
+			names: []string{"meta", "cmp", "immediate"},
+		},
+		"output_to_vpn": {
+			rule: rule{
+				outputInterface: "eth0",
+				destination:     netip.MustParsePrefix("1.2.3.4/32"),
+				protocol:        protocolUDP,
+				destinationPort: 1194,
+				verdict:         verdictAccept,
+			},
+			names: []string{
+				"meta", "cmp", // family
+				"meta", "cmp", // output interface
+				"payload", "cmp", // destination address
+				"meta", "cmp", // protocol
+				"payload", "cmp", // destination port
+				"immediate",
+			},
+		},
+		"output_to_subnet": {
+			rule: rule{
+				destination: netip.MustParsePrefix("192.168.1.0/24"),
+				verdict:     verdictAccept,
+			},
+			names: []string{"meta", "cmp", "payload", "bitwise", "cmp", "immediate"},
+		},
+		"established": {
+			rule:  rule{established: true, verdict: verdictAccept},
+			names: []string{"ct", "bitwise", "cmp", "immediate"},
+		},
+		"redirect": {
+			rule: rule{
+				protocol:        protocolTCP,
+				destinationPort: 8000,
+				redirectPort:    9000,
+			},
+			names: []string{"meta", "cmp", "payload", "cmp", "immediate", "redir"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expressions := testCase.rule.expressions()
+
+			names := make([]string, len(expressions))
+			for i, expression := range expressions {
+				names[i] = expression.name
+			}
+			assert.Equal(t, testCase.names, names)
+		})
+	}
+}
+
+func Test_buildMessages(t *testing.T) {
+	t.Parallel()
+
+	messages, err := buildMessages(ruleset{})
+	assert.NoError(t, err)
+	// batch begin, add table, delete table and batch end
+	assert.Len(t, messages, 4)
+
+	messages, err = buildMessages(ruleset{
+		ipv4Drop: true,
+		input:    []rule{{inputInterface: "lo", verdict: verdictAccept}},
+	})
+	assert.NoError(t, err)
+	// batch begin, add table, delete table, add table,
+	// 4 chains, 1 input rule, 3 IPv4 drop rules and batch end
+	assert.Len(t, messages, 13)
+}
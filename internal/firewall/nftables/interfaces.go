@@ -0,0 +1,14 @@
+package nftables
+
+import "os/exec"
+
+type CmdRunner interface {
+	Run(cmd *exec.Cmd) (output string, err error)
+}
+
+type Logger interface {
+	Debug(s string)
+	Info(s string)
+	Warn(s string)
+	Error(s string)
+}
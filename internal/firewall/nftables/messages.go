@@ -0,0 +1,182 @@
+package nftables
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/mdlayher/netlink"
+	"github.com/ti-mo/netfilter"
+)
+
+// Netlink message types and attributes from linux/netfilter/nf_tables.h
+// and linux/netfilter/nfnetlink.h.
+const (
+	msgBatchBegin = 0x10
+	msgBatchEnd   = 0x11
+	msgNewTable   = 0
+	msgDelTable   = 2
+	msgNewChain   = 3
+	msgNewRule    = 6
+
+	nftaTableName = 1
+
+	nftaChainTable   = 1
+	nftaChainName    = 3
+	nftaChainHook    = 4
+	nftaChainPolicy  = 5
+	nftaChainType    = 7
+	nftaHookHookNum  = 1
+	nftaHookPriority = 2
+
+	nftaRuleTable       = 1
+	nftaRuleChain       = 2
+	nftaRuleExpressions = 4
+
+	hookPrerouting = 0
+	hookInput      = 1
+	hookForward    = 2
+	hookOutput     = 3
+)
+
+type chainSpec struct {
+	chain     chain
+	chainType string
+	hook      uint32
+	priority  int32
+}
+
+// chainSpecs are the base chains of the gluetun table, using the same
+// priorities as the iptables filter table and nat table prerouting chain.
+var chainSpecs = []chainSpec{ //nolint:gochecknoglobals
+	{chain: chainInput, chainType: "filter", hook: hookInput, priority: 0},
+	{chain: chainOutput, chainType: "filter", hook: hookOutput, priority: 0},
+	{chain: chainForward, chainType: "filter", hook: hookForward, priority: 0},
+	{chain: chainPrerouting, chainType: "nat", hook: hookPrerouting, priority: -100}, //nolint:mnd
+}
+
+// buildMessages returns the netlink messages of a transaction deleting
+// the gluetun table and re-creating it with the ruleset given, unless the
+// ruleset is empty. The table is first added so deleting it does not fail
+// if it does not exist. Every message except the batch delimiters requests
+// an acknowledgement.
+func buildMessages(r ruleset) (messages []netlink.Message, err error) {
+	type entry struct {
+		messageType netfilter.MessageType
+		flags       netlink.HeaderFlags
+		encode      func(ae *netlink.AttributeEncoder)
+	}
+	encodeTableName := func(ae *netlink.AttributeEncoder) {
+		ae.String(nftaTableName, tableName)
+	}
+	entries := []entry{
+		{messageType: msgNewTable, flags: netlink.Create, encode: encodeTableName},
+		{messageType: msgDelTable, encode: encodeTableName},
+	}
+
+	if !r.empty() {
+		entries = append(entries, entry{
+			messageType: msgNewTable, flags: netlink.Create, encode: encodeTableName,
+		})
+
+		for _, spec := range chainSpecs {
+			entries = append(entries, entry{
+				messageType: msgNewChain,
+				flags:       netlink.Create,
+				encode:      encodeChain(spec),
+			})
+		}
+
+		for _, spec := range chainSpecs {
+			for _, chainRule := range r.chainRules(spec.chain) {
+				entries = append(entries, entry{
+					messageType: msgNewRule,
+					flags:       netlink.Create | netlink.Append,
+					encode:      encodeRule(spec.chain, chainRule),
+				})
+			}
+		}
+	}
+
+	messages = make([]netlink.Message, 0, len(entries)+2) //nolint:mnd
+	messages = append(messages, batchMessage(msgBatchBegin))
+	for _, entry := range entries {
+		ae := netlink.NewAttributeEncoder()
+		ae.ByteOrder = binary.BigEndian
+		entry.encode(ae)
+		message, err := netfilter.EncodeNetlink(netfilter.Header{
+			SubsystemID: netfilter.NFSubsysNFTables,
+			MessageType: entry.messageType,
+			Family:      netfilter.ProtoInet,
+			Flags:       netlink.Request | netlink.Acknowledge | entry.flags,
+		}, ae)
+		if err != nil {
+			return nil, fmt.Errorf("encoding message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	messages = append(messages, batchMessage(msgBatchEnd))
+
+	return messages, nil
+}
+
+// chainRules returns the rules of the chain given, followed by the rules
+// dropping IPv4 and IPv6 packets for the filter chains if needed.
+func (r ruleset) chainRules(c chain) (rules []rule) {
+	rules = slices.Clone(*r.rules(c))
+	if c == chainPrerouting {
+		return rules
+	}
+	if r.ipv4Drop {
+		rules = append(rules, rule{family: familyIPv4, verdict: verdictDrop})
+	}
+	if r.ipv6Drop {
+		rules = append(rules, rule{family: familyIPv6, verdict: verdictDrop})
+	}
+	return rules
+}
+
+func batchMessage(messageType netfilter.MessageType) netlink.Message {
+	// The batch message cannot fail to encode.
+	message, _ := netfilter.EncodeNetlink(netfilter.Header{
+		MessageType: messageType,
+		Family:      netfilter.ProtoUnspec,
+		ResourceID:  uint16(netfilter.NFSubsysNFTables),
+		Flags:       netlink.Request,
+	}, netlink.NewAttributeEncoder())
+	return message
+}
+
+func encodeChain(spec chainSpec) func(ae *netlink.AttributeEncoder) {
+	return func(ae *netlink.AttributeEncoder) {
+		ae.String(nftaChainTable, tableName)
+		ae.String(nftaChainName, string(spec.chain))
+		ae.Nested(nftaChainHook, func(nae *netlink.AttributeEncoder) error {
+			nae.Uint32(nftaHookHookNum, spec.hook)
+			nae.Int32(nftaHookPriority, spec.priority)
+			return nil
+		})
+		ae.Uint32(nftaChainPolicy, uint32(verdictAccept))
+		ae.String(nftaChainType, spec.chainType)
+	}
+}
+
+func encodeRule(c chain, r rule) func(ae *netlink.AttributeEncoder) {
+	return func(ae *netlink.AttributeEncoder) {
+		ae.String(nftaRuleTable, tableName)
+		ae.String(nftaRuleChain, string(c))
+		ae.Nested(nftaRuleExpressions, func(nae *netlink.AttributeEncoder) error {
+			for _, expression := range r.expressions() {
+				nae.Nested(nftaListElem, func(nae *netlink.AttributeEncoder) error {
+					nae.String(nftaExprName, expression.name)
+					nae.Nested(nftaExprData, func(nae *netlink.AttributeEncoder) error {
+						expression.encode(nae)
+						return nil
+					})
+					return nil
+				})
+			}
+			return nil
+		})
+	}
+}
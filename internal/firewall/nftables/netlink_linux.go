@@ -0,0 +1,50 @@
+package nftables
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// commitRuleset replaces the gluetun table with the ruleset given in
+// a single netlink batch, which the kernel applies atomically.
+func commitRuleset(r ruleset) (err error) {
+	messages, err := buildMessages(r)
+	if err != nil {
+		return fmt.Errorf("building messages: %w", err)
+	}
+
+	conn, err := netlink.Dial(unix.NETLINK_NETFILTER, nil)
+	if err != nil {
+		return fmt.Errorf("dialing netfilter: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.SendMessages(messages)
+	if err != nil {
+		return fmt.Errorf("sending messages: %w", err)
+	}
+
+	// Each message except the batch begin and end messages is acknowledged,
+	// and the first error acknowledgement aborts the whole batch.
+	acknowledgements := len(messages) - 2 //nolint:mnd
+	for acknowledgements > 0 {
+		received, err := conn.Receive()
+		if err != nil {
+			return fmt.Errorf("receiving acknowledgement: %w", err)
+		}
+		acknowledgements -= len(received)
+	}
+
+	return nil
+}
+
+func kernelRelease() (release string, err error) {
+	var uname unix.Utsname
+	err = unix.Uname(&uname)
+	if err != nil {
+		return "", err
+	}
+	return unix.ByteSliceToString(uname.Release[:]), nil
+}
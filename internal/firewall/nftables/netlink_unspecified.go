@@ -0,0 +1,15 @@
+//go:build !linux
+
+package nftables
+
+import "errors"
+
+var ErrNotSupported = errors.New("nftables is only supported on Linux")
+
+func commitRuleset(ruleset) error {
+	return ErrNotSupported
+}
+
+func kernelRelease() (release string, err error) {
+	return "", ErrNotSupported
+}
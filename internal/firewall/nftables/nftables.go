@@ -0,0 +1,74 @@
+// Package nftables implements the firewall using nftables programmed
+// directly over netlink, without relying on any nft or iptables program.
+// All the rules live in a dedicated table of the inet family, which is
+// re-created atomically in a single netlink transaction on each change.
+package nftables
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type Config struct {
+	runner CmdRunner
+	logger Logger
+	// commit commits the ruleset given to the kernel in a single transaction.
+	// It is a field so it can be swapped in tests.
+	commit func(ruleset ruleset) error
+
+	// State
+	ruleset ruleset
+	mutex   sync.Mutex
+}
+
+// New creates a new nftables firewall, and returns an error if
+// nftables is not supported by the kernel. Any gluetun table left
+// over from a previous run is removed.
+func New(runner CmdRunner, logger Logger) (*Config, error) {
+	c := &Config{
+		runner: runner,
+		logger: logger,
+		commit: commitRuleset,
+	}
+
+	err := c.commit(c.ruleset)
+	if err != nil {
+		return nil, fmt.Errorf("removing existing %s table: %w", tableName, err)
+	}
+
+	return c, nil
+}
+
+// Version returns the nftables backend name with the
+// kernel release, since there is no userspace program involved.
+func (c *Config) Version(context.Context) (version string, err error) {
+	release, err := kernelRelease()
+	if err != nil {
+		return "", fmt.Errorf("getting kernel release: %w", err)
+	}
+	return "nftables (kernel " + release + ")", nil
+}
+
+// update applies the modify function to a copy of the current ruleset,
+// commits the modified copy to the kernel and only then sets it as the
+// current ruleset, so the state is unchanged if the transaction fails.
+func (c *Config) update(modify func(ruleset *ruleset) (changed bool, err error)) (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	modified := c.ruleset.copy()
+	changed, err := modify(&modified)
+	if err != nil {
+		return err
+	} else if !changed {
+		return nil
+	}
+
+	err = c.commit(modified)
+	if err != nil {
+		return fmt.Errorf("committing nftables transaction: %w", err)
+	}
+	c.ruleset = modified
+	return nil
+}
@@ -0,0 +1,50 @@
+package nftables
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// RunUserPostRules runs the lines of the file given starting with 'nft ' using
+// the nft program. Since the gluetun table is re-created on each change, these
+// rules should be added to their own table. Lines for iptables are ignored
+// with a warning.
+func (c *Config) RunUserPostRules(ctx context.Context, filepath string) error {
+	b, err := os.ReadFile(filepath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	iptablesLinesIgnored := 0
+	for line := range strings.SplitSeq(string(b), "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case strings.HasPrefix(fields[0], "iptables") || strings.HasPrefix(fields[0], "ip6tables"):
+			iptablesLinesIgnored++
+			continue
+		case fields[0] != "nft":
+			continue
+		}
+
+		cmd := exec.CommandContext(ctx, "nft", fields[1:]...) // #nosec G204
+		c.logger.Debug(cmd.String())
+		output, err := c.runner.Run(cmd)
+		if err != nil {
+			return fmt.Errorf("command failed: %q: %s: %w", line, output, err)
+		}
+	}
+
+	if iptablesLinesIgnored > 0 {
+		c.logger.Warn(fmt.Sprintf("ignoring %d iptables rules from %s "+
+			"since the nftables firewall backend is used",
+			iptablesLinesIgnored, filepath))
+	}
+	return nil
+}
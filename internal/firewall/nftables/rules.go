@@ -0,0 +1,263 @@
+package nftables
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+var (
+	ErrPolicyUnknown  = errors.New("unknown policy")
+	ErrFamilyMismatch = errors.New("source and destination IP families do not match")
+)
+
+// SaveAndRestore saves the current ruleset and returns a restore
+// function that can be called to restore the saved ruleset.
+func (c *Config) SaveAndRestore(context.Context) (restore func(context.Context), err error) {
+	c.mutex.Lock()
+	saved := c.ruleset.copy()
+	c.mutex.Unlock()
+
+	restore = func(context.Context) {
+		err := c.update(func(ruleset *ruleset) (changed bool, err error) {
+			*ruleset = saved.copy()
+			return true, nil
+		})
+		if err != nil {
+			c.logger.Warn("restoring nftables ruleset failed: " + err.Error())
+		}
+	}
+	return restore, nil
+}
+
+func (c *Config) SetIPv4AllPolicies(_ context.Context, policy string) error {
+	drop, err := parsePolicy(policy)
+	if err != nil {
+		return err
+	}
+	return c.update(func(ruleset *ruleset) (changed bool, err error) {
+		ruleset.ipv4Drop = drop
+		return true, nil
+	})
+}
+
+func (c *Config) SetIPv6AllPolicies(_ context.Context, policy string) error {
+	drop, err := parsePolicy(policy)
+	if err != nil {
+		return err
+	}
+	return c.update(func(ruleset *ruleset) (changed bool, err error) {
+		ruleset.ipv6Drop = drop
+		return true, nil
+	})
+}
+
+func parsePolicy(policy string) (drop bool, err error) {
+	switch policy {
+	case "ACCEPT":
+		return false, nil
+	case "DROP":
+		return true, nil
+	default:
+		return false, fmt.Errorf("%w: %s", ErrPolicyUnknown, policy)
+	}
+}
+
+// changeRules adds the rules given to the chains given, or removes them if
+// remove is true, in a single transaction. Rules to remove which are not
+// found are ignored, as the iptables implementation does.
+func (c *Config) changeRules(remove bool, chainRules ...chainRule) error {
+	return c.update(func(ruleset *ruleset) (changed bool, err error) {
+		for _, chainRule := range chainRules {
+			ruleChanged := ruleset.addOrRemove(chainRule.chain, chainRule.rule, remove)
+			if !ruleChanged {
+				c.logger.Debug(fmt.Sprintf("rule %+v not found in chain %s",
+					chainRule.rule, chainRule.chain))
+			}
+			changed = changed || ruleChanged
+		}
+		return changed, nil
+	})
+}
+
+type chainRule struct {
+	chain chain
+	rule  rule
+}
+
+// interfaceName returns the empty string matching all
+// interfaces if the interface given is "*".
+func interfaceName(intf string) string {
+	if intf == "*" {
+		return ""
+	}
+	return intf
+}
+
+func (c *Config) AcceptInputThroughInterface(_ context.Context, intf string) error {
+	const remove = false
+	return c.changeRules(remove, chainRule{chain: chainInput, rule: rule{
+		inputInterface: interfaceName(intf),
+		verdict:        verdictAccept,
+	}})
+}
+
+func (c *Config) AcceptInputToSubnet(_ context.Context, intf string, destination netip.Prefix) error {
+	const remove = false
+	return c.changeRules(remove, chainRule{chain: chainInput, rule: rule{
+		inputInterface: interfaceName(intf),
+		destination:    destination,
+		verdict:        verdictAccept,
+	}})
+}
+
+func (c *Config) AcceptOutputThroughInterface(_ context.Context, intf string, remove bool) error {
+	return c.changeRules(remove, chainRule{chain: chainOutput, rule: rule{
+		outputInterface: interfaceName(intf),
+		verdict:         verdictAccept,
+	}})
+}
+
+func (c *Config) AcceptEstablishedRelatedTraffic(context.Context) error {
+	const remove = false
+	established := rule{established: true, verdict: verdictAccept}
+	return c.changeRules(remove,
+		chainRule{chain: chainOutput, rule: established},
+		chainRule{chain: chainInput, rule: established},
+	)
+}
+
+func (c *Config) AcceptOutputTrafficToVPN(_ context.Context,
+	defaultInterface string, connection models.Connection, remove bool,
+) error {
+	protocol := protocolUDP
+	if connection.Protocol == "tcp" || connection.Protocol == "tcp-client" {
+		protocol = protocolTCP
+	}
+	return c.changeRules(remove, chainRule{chain: chainOutput, rule: rule{
+		outputInterface: defaultInterface,
+		destination:     netip.PrefixFrom(connection.IP, connection.IP.BitLen()),
+		protocol:        protocol,
+		destinationPort: connection.Port,
+		verdict:         verdictAccept,
+	}})
+}
+
+// AcceptOutputFromIPToSubnet accepts outgoing traffic from sourceIP to destinationSubnet
+// on the interface intf. If intf is "*", the rule applies to all interfaces.
+// If remove is true, the rule is removed instead of added.
+func (c *Config) AcceptOutputFromIPToSubnet(_ context.Context,
+	intf string, sourceIP netip.Addr, destinationSubnet netip.Prefix, remove bool,
+) error {
+	if sourceIP.Is4() != destinationSubnet.Addr().Is4() {
+		return fmt.Errorf("%w: accept output from %s to %s",
+			ErrFamilyMismatch, sourceIP, destinationSubnet)
+	}
+	return c.changeRules(remove, chainRule{chain: chainOutput, rule: rule{
+		outputInterface: interfaceName(intf),
+		source:          netip.PrefixFrom(sourceIP, sourceIP.BitLen()),
+		destination:     destinationSubnet,
+		verdict:         verdictAccept,
+	}})
+}
+
+// AcceptIpv6MulticastOutput accepts outgoing traffic to the IPv6 multicast address
+// ff02::1:ff00:0/104, which is used for NDP (Neighbor Discovery Protocol) to resolve
+// IPv6 addresses to MAC addresses.
+func (c *Config) AcceptIpv6MulticastOutput(_ context.Context, intf string) error {
+	const remove = false
+	return c.changeRules(remove, chainRule{chain: chainOutput, rule: rule{
+		outputInterface: interfaceName(intf),
+		destination:     netip.MustParsePrefix("ff02::1:ff00:0/104"),
+		verdict:         verdictAccept,
+	}})
+}
+
+// AcceptInputToPort accepts incoming traffic on the specified port, for both TCP and UDP
+// protocols, on the interface intf. If intf is "*", the rules apply to all interfaces.
+// If remove is true, the rules are removed instead of added.
+func (c *Config) AcceptInputToPort(_ context.Context, intf string, port uint16, remove bool) error {
+	return c.changeRules(remove,
+		chainRule{chain: chainInput, rule: rule{
+			inputInterface:  interfaceName(intf),
+			protocol:        protocolTCP,
+			destinationPort: port,
+			verdict:         verdictAccept,
+		}},
+		chainRule{chain: chainInput, rule: rule{
+			inputInterface:  interfaceName(intf),
+			protocol:        protocolUDP,
+			destinationPort: port,
+			verdict:         verdictAccept,
+		}},
+	)
+}
+
+// RedirectPort redirects incoming traffic on the specified source port to the
+// specified destination port, for both TCP and UDP protocols, on the interface intf.
+// If intf is "*", the redirection applies to all interfaces. If remove is true,
+// the redirection is removed instead of added.
+func (c *Config) RedirectPort(_ context.Context, intf string,
+	sourcePort, destinationPort uint16, remove bool,
+) (err error) {
+	var chainRules []chainRule
+	for _, protocol := range []uint8{protocolTCP, protocolUDP} {
+		chainRules = append(chainRules,
+			chainRule{chain: chainPrerouting, rule: rule{
+				inputInterface:  interfaceName(intf),
+				protocol:        protocol,
+				destinationPort: sourcePort,
+				redirectPort:    destinationPort,
+			}},
+			chainRule{chain: chainInput, rule: rule{
+				inputInterface:  interfaceName(intf),
+				protocol:        protocol,
+				destinationPort: destinationPort,
+				verdict:         verdictAccept,
+			}},
+		)
+	}
+
+	err = c.changeRules(remove, chainRules...)
+	if err != nil {
+		return fmt.Errorf("redirecting source port %d to destination port %d on interface %s: %w",
+			sourcePort, destinationPort, intf, err)
+	}
+	return nil
+}
+
+// TempDropOutputTCPRST temporarily drops outgoing TCP RST packets to the specified address and port,
+// for any TCP packets not marked with the excludeMark given.
+// This is necessary for TCP path MTU discovery to work, as the kernel will try to terminate the connection
+// by sending a TCP RST packet, although we want to handle the connection manually.
+func (c *Config) TempDropOutputTCPRST(_ context.Context,
+	src, dst netip.AddrPort, excludeMark int) (
+	revert func(ctx context.Context) error, err error,
+) {
+	dropRule := chainRule{chain: chainOutput, rule: rule{
+		source:           netip.PrefixFrom(src.Addr(), src.Addr().BitLen()),
+		sourcePort:       src.Port(),
+		destination:      netip.PrefixFrom(dst.Addr(), dst.Addr().BitLen()),
+		destinationPort:  dst.Port(),
+		protocol:         protocolTCP,
+		tcpFlagRST:       true,
+		matchExcludeMark: true,
+		excludeMark:      uint32(excludeMark), //nolint:gosec
+		verdict:          verdictDrop,
+	}}
+
+	const remove = false
+	err = c.changeRules(remove, dropRule)
+	if err != nil {
+		return nil, fmt.Errorf("adding rule: %w", err)
+	}
+
+	revert = func(context.Context) error {
+		const remove = true
+		return c.changeRules(remove, dropRule)
+	}
+	return revert, nil
+}
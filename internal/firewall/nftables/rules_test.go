@@ -0,0 +1,143 @@
+package nftables
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string) {}
+func (noopLogger) Info(string)  {}
+func (noopLogger) Warn(string)  {}
+func (noopLogger) Error(string) {}
+
+func newTestConfig(commitErr error) (c *Config, committed *[]ruleset) {
+	committed = new([]ruleset)
+	c = &Config{
+		logger: noopLogger{},
+		commit: func(r ruleset) error {
+			if commitErr != nil {
+				return commitErr
+			}
+			*committed = append(*committed, r)
+			return nil
+		},
+	}
+	return c, committed
+}
+
+func Test_Config_AcceptInputToPort(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, committed := newTestConfig(nil)
+
+	const remove = false
+	err := c.AcceptInputToPort(ctx, "tun0", 8000, remove)
+	require.NoError(t, err)
+
+	expectedInput := []rule{
+		{inputInterface: "tun0", protocol: protocolTCP, destinationPort: 8000, verdict: verdictAccept},
+		{inputInterface: "tun0", protocol: protocolUDP, destinationPort: 8000, verdict: verdictAccept},
+	}
+	assert.Equal(t, expectedInput, c.ruleset.input)
+	require.Len(t, *committed, 1)
+
+	err = c.AcceptInputToPort(ctx, "tun0", 8000, !remove)
+	require.NoError(t, err)
+	assert.Empty(t, c.ruleset.input)
+	require.Len(t, *committed, 2)
+	assert.True(t, (*committed)[1].empty())
+
+	// Removing rules not present does not commit anything.
+	err = c.AcceptInputToPort(ctx, "tun0", 8000, !remove)
+	require.NoError(t, err)
+	assert.Len(t, *committed, 2)
+}
+
+func Test_Config_update_commitError(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	c, _ := newTestConfig(errTest)
+
+	err := c.AcceptInputThroughInterface(context.Background(), "lo")
+	require.ErrorIs(t, err, errTest)
+	assert.EqualError(t, err, "committing nftables transaction: test error")
+	assert.True(t, c.ruleset.empty())
+}
+
+func Test_Config_SaveAndRestore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, committed := newTestConfig(nil)
+
+	restore, err := c.SaveAndRestore(ctx)
+	require.NoError(t, err)
+
+	err = c.SetIPv4AllPolicies(ctx, "DROP")
+	require.NoError(t, err)
+	err = c.AcceptOutputFromIPToSubnet(ctx, "eth0", netip.MustParseAddr("10.0.0.2"),
+		netip.MustParsePrefix("192.168.1.0/24"), false)
+	require.NoError(t, err)
+	assert.False(t, c.ruleset.empty())
+
+	restore(ctx)
+	assert.True(t, c.ruleset.empty())
+	require.Len(t, *committed, 3)
+	assert.True(t, (*committed)[2].empty())
+}
+
+func Test_Config_SetIPv6AllPolicies(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newTestConfig(nil)
+
+	err := c.SetIPv6AllPolicies(context.Background(), "REJECT")
+	require.ErrorIs(t, err, ErrPolicyUnknown)
+	assert.EqualError(t, err, "unknown policy: REJECT")
+
+	err = c.SetIPv6AllPolicies(context.Background(), "DROP")
+	require.NoError(t, err)
+	assert.True(t, c.ruleset.ipv6Drop)
+	assert.Equal(t, []rule{{family: familyIPv6, verdict: verdictDrop}},
+		c.ruleset.chainRules(chainOutput))
+	assert.Empty(t, c.ruleset.chainRules(chainPrerouting))
+}
+
+func Test_Config_AcceptOutputFromIPToSubnet(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newTestConfig(nil)
+
+	err := c.AcceptOutputFromIPToSubnet(context.Background(), "*",
+		netip.MustParseAddr("10.0.0.2"), netip.MustParsePrefix("::1/128"), false)
+	require.ErrorIs(t, err, ErrFamilyMismatch)
+	assert.EqualError(t, err, "source and destination IP families do not match: "+
+		"accept output from 10.0.0.2 to ::1/128")
+}
+
+func Test_Config_TempDropOutputTCPRST(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, _ := newTestConfig(nil)
+
+	revert, err := c.TempDropOutputTCPRST(ctx,
+		netip.MustParseAddrPort("10.0.0.2:5000"),
+		netip.MustParseAddrPort("1.2.3.4:443"), 0x1234)
+	require.NoError(t, err)
+	require.Len(t, c.ruleset.output, 1)
+	assert.True(t, c.ruleset.output[0].tcpFlagRST)
+
+	err = revert(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, c.ruleset.output)
+}
@@ -0,0 +1,149 @@
+package nftables
+
+import (
+	"net/netip"
+	"slices"
+)
+
+// tableName is the name of the inet family table containing all the rules.
+const tableName = "gluetun"
+
+type chain string
+
+const (
+	chainInput      chain = "input"
+	chainOutput     chain = "output"
+	chainForward    chain = "forward"
+	chainPrerouting chain = "prerouting"
+)
+
+type verdict uint32
+
+const (
+	verdictDrop   verdict = 0
+	verdictAccept verdict = 1
+)
+
+// ipFamily is the netfilter protocol family of a rule,
+// where the zero value matches both IPv4 and IPv6.
+type ipFamily uint8
+
+const (
+	familyIPv4 ipFamily = 2
+	familyIPv6 ipFamily = 10
+)
+
+func familyOf(ip netip.Addr) ipFamily {
+	if ip.Is4() {
+		return familyIPv4
+	}
+	return familyIPv6
+}
+
+const (
+	protocolTCP uint8 = 6
+	protocolUDP uint8 = 17
+)
+
+// ruleset is the content of the gluetun table, which
+// does not exist in the kernel if the ruleset is empty.
+type ruleset struct {
+	// ipv4Drop and ipv6Drop are true to drop IPv4 and IPv6
+	// packets not accepted by a rule of the input, output
+	// and forward chains, mimicking iptables DROP policies.
+	ipv4Drop   bool
+	ipv6Drop   bool
+	input      []rule
+	output     []rule
+	forward    []rule
+	prerouting []rule
+}
+
+func (r ruleset) empty() bool {
+	return !r.ipv4Drop && !r.ipv6Drop &&
+		len(r.input) == 0 && len(r.output) == 0 &&
+		len(r.forward) == 0 && len(r.prerouting) == 0
+}
+
+func (r ruleset) copy() (copied ruleset) {
+	return ruleset{
+		ipv4Drop:   r.ipv4Drop,
+		ipv6Drop:   r.ipv6Drop,
+		input:      slices.Clone(r.input),
+		output:     slices.Clone(r.output),
+		forward:    slices.Clone(r.forward),
+		prerouting: slices.Clone(r.prerouting),
+	}
+}
+
+func (r *ruleset) rules(c chain) *[]rule {
+	switch c {
+	case chainInput:
+		return &r.input
+	case chainOutput:
+		return &r.output
+	case chainForward:
+		return &r.forward
+	case chainPrerouting:
+		return &r.prerouting
+	default:
+		panic("unknown chain: " + string(c))
+	}
+}
+
+// addOrRemove appends the rule given to the chain, or removes the first
+// identical rule of the chain if remove is true. It returns false if
+// the rule to remove is not found.
+func (r *ruleset) addOrRemove(c chain, ruleToChange rule, remove bool) (changed bool) {
+	rules := r.rules(c)
+	if !remove {
+		*rules = append(*rules, ruleToChange)
+		return true
+	}
+
+	index := slices.Index(*rules, ruleToChange)
+	if index == -1 {
+		return false
+	}
+	*rules = slices.Delete(*rules, index, index+1)
+	return true
+}
+
+// rule is a rule of the gluetun table. It is comparable
+// so rules can be found in a chain to be removed.
+type rule struct {
+	// family is the family of packets matched, and is deduced
+	// from the source and destination if they are set.
+	family          ipFamily
+	inputInterface  string
+	outputInterface string
+	source          netip.Prefix
+	destination     netip.Prefix
+	protocol        uint8
+	sourcePort      uint16
+	destinationPort uint16
+	// tcpFlagRST matches TCP packets with the RST flag set.
+	tcpFlagRST bool
+	// matchExcludeMark is true to match packets not marked with excludeMark.
+	matchExcludeMark bool
+	excludeMark      uint32
+	// established matches packets of established or related connections.
+	established bool
+	verdict     verdict
+	// redirectPort, if not zero, redirects packets to this
+	// local port instead of applying the verdict.
+	redirectPort uint16
+}
+
+func (r rule) ipFamily() ipFamily {
+	switch {
+	case r.family != 0:
+		return r.family
+	case r.source.IsValid():
+		return familyOf(r.source.Addr())
+	case r.destination.IsValid():
+		return familyOf(r.destination.Addr())
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,24 @@
+package nftables
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+)
+
+func Test_zzTry(t *testing.T) {
+	c, err := New(nil, noopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	must := func(err error) { if err != nil { t.Fatal(err) } }
+	must(c.SetIPv4AllPolicies(ctx, "DROP"))
+	must(c.AcceptInputThroughInterface(ctx, "lo"))
+	must(c.AcceptEstablishedRelatedTraffic(ctx))
+	must(c.AcceptOutputFromIPToSubnet(ctx, "eth0", netip.MustParseAddr("10.0.0.2"), netip.MustParsePrefix("192.168.0.0/16"), false))
+	must(c.AcceptIpv6MulticastOutput(ctx, "eth0"))
+	must(c.RedirectPort(ctx, "tun0", 80, 8080, false))
+	_, err = c.TempDropOutputTCPRST(ctx, netip.MustParseAddrPort("10.0.0.2:5"), netip.MustParseAddrPort("1.1.1.1:443"), 5)
+	must(err)
+}
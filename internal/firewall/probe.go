@@ -0,0 +1,75 @@
+package firewall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/qdm12/gluetun/internal/netlink"
+)
+
+// TempAllowOutputToIPs temporarily allows outgoing traffic to the given IP addresses
+// through the default route interfaces, for example to probe candidate VPN servers
+// before connecting to one of them. The revert function returned must be called
+// to remove the rules added. If the firewall is disabled, this is a no-op.
+func (c *Config) TempAllowOutputToIPs(ctx context.Context, ips []netip.Addr) (
+	revert func(ctx context.Context) error, err error,
+) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	var rules []outputRule
+	revert = func(ctx context.Context) error {
+		c.stateMutex.Lock()
+		defer c.stateMutex.Unlock()
+		return c.removeOutputRules(ctx, rules)
+	}
+
+	if !c.enabled {
+		return revert, nil
+	}
+
+	const remove = false
+	for _, ip := range ips {
+		subnet := netip.PrefixFrom(ip, ip.BitLen())
+		for _, defaultRoute := range c.defaultRoutes {
+			defaultRouteIsIPv6 := defaultRoute.Family == netlink.FamilyV6
+			if ip.Is6() != defaultRouteIsIPv6 {
+				continue
+			}
+
+			err = c.impl.AcceptOutputFromIPToSubnet(ctx, defaultRoute.NetInterface,
+				defaultRoute.AssignedIP, subnet, remove)
+			if err != nil {
+				err = fmt.Errorf("allowing output traffic to %s: %w", ip, err)
+				return nil, errors.Join(err, c.removeOutputRules(ctx, rules))
+			}
+			rules = append(rules, outputRule{
+				intf:     defaultRoute.NetInterface,
+				sourceIP: defaultRoute.AssignedIP,
+				subnet:   subnet,
+			})
+		}
+	}
+
+	return revert, nil
+}
+
+type outputRule struct {
+	intf     string
+	sourceIP netip.Addr
+	subnet   netip.Prefix
+}
+
+func (c *Config) removeOutputRules(ctx context.Context, rules []outputRule) error {
+	const remove = true
+	var errs []error
+	for _, rule := range rules {
+		err := c.impl.AcceptOutputFromIPToSubnet(ctx, rule.intf, rule.sourceIP, rule.subnet, remove)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("removing output rule to %s: %w", rule.subnet.Addr(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,25 @@
+package firewall
+
+import (
+	"net/netip"
+	"slices"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// GetState returns a snapshot of the firewall state.
+func (c *Config) GetState() (state models.FirewallState) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	state = models.FirewallState{
+		Enabled:         c.enabled,
+		VPNInterface:    c.vpnIntf,
+		OutboundSubnets: slices.Clone(c.outboundSubnets),
+	}
+	if c.vpnConnection.IP.IsValid() {
+		state.VPNServer = netip.AddrPortFrom(c.vpnConnection.IP, c.vpnConnection.Port)
+		state.VPNProtocol = c.vpnConnection.Protocol
+	}
+	return state
+}
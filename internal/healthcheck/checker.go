@@ -9,6 +9,7 @@ import (
 	"net/netip"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/qdm12/gluetun/internal/healthcheck/dns"
@@ -28,6 +29,10 @@ type Checker struct {
 
 	icmpNotPermitted *bool
 
+	// Check results counters, kept across restarts
+	successes atomic.Uint64
+	failures  atomic.Uint64
+
 	// Internal periodic service signals
 	stop context.CancelFunc
 	done <-chan struct{}
@@ -101,6 +106,7 @@ func (c *Checker) Start(ctx context.Context) (runError <-chan error, err error)
 	runErrorCh := make(chan error, 1)
 	runError = runErrorCh
 	err = c.startupCheck(ctx)
+	c.recordResult(ctx, err)
 	if err != nil {
 		err = fmt.Errorf("startup check: %w", err)
 		if !c.startupOnFail {
@@ -129,6 +135,7 @@ func (c *Checker) Start(ctx context.Context) (runError <-chan error, err error)
 				return
 			case <-smallCheckTimer.C:
 				err := c.smallPeriodicCheck(ctx)
+				c.recordResult(ctx, err)
 				if err != nil {
 					err = fmt.Errorf("small periodic check: %w", err)
 				}
@@ -140,6 +147,7 @@ func (c *Checker) Start(ctx context.Context) (runError <-chan error, err error)
 				smallCheckTimer.Reset(smallCheckPeriod)
 			case <-fullCheckTimer.C:
 				err := c.fullPeriodicCheck(ctx)
+				c.recordResult(ctx, err)
 				if err != nil {
 					err = fmt.Errorf("full periodic check: %w", err)
 				}
@@ -165,6 +173,22 @@ func (c *Checker) Stop() error {
 	return nil
 }
 
+// GetResultCounts returns the number of successful and failed
+// checks since the [Checker] was created.
+func (c *Checker) GetResultCounts() (successes, failures uint64) {
+	return c.successes.Load(), c.failures.Load()
+}
+
+func (c *Checker) recordResult(ctx context.Context, err error) {
+	switch {
+	case ctx.Err() != nil: // checker stopped, result is not relevant
+	case err != nil:
+		c.failures.Add(1)
+	default:
+		c.successes.Add(1)
+	}
+}
+
 func (c *Checker) smallPeriodicCheck(ctx context.Context) error {
 	c.configMutex.Lock()
 	icmpTargetIPs := make([]netip.Addr, len(c.icmpTargetIPs))
@@ -9,6 +9,7 @@ import (
 
 func newHandler(ctx context.Context, wg *sync.WaitGroup, logger Logger,
 	stealth, verbose bool, username, password string,
+	stats *connectionStats,
 ) http.Handler {
 	const httpTimeout = 24 * time.Hour
 	return &handler{
@@ -23,6 +24,7 @@ func newHandler(ctx context.Context, wg *sync.WaitGroup, logger Logger,
 		stealth:  stealth,
 		username: username,
 		password: password,
+		stats:    stats,
 	}
 }
 
@@ -33,6 +35,7 @@ type handler struct {
 	logger             Logger
 	verbose, stealth   bool
 	username, password string
+	stats              *connectionStats
 }
 
 func (h *handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
@@ -42,6 +45,8 @@ func (h *handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Re
 	if !h.isAuthorized(responseWriter, request) {
 		return
 	}
+	h.stats.opened()
+	defer h.stats.closed()
 	request.Header.Del("Proxy-Connection")
 	request.Header.Del("Proxy-Authenticate")
 	request.Header.Del("Proxy-Authorization")
@@ -14,6 +14,7 @@ import (
 type Loop struct {
 	statusManager *loopstate.State
 	state         *state.State
+	stats         *connectionStats
 	// Other objects
 	logger Logger
 	// Internal channels and locks
@@ -33,12 +34,13 @@ func NewLoop(logger Logger, settings settings.HTTPProxy) *Loop {
 	stopped := make(chan struct{})
 
 	statusManager := loopstate.New(constants.Stopped,
-		start, running, stop, stopped)
+		start, running, stop, stopped, nil)
 	state := state.New(statusManager, settings)
 
 	return &Loop{
 		statusManager: statusManager,
 		state:         state,
+		stats:         &connectionStats{},
 		logger:        logger,
 		start:         start,
 		running:       running,
@@ -19,11 +19,12 @@ type Server struct {
 func New(ctx context.Context, address string, logger Logger,
 	stealth, verbose bool, username, password string,
 	readHeaderTimeout, readTimeout time.Duration,
+	stats *connectionStats,
 ) *Server {
 	wg := &sync.WaitGroup{}
 	return &Server{
 		address:           address,
-		handler:           newHandler(ctx, wg, logger, stealth, verbose, username, password),
+		handler:           newHandler(ctx, wg, logger, stealth, verbose, username, password, stats),
 		logger:            logger,
 		internalWG:        wg,
 		readHeaderTimeout: readHeaderTimeout,
@@ -0,0 +1,25 @@
+package httpproxy
+
+import "sync/atomic"
+
+// connectionStats holds connection counters which are kept
+// across proxy server restarts.
+type connectionStats struct {
+	total  atomic.Uint64
+	active atomic.Int64
+}
+
+func (s *connectionStats) opened() {
+	s.total.Add(1)
+	s.active.Add(1)
+}
+
+func (s *connectionStats) closed() {
+	s.active.Add(-1)
+}
+
+// GetConnectionCounts returns the total number of authorized proxy
+// connections handled and the number of currently active ones.
+func (l *Loop) GetConnectionCounts() (total uint64, active int64) {
+	return l.stats.total.Load(), l.stats.active.Load()
+}
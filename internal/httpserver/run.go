@@ -2,9 +2,13 @@ package httpserver
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 )
 
 // Run runs the HTTP server until ctx is canceled.
@@ -39,8 +43,7 @@ func (s *Server) Run(ctx context.Context, ready chan<- struct{}, done chan<- str
 		}
 	}()
 
-	listenConfig := &net.ListenConfig{}
-	listener, err := listenConfig.Listen(listenCtx, "tcp", s.address)
+	listener, err := s.listen(listenCtx)
 	if err != nil {
 		close(s.addressSet)
 		close(crashed) // stop shutdown goroutine
@@ -50,11 +53,19 @@ func (s *Server) Run(ctx context.Context, ready chan<- struct{}, done chan<- str
 		return
 	}
 
-	s.address = listener.Addr().String()
+	if !strings.HasPrefix(s.address, unixAddressPrefix) {
+		s.address = listener.Addr().String()
+	}
 	close(s.addressSet)
 
+	scheme := "http"
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+		scheme = "https"
+	}
+
 	// note: no further write so no need to mutex
-	s.logger.Info("http server listening on " + s.address)
+	s.logger.Info(scheme + " server listening on " + s.address)
 	close(ready)
 
 	err = server.Serve(listener)
@@ -71,3 +82,23 @@ func (s *Server) Run(ctx context.Context, ready chan<- struct{}, done chan<- str
 	}
 	close(done)
 }
+
+const unixAddressPrefix = "unix:"
+
+// listen listens on the TCP address or on the Unix domain
+// socket path if the address is prefixed with 'unix:'.
+// Any existing file at the socket path is removed first,
+// since it is most likely left over from a previous run.
+func (s *Server) listen(ctx context.Context) (listener net.Listener, err error) {
+	listenConfig := &net.ListenConfig{}
+	unixSocketPath, isUnixSocket := strings.CutPrefix(s.address, unixAddressPrefix)
+	if !isUnixSocket {
+		return listenConfig.Listen(ctx, "tcp", s.address)
+	}
+
+	err = os.Remove(unixSocketPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("removing existing unix socket file: %w", err)
+	}
+	return listenConfig.Listen(ctx, "unix", unixSocketPath)
+}
@@ -1,6 +1,7 @@
 package httpserver
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"time"
@@ -16,6 +17,8 @@ type Server struct {
 	readHeaderTimeout time.Duration
 	readTimeout       time.Duration
 	shutdownTimeout   time.Duration
+	// tlsConfig is nil if TLS is disabled.
+	tlsConfig *tls.Config
 }
 
 // New creates a new HTTP server with the given settings.
@@ -27,6 +30,15 @@ func New(settings Settings) (s *Server, err error) {
 		return nil, fmt.Errorf("http server settings validation failed: %w", err)
 	}
 
+	var tlsConfig *tls.Config
+	if settings.TLSCertPath != "" {
+		tlsConfig, err = newTLSConfig(settings.TLSCertPath, settings.TLSKeyPath,
+			settings.TLSClientCAPath, settings.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating TLS configuration: %w", err)
+		}
+	}
+
 	return &Server{
 		address:           settings.Address,
 		addressSet:        make(chan struct{}),
@@ -35,5 +47,6 @@ func New(settings Settings) (s *Server, err error) {
 		readHeaderTimeout: settings.ReadHeaderTimeout,
 		readTimeout:       settings.ReadTimeout,
 		shutdownTimeout:   settings.ShutdownTimeout,
+		tlsConfig:         tlsConfig,
 	}, nil
 }
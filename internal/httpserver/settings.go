@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/qdm12/gosettings"
@@ -14,6 +15,8 @@ import (
 
 type Settings struct {
 	// Address is the server listening address.
+	// It can be a Unix domain socket path prefixed
+	// with 'unix:', such as 'unix:/tmp/server.sock'.
 	// It defaults to :8000.
 	Address string
 	// Handler is the HTTP Handler to use.
@@ -31,6 +34,22 @@ type Settings struct {
 	// ShutdownTimeout is the shutdown timeout duration
 	// of the HTTP server. It defaults to 3 seconds if left unset.
 	ShutdownTimeout time.Duration
+	// TLSCertPath is the path to the PEM encoded TLS certificate
+	// file. If set with TLSKeyPath, the server serves HTTPS and
+	// reloads the certificate and key pair when their files change.
+	// It defaults to the empty string, to serve plaintext HTTP.
+	TLSCertPath string
+	// TLSKeyPath is the path to the PEM encoded TLS private key file.
+	// It must be set if TLSCertPath is set.
+	TLSKeyPath string
+	// TLSClientCAPath is the path to the PEM encoded certificate
+	// authorities file used to verify client certificates.
+	// Client certificates are optional, and a request has
+	// verified certificate chains only if its client certificate
+	// is signed by one of these authorities.
+	// It defaults to the empty string, to not request client
+	// certificates, and can only be set if TLS is enabled.
+	TLSClientCAPath string
 }
 
 func (s *Settings) SetDefaults() {
@@ -50,6 +69,9 @@ func (s Settings) Copy() Settings {
 		ReadHeaderTimeout: s.ReadHeaderTimeout,
 		ReadTimeout:       s.ReadTimeout,
 		ShutdownTimeout:   s.ShutdownTimeout,
+		TLSCertPath:       s.TLSCertPath,
+		TLSKeyPath:        s.TLSKeyPath,
+		TLSClientCAPath:   s.TLSClientCAPath,
 	}
 }
 
@@ -62,6 +84,9 @@ func (s *Settings) OverrideWith(other Settings) {
 	s.ReadHeaderTimeout = gosettings.OverrideWithComparable(s.ReadHeaderTimeout, other.ReadHeaderTimeout)
 	s.ReadTimeout = gosettings.OverrideWithComparable(s.ReadTimeout, other.ReadTimeout)
 	s.ShutdownTimeout = gosettings.OverrideWithComparable(s.ShutdownTimeout, other.ShutdownTimeout)
+	s.TLSCertPath = gosettings.OverrideWithComparable(s.TLSCertPath, other.TLSCertPath)
+	s.TLSKeyPath = gosettings.OverrideWithComparable(s.TLSKeyPath, other.TLSKeyPath)
+	s.TLSClientCAPath = gosettings.OverrideWithComparable(s.TLSClientCAPath, other.TLSClientCAPath)
 }
 
 var (
@@ -70,12 +95,21 @@ var (
 	ErrReadHeaderTimeoutTooSmall = errors.New("read header timeout is too small")
 	ErrReadTimeoutTooSmall       = errors.New("read timeout is too small")
 	ErrShutdownTimeoutTooSmall   = errors.New("shutdown timeout is too small")
+	ErrUnixSocketPathEmpty       = errors.New("unix socket path is empty")
+	ErrTLSKeyPairIncomplete      = errors.New("TLS certificate and key paths must be set together")
+	ErrTLSClientCAWithoutTLS     = errors.New("TLS client CA path requires TLS to be enabled")
 )
 
 func (s Settings) Validate() (err error) {
-	err = validate.ListeningAddress(s.Address, os.Getuid())
-	if err != nil {
-		return err
+	unixSocketPath, isUnixSocket := strings.CutPrefix(s.Address, unixAddressPrefix)
+	switch {
+	case isUnixSocket && unixSocketPath == "":
+		return fmt.Errorf("%w", ErrUnixSocketPathEmpty)
+	case !isUnixSocket:
+		err = validate.ListeningAddress(s.Address, os.Getuid())
+		if err != nil {
+			return err
+		}
 	}
 
 	if s.Handler == nil {
@@ -106,6 +140,14 @@ func (s Settings) Validate() (err error) {
 			s.ShutdownTimeout, minShutdownTimeout)
 	}
 
+	if (s.TLSCertPath == "") != (s.TLSKeyPath == "") {
+		return fmt.Errorf("%w", ErrTLSKeyPairIncomplete)
+	}
+
+	if s.TLSClientCAPath != "" && s.TLSCertPath == "" {
+		return fmt.Errorf("%w", ErrTLSClientCAWithoutTLS)
+	}
+
 	return nil
 }
 
@@ -115,6 +157,14 @@ func (s Settings) ToLinesNode() (node *gotree.Node) {
 	node.Appendf("Read header timeout: %s", s.ReadHeaderTimeout)
 	node.Appendf("Read timeout: %s", s.ReadTimeout)
 	node.Appendf("Shutdown timeout: %s", s.ShutdownTimeout)
+	if s.TLSCertPath != "" {
+		tlsNode := node.Appendf("TLS:")
+		tlsNode.Appendf("Certificate file path: %s", s.TLSCertPath)
+		tlsNode.Appendf("Key file path: %s", s.TLSKeyPath)
+		if s.TLSClientCAPath != "" {
+			tlsNode.Appendf("Client CA file path: %s", s.TLSClientCAPath)
+		}
+	}
 	return node
 }
 
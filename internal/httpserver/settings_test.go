@@ -247,6 +247,39 @@ func Test_Settings_Validate(t *testing.T) {
 			errWrapped: ErrShutdownTimeoutTooSmall,
 			errMessage: "shutdown timeout is too small: 1ms must be at least 5ms",
 		},
+		"empty unix socket path": {
+			settings: Settings{
+				Address: "unix:",
+			},
+			errWrapped: ErrUnixSocketPathEmpty,
+			errMessage: "unix socket path is empty",
+		},
+		"TLS key path missing": {
+			settings: Settings{
+				Address:           ":8000",
+				Handler:           someHandler,
+				Logger:            someLogger,
+				ReadHeaderTimeout: time.Millisecond,
+				ReadTimeout:       time.Millisecond,
+				ShutdownTimeout:   time.Second,
+				TLSCertPath:       "/cert.pem",
+			},
+			errWrapped: ErrTLSKeyPairIncomplete,
+			errMessage: "TLS certificate and key paths must be set together",
+		},
+		"TLS client CA without TLS": {
+			settings: Settings{
+				Address:           "unix:/tmp/server.sock",
+				Handler:           someHandler,
+				Logger:            someLogger,
+				ReadHeaderTimeout: time.Millisecond,
+				ReadTimeout:       time.Millisecond,
+				ShutdownTimeout:   time.Second,
+				TLSClientCAPath:   "/ca.pem",
+			},
+			errWrapped: ErrTLSClientCAWithoutTLS,
+			errMessage: "TLS client CA path requires TLS to be enabled",
+		},
 		"valid settings": {
 			settings: Settings{
 				Address:           ":8000",
@@ -0,0 +1,123 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrClientCANoCertificate = errors.New("no certificate found in client CA file")
+
+// newTLSConfig returns a TLS configuration serving the certificate
+// and key pair from the file paths given, reloading them when their
+// files modification times change. If clientCAPath is not empty,
+// client certificates are requested and verified if given.
+func newTLSConfig(certPath, keyPath, clientCAPath string, logger Logger) (
+	config *tls.Config, err error,
+) {
+	reloader := &certReloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+		logger:   logger,
+	}
+	err = reloader.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	config = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.getCertificate,
+	}
+
+	if clientCAPath != "" {
+		pemData, err := os.ReadFile(clientCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CA file: %w", err)
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("%w: %s", ErrClientCANoCertificate, clientCAPath)
+		}
+		// Client certificates are optional so clients can still
+		// use other authentication methods of the auth middleware.
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+type certReloader struct {
+	certPath    string
+	keyPath     string
+	logger      Logger
+	certificate *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	mutex       sync.Mutex
+}
+
+func (c *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	certModTime, keyModTime, err := c.modTimes()
+	if err != nil {
+		c.logger.Warn("checking TLS files, keeping current certificate: " + err.Error())
+		return c.certificate, nil
+	}
+
+	if certModTime.Equal(c.certModTime) && keyModTime.Equal(c.keyModTime) {
+		return c.certificate, nil
+	}
+
+	err = c.reloadLocked()
+	if err != nil {
+		// Files may be in the middle of being written, keep the
+		// current certificate and retry on the next handshake.
+		c.logger.Warn("reloading TLS certificate, keeping current certificate: " + err.Error())
+		return c.certificate, nil
+	}
+	c.logger.Info("TLS certificate reloaded")
+	return c.certificate, nil
+}
+
+func (c *certReloader) reload() (err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.reloadLocked()
+}
+
+// reloadLocked must be called with the mutex locked.
+func (c *certReloader) reloadLocked() (err error) {
+	certModTime, keyModTime, err := c.modTimes()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(c.certPath, c.keyPath)
+	if err != nil {
+		return fmt.Errorf("loading TLS key pair: %w", err)
+	}
+
+	c.certificate = &certificate
+	c.certModTime = certModTime
+	c.keyModTime = keyModTime
+	return nil
+}
+
+func (c *certReloader) modTimes() (certModTime, keyModTime time.Time, err error) {
+	certStat, err := os.Stat(c.certPath)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("getting certificate file information: %w", err)
+	}
+	keyStat, err := os.Stat(c.keyPath)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("getting key file information: %w", err)
+	}
+	return certStat.ModTime(), keyStat.ModTime(), nil
+}
@@ -0,0 +1,82 @@
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestKeyPair(t *testing.T, certPath, keyPath, commonName string,
+	modTime time.Time,
+) {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template,
+		&privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	err = os.WriteFile(certPath, certPEM, 0o600)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	err = os.WriteFile(keyPath, keyPEM, 0o600)
+	require.NoError(t, err)
+
+	for _, path := range []string{certPath, keyPath} {
+		err = os.Chtimes(path, modTime, modTime)
+		require.NoError(t, err)
+	}
+}
+
+func Test_newTLSConfig_reload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	modTime := time.Unix(1700000000, 0)
+	writeTestKeyPair(t, certPath, keyPath, "first", modTime)
+
+	config, err := newTLSConfig(certPath, keyPath, "", &testLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+
+	certificate, err := config.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", certificate.Leaf.Subject.CommonName)
+
+	writeTestKeyPair(t, certPath, keyPath, "second", modTime.Add(time.Second))
+
+	certificate, err = config.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", certificate.Leaf.Subject.CommonName)
+
+	// Invalid files keep the current certificate
+	err = os.WriteFile(keyPath, []byte("invalid"), 0o600)
+	require.NoError(t, err)
+	certificate, err = config.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", certificate.Leaf.Subject.CommonName)
+}
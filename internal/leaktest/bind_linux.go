@@ -0,0 +1,23 @@
+package leaktest
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// bindToInterface returns a socket control function binding
+// the socket to the network interface given, so packets are
+// sent through this interface regardless of the routing rules.
+func bindToInterface(name string) func(network, address string, rawConn syscall.RawConn) error {
+	return func(_, _ string, rawConn syscall.RawConn) error {
+		var bindErr error
+		err := rawConn.Control(func(fd uintptr) {
+			bindErr = unix.BindToDevice(int(fd), name)
+		})
+		if err == nil {
+			err = bindErr
+		}
+		return err
+	}
+}
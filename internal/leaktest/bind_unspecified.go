@@ -0,0 +1,16 @@
+//go:build !linux
+
+package leaktest
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// bindToInterface for platforms other than Linux is not
+// implemented, and the control function returned always errors.
+func bindToInterface(name string) func(network, address string, rawConn syscall.RawConn) error {
+	return func(_, _ string, _ syscall.RawConn) error {
+		return fmt.Errorf("%w: binding to interface %s", ErrBindNotSupported, name)
+	}
+}
@@ -0,0 +1,50 @@
+package leaktest
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/routing"
+)
+
+// explain returns an explanation of the probe result
+// using the default route and firewall state given.
+func explain(probe models.LeakProbe, target netip.Addr,
+	defaultRoute routing.DefaultRoute, firewall models.FirewallState,
+) (explanation string) {
+	path := "interface " + defaultRoute.NetInterface
+	if defaultRoute.Gateway.IsValid() {
+		path += " via gateway " + defaultRoute.Gateway.String()
+	}
+
+	if !probe.Escaped {
+		switch {
+		case !firewall.Enabled:
+			return "inconclusive: no response was received through " + path +
+				" but the firewall is disabled, so nothing blocks this traffic"
+		case firewall.VPNServer.IsValid():
+			return fmt.Sprintf("blocked: the firewall only allows traffic through %s "+
+				"to the VPN server %s", path, firewall.VPNServer)
+		default:
+			return "blocked: the firewall drops traffic through " + path
+		}
+	}
+
+	for _, subnet := range firewall.OutboundSubnets {
+		if subnet.Contains(target) {
+			return fmt.Sprintf("allowed: the target is in the outbound subnet %s "+
+				"allowed through %s", subnet, path)
+		}
+	}
+
+	switch {
+	case !firewall.Enabled:
+		return "leak: the firewall is disabled, so traffic escapes through " + path
+	case firewall.VPNServer.Addr() == target:
+		return "allowed: the target is the VPN server, which is allowed through " + path
+	default:
+		return "leak: the firewall rules do not block traffic through " + path +
+			", check for custom rules in /iptables/post-rules.txt"
+	}
+}
@@ -0,0 +1,90 @@
+package leaktest
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/routing"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_explain(t *testing.T) {
+	t.Parallel()
+
+	defaultRoute := routing.DefaultRoute{
+		NetInterface: "eth0",
+		Gateway:      netip.AddrFrom4([4]byte{172, 17, 0, 1}),
+	}
+
+	testCases := map[string]struct {
+		probe       models.LeakProbe
+		target      netip.Addr
+		firewall    models.FirewallState
+		explanation string
+	}{
+		"blocked_firewall_disabled": {
+			target: netip.AddrFrom4([4]byte{1, 1, 1, 1}),
+			explanation: "inconclusive: no response was received through interface eth0 " +
+				"via gateway 172.17.0.1 but the firewall is disabled, so nothing blocks this traffic",
+		},
+		"blocked_vpn_server": {
+			target: netip.AddrFrom4([4]byte{1, 1, 1, 1}),
+			firewall: models.FirewallState{
+				Enabled:   true,
+				VPNServer: netip.AddrPortFrom(netip.AddrFrom4([4]byte{2, 2, 2, 2}), 1194),
+			},
+			explanation: "blocked: the firewall only allows traffic through interface eth0 " +
+				"via gateway 172.17.0.1 to the VPN server 2.2.2.2:1194",
+		},
+		"blocked_no_vpn_server": {
+			target:      netip.AddrFrom4([4]byte{1, 1, 1, 1}),
+			firewall:    models.FirewallState{Enabled: true},
+			explanation: "blocked: the firewall drops traffic through interface eth0 via gateway 172.17.0.1",
+		},
+		"escaped_outbound_subnet": {
+			probe:  models.LeakProbe{Escaped: true},
+			target: netip.AddrFrom4([4]byte{1, 1, 1, 1}),
+			firewall: models.FirewallState{
+				Enabled:         true,
+				OutboundSubnets: []netip.Prefix{netip.MustParsePrefix("1.1.1.0/24")},
+			},
+			explanation: "allowed: the target is in the outbound subnet 1.1.1.0/24 " +
+				"allowed through interface eth0 via gateway 172.17.0.1",
+		},
+		"escaped_firewall_disabled": {
+			probe:  models.LeakProbe{Escaped: true},
+			target: netip.AddrFrom4([4]byte{1, 1, 1, 1}),
+			explanation: "leak: the firewall is disabled, so traffic escapes through " +
+				"interface eth0 via gateway 172.17.0.1",
+		},
+		"escaped_vpn_server": {
+			probe:  models.LeakProbe{Escaped: true},
+			target: netip.AddrFrom4([4]byte{1, 1, 1, 1}),
+			firewall: models.FirewallState{
+				Enabled:   true,
+				VPNServer: netip.AddrPortFrom(netip.AddrFrom4([4]byte{1, 1, 1, 1}), 443),
+			},
+			explanation: "allowed: the target is the VPN server, which is allowed through " +
+				"interface eth0 via gateway 172.17.0.1",
+		},
+		"escaped_leak": {
+			probe:    models.LeakProbe{Escaped: true},
+			target:   netip.AddrFrom4([4]byte{1, 1, 1, 1}),
+			firewall: models.FirewallState{Enabled: true},
+			explanation: "leak: the firewall rules do not block traffic through interface eth0 " +
+				"via gateway 172.17.0.1, check for custom rules in /iptables/post-rules.txt",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			explanation := explain(testCase.probe, testCase.target,
+				defaultRoute, testCase.firewall)
+
+			assert.Equal(t, testCase.explanation, explanation)
+		})
+	}
+}
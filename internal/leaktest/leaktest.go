@@ -0,0 +1,145 @@
+// Package leaktest verifies the firewall kill switch by sending packets
+// through the default route interfaces, bypassing the VPN interface,
+// and reporting which packets escaped.
+package leaktest
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/netlink"
+	"github.com/qdm12/gluetun/internal/routing"
+)
+
+var ErrBindNotSupported = errors.New("binding to a network interface is not supported")
+
+type Firewall interface {
+	GetState() (state models.FirewallState)
+}
+
+// Tester runs leak self-tests.
+type Tester struct {
+	defaultRoutes []routing.DefaultRoute
+	firewall      Firewall
+	timeout       time.Duration
+	timeNow       func() time.Time
+}
+
+// New creates a leak tester sending packets through the default route
+// interfaces given, and explaining the results with the firewall state.
+func New(defaultRoutes []routing.DefaultRoute, firewall Firewall) *Tester {
+	const timeout = 3 * time.Second
+	return &Tester{
+		defaultRoutes: defaultRoutes,
+		firewall:      firewall,
+		timeout:       timeout,
+		timeNow:       time.Now,
+	}
+}
+
+type probeTarget struct {
+	probeType string
+	address   netip.AddrPort
+}
+
+type probeJob struct {
+	target       probeTarget
+	defaultRoute routing.DefaultRoute
+}
+
+// Targets are public servers answering to each probe type.
+// The port is ignored for ICMP probes.
+var (
+	targetsIPv4 = []probeTarget{ //nolint:gochecknoglobals
+		{probeType: models.LeakProbeTCP, address: netip.MustParseAddrPort("1.1.1.1:443")},
+		{probeType: models.LeakProbeUDP, address: netip.MustParseAddrPort("162.159.200.123:123")},
+		{probeType: models.LeakProbeICMP, address: netip.MustParseAddrPort("1.1.1.1:0")},
+		{probeType: models.LeakProbeDNS, address: netip.MustParseAddrPort("8.8.8.8:53")},
+	}
+	targetsIPv6 = []probeTarget{ //nolint:gochecknoglobals
+		{probeType: models.LeakProbeTCP, address: netip.MustParseAddrPort("[2606:4700:4700::1111]:443")},
+		{probeType: models.LeakProbeUDP, address: netip.MustParseAddrPort("[2606:4700:f1::123]:123")},
+		{probeType: models.LeakProbeICMP, address: netip.MustParseAddrPort("[2606:4700:4700::1111]:0")},
+		{probeType: models.LeakProbeDNS, address: netip.MustParseAddrPort("[2001:4860:4860::8888]:53")},
+	}
+)
+
+// Run sends TCP, UDP, ICMP and DNS probes through each default route
+// interface and reports which probes escaped. A probe escapes if a
+// response is received, so packets sent but not answered are not detected.
+func (t *Tester) Run(ctx context.Context) (report models.LeakTestReport) {
+	report = models.LeakTestReport{
+		Time:     t.timeNow(),
+		Firewall: t.firewall.GetState(),
+	}
+
+	var jobs []probeJob
+	for _, defaultRoute := range t.defaultRoutes {
+		familyTargets := targetsIPv4
+		if defaultRoute.Family == netlink.FamilyV6 {
+			familyTargets = targetsIPv6
+		}
+		for _, target := range familyTargets {
+			jobs = append(jobs, probeJob{target: target, defaultRoute: defaultRoute})
+			report.Probes = append(report.Probes, models.LeakProbe{
+				Type:      target.probeType,
+				Interface: defaultRoute.NetInterface,
+				Target:    targetString(target),
+			})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := range report.Probes {
+		wg.Add(1)
+		go func(probe *models.LeakProbe, job probeJob) {
+			defer wg.Done()
+			err := runProbe(ctx, probe.Interface, job.target)
+			probe.Escaped = err == nil
+			if err != nil {
+				probe.Error = err.Error()
+			}
+			probe.Explanation = explain(*probe, job.target.address.Addr(),
+				job.defaultRoute, report.Firewall)
+		}(&report.Probes[i], jobs[i])
+	}
+	wg.Wait()
+
+	for _, probe := range report.Probes {
+		if probe.Escaped {
+			report.Leaked = true
+			break
+		}
+	}
+
+	return report
+}
+
+func targetString(target probeTarget) string {
+	if target.probeType == models.LeakProbeICMP {
+		return target.address.Addr().String()
+	}
+	return target.address.String()
+}
+
+func runProbe(ctx context.Context, intf string, target probeTarget) (err error) {
+	switch target.probeType {
+	case models.LeakProbeTCP:
+		return probeTCP(ctx, intf, target.address)
+	case models.LeakProbeUDP:
+		return probeUDP(ctx, intf, target.address)
+	case models.LeakProbeICMP:
+		return probeICMP(ctx, intf, target.address.Addr())
+	case models.LeakProbeDNS:
+		return probeDNS(ctx, intf, target.address)
+	default:
+		panic("unknown probe type: " + target.probeType)
+	}
+}
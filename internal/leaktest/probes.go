@@ -0,0 +1,142 @@
+package leaktest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+func probeTCP(ctx context.Context, intf string, address netip.AddrPort) (err error) {
+	dialer := net.Dialer{Control: bindToInterface(intf)}
+	conn, err := dialer.DialContext(ctx, "tcp", address.String())
+	if err != nil {
+		return fmt.Errorf("dialing: %w", err)
+	}
+	_ = conn.Close()
+	return nil
+}
+
+var ErrResponseTooShort = errors.New("response is too short")
+
+// probeUDP sends an NTP client request to the address given
+// and waits for a response.
+func probeUDP(ctx context.Context, intf string, address netip.AddrPort) (err error) {
+	dialer := net.Dialer{Control: bindToInterface(intf)}
+	conn, err := dialer.DialContext(ctx, "udp", address.String())
+	if err != nil {
+		return fmt.Errorf("dialing: %w", err)
+	}
+	defer conn.Close()
+	setDeadlineFromContext(ctx, conn)
+
+	const ntpPacketSize = 48
+	request := make([]byte, ntpPacketSize)
+	const ntpVersion3ClientMode = 0x1b
+	request[0] = ntpVersion3ClientMode
+	_, err = conn.Write(request)
+	if err != nil {
+		return fmt.Errorf("writing NTP request: %w", err)
+	}
+
+	response := make([]byte, ntpPacketSize)
+	n, err := conn.Read(response)
+	if err != nil {
+		return fmt.Errorf("reading NTP response: %w", err)
+	} else if n < ntpPacketSize {
+		return fmt.Errorf("%w: %d bytes", ErrResponseTooShort, n)
+	}
+	return nil
+}
+
+// probeDNS resolves a domain name using the DNS server address given.
+func probeDNS(ctx context.Context, intf string, address netip.AddrPort) (err error) {
+	dialer := net.Dialer{Control: bindToInterface(intf)}
+	resolver := net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address.String())
+		},
+	}
+	network := "ip4"
+	if address.Addr().Is6() {
+		network = "ip6"
+	}
+	_, err = resolver.LookupNetIP(ctx, network, "github.com")
+	if err != nil {
+		return fmt.Errorf("resolving: %w", err)
+	}
+	return nil
+}
+
+var ErrICMPReplyNotReceived = errors.New("ICMP echo reply not received")
+
+// probeICMP sends an ICMP echo request to the IP address given
+// and waits for the matching echo reply.
+func probeICMP(ctx context.Context, intf string, ip netip.Addr) (err error) {
+	network, protocol := "ip4:icmp", 1
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if ip.Is6() {
+		network, protocol = "ip6:ipv6-icmp", 58 //nolint:mnd
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	listenConfig := net.ListenConfig{Control: bindToInterface(intf)}
+	conn, err := listenConfig.ListenPacket(ctx, network, "")
+	if err != nil {
+		return fmt.Errorf("listening for ICMP packets: %w", err)
+	}
+	defer conn.Close()
+	setDeadlineFromContext(ctx, conn)
+
+	id := rand.IntN(1 << 16) //nolint:gosec,mnd
+	const seq = 1
+	message := icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("gluetun leak test")},
+	}
+	encoded, err := message.Marshal(nil)
+	if err != nil {
+		return fmt.Errorf("encoding ICMP message: %w", err)
+	}
+
+	_, err = conn.WriteTo(encoded, &net.IPAddr{IP: ip.AsSlice()})
+	if err != nil {
+		return fmt.Errorf("writing ICMP message: %w", err)
+	}
+
+	const maxICMPSize = 1500
+	buffer := make([]byte, maxICMPSize)
+	for {
+		n, _, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrICMPReplyNotReceived, err)
+		}
+		received, err := icmp.ParseMessage(protocol, buffer[:n])
+		if err != nil || received.Type != replyType {
+			continue
+		}
+		echo, ok := received.Body.(*icmp.Echo)
+		if ok && echo.ID == id && echo.Seq == seq {
+			return nil
+		}
+	}
+}
+
+type deadliner interface {
+	SetDeadline(t time.Time) error
+}
+
+func setDeadlineFromContext(ctx context.Context, conn deadliner) {
+	deadline, ok := ctx.Deadline()
+	if ok {
+		_ = conn.SetDeadline(deadline)
+	}
+}
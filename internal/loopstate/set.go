@@ -8,5 +8,15 @@ import "github.com/qdm12/gluetun/internal/models"
 func (s *State) SetStatus(status models.LoopStatus) {
 	s.statusMu.Lock()
 	defer s.statusMu.Unlock()
+	s.setStatus(status)
+}
+
+// setStatus sets the status and calls the onChange function
+// if the status changed. It must be called with statusMu locked.
+func (s *State) setStatus(status models.LoopStatus) {
+	changed := s.status != status
 	s.status = status
+	if changed && s.onChange != nil {
+		s.onChange(status)
+	}
 }
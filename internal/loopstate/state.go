@@ -6,16 +6,21 @@ import (
 	"github.com/qdm12/gluetun/internal/models"
 )
 
+// New creates a new loop state. The onChange function is optional
+// and can be left to nil. If set, it is called with the new status
+// each time the status changes, and must not block.
 func New(status models.LoopStatus,
 	start chan<- struct{}, running <-chan models.LoopStatus,
 	stop chan<- struct{}, stopped <-chan struct{},
+	onChange func(status models.LoopStatus),
 ) *State {
 	return &State{
-		status:  status,
-		start:   start,
-		running: running,
-		stop:    stop,
-		stopped: stopped,
+		status:   status,
+		onChange: onChange,
+		start:    start,
+		running:  running,
+		stop:     stop,
+		stopped:  stopped,
 	}
 }
 
@@ -24,6 +29,7 @@ type State struct {
 
 	status   models.LoopStatus
 	statusMu sync.RWMutex
+	onChange func(status models.LoopStatus)
 
 	start   chan<- struct{}
 	running <-chan models.LoopStatus
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"net/netip"
+	"time"
+)
+
+const (
+	ServerFailureConnection  = "connection"
+	ServerFailureHealthcheck = "healthcheck"
+)
+
+// ServerFailure is a failure of a VPN server.
+type ServerFailure struct {
+	Provider string     `json:"provider"`
+	Hostname string     `json:"hostname,omitempty"`
+	IP       netip.Addr `json:"ip"`
+	Time     time.Time  `json:"time"`
+	// Type is the failure type, which can be
+	// "connection" or "healthcheck".
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+// BlacklistedServer is a VPN server IP address excluded
+// from the server selection due to its recent failures.
+type BlacklistedServer struct {
+	Provider string          `json:"provider"`
+	Hostname string          `json:"hostname,omitempty"`
+	IP       netip.Addr      `json:"ip"`
+	Until    time.Time       `json:"until"`
+	Failures []ServerFailure `json:"failures"`
+}
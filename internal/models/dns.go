@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// DNSStats contains statistics on the DNS queries
+// answered by the DNS server.
+type DNSStats struct {
+	Since      time.Time     `json:"since"`
+	Queries    uint64        `json:"queries"`
+	Blocked    uint64        `json:"blocked"`
+	Failed     uint64        `json:"failed"`
+	TopQueried []DomainCount `json:"top_queried"`
+	TopBlocked []DomainCount `json:"top_blocked"`
+}
+
+type DomainCount struct {
+	Name  string `json:"name"`
+	Count uint64 `json:"count"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "net/netip"
+
+// FirewallState is a snapshot of the firewall state.
+type FirewallState struct {
+	Enabled bool `json:"enabled"`
+	// VPNServer is the VPN server address allowed through the
+	// default route interfaces, and is invalid if not set.
+	VPNServer netip.AddrPort `json:"vpn_server"`
+	// VPNProtocol is the VPN server protocol, which can be 'tcp' or 'udp'.
+	VPNProtocol string `json:"vpn_protocol,omitempty"`
+	// VPNInterface is the VPN network interface name.
+	VPNInterface    string         `json:"vpn_interface,omitempty"`
+	OutboundSubnets []netip.Prefix `json:"outbound_subnets"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	LeakProbeTCP  = "tcp"
+	LeakProbeUDP  = "udp"
+	LeakProbeICMP = "icmp"
+	LeakProbeDNS  = "dns"
+)
+
+// LeakTestReport is the result of a kill switch self-test,
+// sending packets through the default route interfaces
+// instead of the VPN interface.
+type LeakTestReport struct {
+	Time time.Time `json:"time"`
+	// Leaked is true if at least one probe escaped.
+	Leaked   bool          `json:"leaked"`
+	Firewall FirewallState `json:"firewall"`
+	Probes   []LeakProbe   `json:"probes"`
+}
+
+// LeakProbe is the result of sending a packet to a target
+// through a default route interface.
+type LeakProbe struct {
+	// Type is the probe type, which can be
+	// "tcp", "udp", "icmp" or "dns".
+	Type      string `json:"type"`
+	Interface string `json:"interface"`
+	Target    string `json:"target"`
+	// Escaped is true if a response was received from the target,
+	// meaning the packet escaped through the interface.
+	Escaped bool `json:"escaped"`
+	// Error is the error encountered if the probe did not escape.
+	Error       string `json:"error,omitempty"`
+	Explanation string `json:"explanation"`
+}
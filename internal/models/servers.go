@@ -156,6 +156,9 @@ type Servers struct {
 	Version   uint16   `json:"version"`
 	Timestamp int64    `json:"timestamp"`
 	Servers   []Server `json:"servers,omitempty"`
+	// Changes contains the most recent changes made
+	// by the updater, from oldest to newest.
+	Changes []ServersChange `json:"changes,omitempty"`
 }
 
 var ErrServersFormatNotSupported = errors.New("servers format not supported")
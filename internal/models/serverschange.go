@@ -0,0 +1,32 @@
+package models
+
+import "net/netip"
+
+// ServersChange is the difference between the servers of a
+// provider before and after an update of its servers.
+type ServersChange struct {
+	Provider string `json:"provider"`
+	// Timestamp is the Unix timestamp of the update.
+	Timestamp int64 `json:"timestamp"`
+	// Added and Removed contain the keys of the servers
+	// added and removed by the update.
+	Added   []string       `json:"added,omitempty"`
+	Removed []string       `json:"removed,omitempty"`
+	Changed []ServerChange `json:"changed,omitempty"`
+}
+
+// ServerChange is the difference between the old and new
+// data of a server present before and after an update.
+type ServerChange struct {
+	Key        string       `json:"key"`
+	IPsAdded   []netip.Addr `json:"ips_added,omitempty"`
+	IPsRemoved []netip.Addr `json:"ips_removed,omitempty"`
+	// Fields contains the JSON names of other fields changed,
+	// such as "port_forward" or "wgpubkey".
+	Fields []string `json:"fields,omitempty"`
+}
+
+// IsEmpty returns true if no server was added, removed or changed.
+func (s ServersChange) IsEmpty() bool {
+	return len(s.Added) == 0 && len(s.Removed) == 0 && len(s.Changed) == 0
+}
@@ -0,0 +1,17 @@
+package models
+
+// UpdaterProgress is the progress of the current
+// or last servers update run by the updater.
+type UpdaterProgress struct {
+	// Providers are the providers to update in this run.
+	Providers []string `json:"providers"`
+	// CurrentProvider is the provider being updated,
+	// and is empty if no provider is being updated.
+	CurrentProvider string `json:"current_provider,omitempty"`
+	// ServersFetched maps each provider updated to
+	// its number of servers fetched.
+	ServersFetched map[string]int `json:"servers_fetched"`
+	// Errors maps each provider which failed to update
+	// to its error message.
+	Errors map[string]string `json:"errors"`
+}
@@ -0,0 +1,146 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_qBittorrent_notify(t *testing.T) {
+	t.Parallel()
+
+	preferencesSet := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/auth/login":
+			if r.PostFormValue("username") != "user" || r.PostFormValue("password") != "pass" {
+				_, _ = w.Write([]byte("Fails."))
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "session"})
+			_, _ = w.Write([]byte("Ok."))
+		case "/api/v2/app/setPreferences":
+			cookie, err := r.Cookie("SID")
+			if err != nil || cookie.Value != "session" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			assert.Equal(t, `{"listen_port":1000}`, r.PostFormValue("json"))
+			preferencesSet = true
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := newQBittorrent(server.Client(), settings.PortForwardingClient{
+		URL:      server.URL + "/",
+		Username: "user",
+		Password: "pass",
+	})
+
+	err := client.notify(context.Background(), EventUp, Data{Ports: []uint16{1000}})
+	require.NoError(t, err)
+	assert.True(t, preferencesSet)
+
+	client.password = "wrong"
+	err = client.notify(context.Background(), EventUp, Data{Ports: []uint16{1000}})
+	assert.ErrorIs(t, err, ErrQBittorrentLoginFailed)
+}
+
+func Test_transmission_notify(t *testing.T) {
+	t.Parallel()
+
+	const sessionID = "session-id"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/transmission/rpc", r.URL.Path)
+		if r.Header.Get("X-Transmission-Session-Id") != sessionID {
+			w.Header().Set("X-Transmission-Session-Id", sessionID)
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"method":"session-set","arguments":{"peer-port":1000}}`, string(body))
+		_, _ = w.Write([]byte(`{"result":"success","arguments":{}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTransmission(server.Client(), settings.PortForwardingClient{
+		URL:      server.URL,
+		Username: "user",
+		Password: "pass",
+	})
+
+	err := client.notify(context.Background(), EventChange, Data{Ports: []uint16{1000}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	err = client.notify(context.Background(), EventDown, Data{Ports: []uint16{1000}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
+
+func Test_deluge_notify(t *testing.T) {
+	t.Parallel()
+
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+			ID     int               `json:"id"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&request)
+		assert.NoError(t, err)
+		methods = append(methods, request.Method)
+
+		if request.Method != "auth.login" {
+			_, err = r.Cookie("_session_id")
+			assert.NoError(t, err)
+		}
+
+		var result string
+		switch request.Method {
+		case "auth.login":
+			http.SetCookie(w, &http.Cookie{Name: "_session_id", Value: "session"})
+			result = `true`
+		case "web.connected":
+			result = `false`
+		case "web.get_hosts":
+			result = `[["host-id","127.0.0.1",58846,"Online"]]`
+		case "web.connect":
+			assert.JSONEq(t, `"host-id"`, string(request.Params[0]))
+			result = `null`
+		case "core.set_config":
+			assert.JSONEq(t, `{"listen_ports":[1000,1000],"random_port":false}`,
+				string(request.Params[0]))
+			result = `null`
+		}
+		_, _ = w.Write([]byte(`{"result":` + result + `,"error":null,"id":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := newDeluge(server.Client(), settings.PortForwardingClient{
+		URL:      server.URL,
+		Password: "pass",
+	})
+
+	err := client.notify(context.Background(), EventUp, Data{Ports: []uint16{1000}})
+	require.NoError(t, err)
+	expectedMethods := []string{"auth.login", "web.connected", "web.get_hosts",
+		"web.connect", "core.set_config"}
+	assert.Equal(t, expectedMethods, methods)
+}
@@ -0,0 +1,180 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// deluge sets the listening port of a Deluge client
+// using its web UI JSON-RPC API.
+type deluge struct {
+	client   *http.Client
+	jsonURL  string
+	password string
+}
+
+func newDeluge(client *http.Client, settings settings.PortForwardingClient) *deluge {
+	return &deluge{
+		client:   client,
+		jsonURL:  strings.TrimSuffix(settings.URL, "/") + "/json",
+		password: settings.Password,
+	}
+}
+
+func (d *deluge) String() string {
+	return "Deluge"
+}
+
+var (
+	ErrDelugeLoginFailed = errors.New("deluge login failed")
+	ErrDelugeNoHost      = errors.New("deluge web UI has no daemon host")
+)
+
+func (d *deluge) notify(ctx context.Context, event Event, data Data) (err error) {
+	if event == EventDown || len(data.Ports) == 0 {
+		return nil
+	}
+
+	rpc := &delugeRPC{
+		client:  d.client,
+		jsonURL: d.jsonURL,
+	}
+
+	var loggedIn bool
+	err = rpc.call(ctx, "auth.login", []any{d.password}, &loggedIn)
+	if err != nil {
+		return fmt.Errorf("logging in: %w", err)
+	} else if !loggedIn {
+		return fmt.Errorf("%w", ErrDelugeLoginFailed)
+	}
+
+	err = rpc.ensureConnected(ctx)
+	if err != nil {
+		return fmt.Errorf("connecting web UI to daemon: %w", err)
+	}
+
+	port := data.Ports[0]
+	config := map[string]any{
+		"listen_ports": []uint16{port, port},
+		"random_port":  false,
+	}
+	err = rpc.call(ctx, "core.set_config", []any{config}, nil)
+	if err != nil {
+		return fmt.Errorf("setting listening port: %w", err)
+	}
+	return nil
+}
+
+type delugeRPC struct {
+	client  *http.Client
+	jsonURL string
+	cookies []*http.Cookie
+	id      int
+}
+
+// ensureConnected connects the web UI to the first daemon
+// host configured, if it is not already connected.
+func (r *delugeRPC) ensureConnected(ctx context.Context) (err error) {
+	var connected bool
+	err = r.call(ctx, "web.connected", []any{}, &connected)
+	if err != nil {
+		return fmt.Errorf("checking connection: %w", err)
+	} else if connected {
+		return nil
+	}
+
+	// Each host is an array of [id, host, port, status]
+	var hosts [][]any
+	err = r.call(ctx, "web.get_hosts", []any{}, &hosts)
+	if err != nil {
+		return fmt.Errorf("getting hosts: %w", err)
+	} else if len(hosts) == 0 || len(hosts[0]) == 0 {
+		return fmt.Errorf("%w", ErrDelugeNoHost)
+	}
+
+	err = r.call(ctx, "web.connect", []any{hosts[0][0]}, nil)
+	if err != nil {
+		return fmt.Errorf("connecting to host: %w", err)
+	}
+	return nil
+}
+
+var ErrDelugeRPC = errors.New("deluge RPC error")
+
+// call calls the method with the parameters given and decodes
+// the result into the result argument if it is not nil.
+func (r *delugeRPC) call(ctx context.Context, method string,
+	params []any, result any,
+) (err error) {
+	r.id++
+	requestBody, err := json.Marshal(struct {
+		Method string `json:"method"`
+		Params []any  `json:"params"`
+		ID     int    `json:"id"`
+	}{Method: method, Params: params, ID: r.id})
+	if err != nil {
+		return fmt.Errorf("encoding request body: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, r.jsonURL,
+		bytes.NewReader(requestBody))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	for _, cookie := range r.cookies {
+		request.AddCookie(cookie)
+	}
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return checkResponse(response)
+	}
+
+	if cookies := response.Cookies(); len(cookies) > 0 {
+		r.cookies = cookies
+	}
+
+	var responseBody struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(&responseBody)
+	if err != nil {
+		_ = response.Body.Close()
+		return fmt.Errorf("decoding response body: %w", err)
+	}
+
+	err = checkResponse(response)
+	if err != nil {
+		return err
+	}
+
+	if responseBody.Error != nil {
+		return fmt.Errorf("%w: %s", ErrDelugeRPC, responseBody.Error.Message)
+	}
+
+	if result == nil {
+		return nil
+	}
+	err = json.Unmarshal(responseBody.Result, result)
+	if err != nil {
+		return fmt.Errorf("decoding result: %w", err)
+	}
+	return nil
+}
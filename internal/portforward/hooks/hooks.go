@@ -0,0 +1,186 @@
+// Package hooks notifies other programs of the forwarded ports,
+// using HTTP webhooks and built-in BitTorrent client integrations.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+type Hooks struct {
+	// State
+	lastPortsMutex sync.Mutex
+	lastPorts      []uint16
+	// Fixed parameters
+	consumers []consumer
+	retries   uint
+	backoff   time.Duration
+	// Fixed injected objects
+	logger Logger
+}
+
+// consumer is a program to notify of forwarded ports changes.
+type consumer interface {
+	String() string
+	notify(ctx context.Context, event Event, data Data) (err error)
+}
+
+// New creates hooks for the webhooks and integrations configured
+// in the settings given. It returns nil if none are configured.
+func New(settings settings.PortForwardingHooks, client *http.Client,
+	logger Logger,
+) *Hooks {
+	consumers := make([]consumer, 0, len(settings.WebhookURLs))
+	for _, url := range settings.WebhookURLs {
+		consumers = append(consumers, newWebhook(client, url, *settings.WebhookMethod,
+			settings.WebhookHeaders, *settings.WebhookBody))
+	}
+	if settings.QBittorrent.URL != "" {
+		consumers = append(consumers, newQBittorrent(client, settings.QBittorrent))
+	}
+	if settings.Transmission.URL != "" {
+		consumers = append(consumers, newTransmission(client, settings.Transmission))
+	}
+	if settings.Deluge.URL != "" {
+		consumers = append(consumers, newDeluge(client, settings.Deluge))
+	}
+
+	if len(consumers) == 0 {
+		return nil
+	}
+
+	const backoff = time.Second
+	return &Hooks{
+		consumers: consumers,
+		retries:   *settings.Retries,
+		backoff:   backoff,
+		logger:    logger,
+	}
+}
+
+// Up notifies all consumers the ports given are forwarded.
+// The event is EventChange if ports were previously forwarded
+// and differ from the ports given, and EventUp otherwise.
+// It is a no-op if the receiver is nil.
+func (h *Hooks) Up(ctx context.Context, data Data) {
+	if h == nil {
+		return
+	}
+
+	h.lastPortsMutex.Lock()
+	event := EventUp
+	if len(h.lastPorts) > 0 && !slices.Equal(h.lastPorts, data.Ports) {
+		event = EventChange
+	}
+	h.lastPorts = slices.Clone(data.Ports)
+	h.lastPortsMutex.Unlock()
+
+	h.notify(ctx, event, data)
+}
+
+// Down notifies all consumers the ports given are no longer forwarded.
+// It is a no-op if the receiver is nil.
+func (h *Hooks) Down(ctx context.Context, data Data) {
+	if h == nil {
+		return
+	}
+	h.notify(ctx, EventDown, data)
+}
+
+// notify notifies all consumers in parallel, retrying each failed
+// consumer with an exponential backoff, and returns once all
+// consumers are notified or have failed.
+func (h *Hooks) notify(ctx context.Context, event Event, data Data) {
+	wg := &sync.WaitGroup{}
+	wg.Add(len(h.consumers))
+	for _, consumer := range h.consumers {
+		go func() {
+			defer wg.Done()
+			err := h.notifyWithRetries(ctx, consumer, event, data)
+			if err != nil {
+				h.logger.Error(fmt.Sprintf("notifying %s of ports %s: %s", consumer, event, err))
+				return
+			}
+			h.logger.Debug(fmt.Sprintf("%s notified of ports %s", consumer, event))
+		}()
+	}
+	wg.Wait()
+}
+
+func (h *Hooks) notifyWithRetries(ctx context.Context, consumer consumer,
+	event Event, data Data,
+) (err error) {
+	backoff := h.backoff
+	for attempt := uint(0); ; attempt++ {
+		const attemptTimeout = 10 * time.Second
+		attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+		err = consumer.notify(attemptCtx, event, data)
+		cancel()
+		switch {
+		case err == nil:
+			return nil
+		case attempt == h.retries:
+			return fmt.Errorf("after %d attempts: %w", attempt+1, err)
+		}
+
+		h.logger.Debug(fmt.Sprintf("notifying %s failed: %s; retrying in %s",
+			consumer, err, backoff))
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// Event is the kind of change of the forwarded ports.
+type Event string
+
+const (
+	// EventUp is used when ports are forwarded.
+	EventUp Event = "up"
+	// EventDown is used when ports are no longer forwarded.
+	EventDown Event = "down"
+	// EventChange is used when ports are forwarded and differ
+	// from the ports previously forwarded.
+	EventChange Event = "change"
+)
+
+// Data contains the information consumers are notified with.
+type Data struct {
+	Ports      []uint16
+	Interface  string
+	ServerName string
+}
+
+// render replaces the placeholders in the template given
+// with the event and data values.
+func render(template string, event Event, data Data) (rendered string) {
+	portStrings := make([]string, len(data.Ports))
+	for i, port := range data.Ports {
+		portStrings[i] = fmt.Sprint(int(port))
+	}
+	firstPort := ""
+	if len(portStrings) > 0 {
+		firstPort = portStrings[0]
+	}
+
+	replacer := strings.NewReplacer(
+		"{{EVENT}}", string(event),
+		"{{PORTS}}", strings.Join(portStrings, ","),
+		"{{PORT}}", firstPort,
+		"{{VPN_INTERFACE}}", data.Interface,
+		"{{VPN_SERVER}}", data.ServerName,
+	)
+	return replacer.Replace(template)
+}
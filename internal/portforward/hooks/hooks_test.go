@@ -0,0 +1,122 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string) {}
+func (noopLogger) Error(string) {}
+
+type fakeConsumer struct {
+	mutex    sync.Mutex
+	events   []Event
+	failures int
+}
+
+func (f *fakeConsumer) String() string { return "fake consumer" }
+
+var errTest = errors.New("test error")
+
+func (f *fakeConsumer) notify(_ context.Context, event Event, _ Data) (err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errTest
+	}
+	f.events = append(f.events, event)
+	return nil
+}
+
+func Test_Hooks(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeConsumer{failures: 2}
+	hooks := &Hooks{
+		consumers: []consumer{fake},
+		retries:   2,
+		backoff:   time.Millisecond,
+		logger:    noopLogger{},
+	}
+
+	ctx := context.Background()
+	hooks.Up(ctx, Data{Ports: []uint16{1000}})
+	hooks.Down(ctx, Data{Ports: []uint16{1000}})
+	hooks.Up(ctx, Data{Ports: []uint16{1000}})
+	hooks.Down(ctx, Data{Ports: []uint16{1000}})
+	hooks.Up(ctx, Data{Ports: []uint16{2000}})
+
+	expectedEvents := []Event{EventUp, EventDown, EventUp, EventDown, EventChange}
+	assert.Equal(t, expectedEvents, fake.events)
+
+	// Retries exhausted
+	fake.failures = 3
+	hooks.Down(ctx, Data{Ports: []uint16{2000}})
+	assert.Equal(t, expectedEvents, fake.events)
+
+	var nilHooks *Hooks
+	nilHooks.Up(ctx, Data{})
+	nilHooks.Down(ctx, Data{})
+}
+
+func Test_render(t *testing.T) {
+	t.Parallel()
+
+	data := Data{
+		Ports:      []uint16{1000, 2000},
+		Interface:  "tun0",
+		ServerName: "server",
+	}
+
+	const template = `{"event":"{{EVENT}}","ports":[{{PORTS}}],"port":{{PORT}},` +
+		`"interface":"{{VPN_INTERFACE}}","server":"{{VPN_SERVER}}"}`
+
+	rendered := render(template, EventChange, data)
+
+	const expected = `{"event":"change","ports":[1000,2000],"port":1000,` +
+		`"interface":"tun0","server":"server"}`
+	assert.Equal(t, expected, rendered)
+}
+
+func Test_webhook_notify(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/hook", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, `{"event":"up","port":1000}`, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	webhook := newWebhook(server.Client(), server.URL+"/hook", http.MethodPut,
+		[]string{"Authorization: Bearer token"}, `{"event":"{{EVENT}}","port":{{PORT}}}`)
+
+	err := webhook.notify(context.Background(), EventUp, Data{Ports: []uint16{1000}})
+	require.NoError(t, err)
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(failingServer.Close)
+	webhook.url = failingServer.URL
+	err = webhook.notify(context.Background(), EventUp, Data{Ports: []uint16{1000}})
+	assert.ErrorIs(t, err, ErrHTTPStatusCodeNotOK)
+	assert.EqualError(t, err, "HTTP status code is not OK: 502 Bad Gateway")
+}
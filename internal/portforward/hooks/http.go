@@ -0,0 +1,27 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var ErrHTTPStatusCodeNotOK = errors.New("HTTP status code is not OK")
+
+// checkResponse drains and closes the response body, and returns
+// an error if the response status code is not a 2xx code.
+func checkResponse(response *http.Response) (err error) {
+	_, _ = io.Copy(io.Discard, response.Body)
+	err = response.Body.Close()
+	if err != nil {
+		return fmt.Errorf("closing response body: %w", err)
+	}
+
+	if response.StatusCode < http.StatusOK ||
+		response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d %s", ErrHTTPStatusCodeNotOK,
+			response.StatusCode, http.StatusText(response.StatusCode))
+	}
+	return nil
+}
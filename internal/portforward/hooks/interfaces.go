@@ -0,0 +1,6 @@
+package hooks
+
+type Logger interface {
+	Debug(s string)
+	Error(s string)
+}
@@ -0,0 +1,122 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// qBittorrent sets the listening port of a qBittorrent client
+// using its web API v2.
+type qBittorrent struct {
+	client   *http.Client
+	baseURL  string
+	username string
+	password string
+}
+
+func newQBittorrent(client *http.Client, settings settings.PortForwardingClient) *qBittorrent {
+	return &qBittorrent{
+		client:   client,
+		baseURL:  strings.TrimSuffix(settings.URL, "/"),
+		username: settings.Username,
+		password: settings.Password,
+	}
+}
+
+func (q *qBittorrent) String() string {
+	return "qBittorrent"
+}
+
+func (q *qBittorrent) notify(ctx context.Context, event Event, data Data) (err error) {
+	if event == EventDown || len(data.Ports) == 0 {
+		return nil
+	}
+
+	cookies, err := q.login(ctx)
+	if err != nil {
+		return fmt.Errorf("logging in: %w", err)
+	}
+
+	preferences := fmt.Sprintf(`{"listen_port":%d}`, data.Ports[0])
+	form := url.Values{"json": {preferences}}
+	err = q.postForm(ctx, "/api/v2/app/setPreferences", form, cookies, nil)
+	if err != nil {
+		return fmt.Errorf("setting listening port: %w", err)
+	}
+	return nil
+}
+
+var ErrQBittorrentLoginFailed = errors.New("qBittorrent login failed")
+
+// login logs in and returns the session cookies to use for
+// subsequent requests. If no username is set, it assumes
+// authentication is disabled for gluetun's address, and
+// returns no cookie.
+func (q *qBittorrent) login(ctx context.Context) (cookies []*http.Cookie, err error) {
+	if q.username == "" {
+		return nil, nil
+	}
+
+	form := url.Values{
+		"username": {q.username},
+		"password": {q.password},
+	}
+	body := ""
+	err = q.postForm(ctx, "/api/v2/auth/login", form, nil, func(response *http.Response) error {
+		b, err := io.ReadAll(response.Body)
+		if err != nil {
+			return fmt.Errorf("reading response body: %w", err)
+		}
+		body = strings.TrimSpace(string(b))
+		cookies = response.Cookies()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// qBittorrent replies with 200 and "Fails." on wrong credentials.
+	if body != "Ok." {
+		return nil, fmt.Errorf("%w: %s", ErrQBittorrentLoginFailed, body)
+	}
+	return cookies, nil
+}
+
+func (q *qBittorrent) postForm(ctx context.Context, path string, form url.Values,
+	cookies []*http.Cookie, handleResponse func(response *http.Response) error,
+) (err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, q.baseURL+path,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// qBittorrent rejects requests with a Referer or Origin
+	// not matching its host, so set it to its own base URL.
+	request.Header.Set("Referer", q.baseURL)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+
+	response, err := q.client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	if handleResponse != nil && response.StatusCode == http.StatusOK {
+		err = handleResponse(response)
+		if err != nil {
+			_ = response.Body.Close()
+			return err
+		}
+	}
+
+	return checkResponse(response)
+}
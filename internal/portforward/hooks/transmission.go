@@ -0,0 +1,132 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+)
+
+// transmission sets the peer port of a Transmission client
+// using its RPC API.
+type transmission struct {
+	client   *http.Client
+	rpcURL   string
+	username string
+	password string
+}
+
+func newTransmission(client *http.Client, settings settings.PortForwardingClient) *transmission {
+	rpcURL := settings.URL
+	parsed, err := url.Parse(rpcURL)
+	if err == nil && strings.Trim(parsed.Path, "/") == "" {
+		// Default RPC path if only the scheme and host are given.
+		parsed.Path = "/transmission/rpc"
+		rpcURL = parsed.String()
+	}
+
+	return &transmission{
+		client:   client,
+		rpcURL:   rpcURL,
+		username: settings.Username,
+		password: settings.Password,
+	}
+}
+
+func (t *transmission) String() string {
+	return "Transmission"
+}
+
+var ErrTransmissionResultNotSuccess = errors.New("transmission result is not success")
+
+func (t *transmission) notify(ctx context.Context, event Event, data Data) (err error) {
+	if event == EventDown || len(data.Ports) == 0 {
+		return nil
+	}
+
+	type sessionSetArguments struct {
+		PeerPort uint16 `json:"peer-port"`
+	}
+	requestBody, err := json.Marshal(struct {
+		Method    string              `json:"method"`
+		Arguments sessionSetArguments `json:"arguments"`
+	}{
+		Method:    "session-set",
+		Arguments: sessionSetArguments{PeerPort: data.Ports[0]},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding request body: %w", err)
+	}
+
+	// Transmission replies with a 409 and the session id header to use
+	// for the first request, so the request is sent at most twice.
+	sessionID := ""
+	const maxTries = 2
+	for range maxTries {
+		var response *http.Response
+		response, err = t.post(ctx, requestBody, sessionID)
+		if err != nil {
+			return err
+		}
+
+		if response.StatusCode == http.StatusConflict {
+			sessionID = response.Header.Get("X-Transmission-Session-Id")
+			err = checkResponse(response)
+			continue
+		}
+
+		return decodeTransmissionResponse(response)
+	}
+	return err
+}
+
+func (t *transmission) post(ctx context.Context, requestBody []byte,
+	sessionID string,
+) (response *http.Response, err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, t.rpcURL,
+		bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		request.Header.Set("X-Transmission-Session-Id", sessionID)
+	}
+	if t.username != "" {
+		request.SetBasicAuth(t.username, t.password)
+	}
+
+	return t.client.Do(request)
+}
+
+func decodeTransmissionResponse(response *http.Response) (err error) {
+	if response.StatusCode != http.StatusOK {
+		return checkResponse(response)
+	}
+
+	var responseBody struct {
+		Result string `json:"result"`
+	}
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(&responseBody)
+	if err != nil {
+		_ = response.Body.Close()
+		return fmt.Errorf("decoding response body: %w", err)
+	}
+
+	err = checkResponse(response)
+	if err != nil {
+		return err
+	}
+
+	if responseBody.Result != "success" {
+		return fmt.Errorf("%w: %s", ErrTransmissionResultNotSuccess, responseBody.Result)
+	}
+	return nil
+}
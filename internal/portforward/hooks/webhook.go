@@ -0,0 +1,68 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type webhook struct {
+	client       *http.Client
+	url          string
+	method       string
+	headers      http.Header
+	bodyTemplate string
+}
+
+func newWebhook(client *http.Client, url, method string,
+	headers []string, bodyTemplate string,
+) *webhook {
+	httpHeaders := make(http.Header, len(headers))
+	for _, header := range headers {
+		name, value, _ := strings.Cut(header, ":")
+		httpHeaders.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	return &webhook{
+		client:       client,
+		url:          url,
+		method:       method,
+		headers:      httpHeaders,
+		bodyTemplate: bodyTemplate,
+	}
+}
+
+// String returns the webhook description without its URL
+// path and query, since these may contain secrets.
+func (w *webhook) String() string {
+	parsed, err := url.Parse(w.url)
+	if err != nil {
+		return "webhook"
+	}
+	return "webhook " + parsed.Scheme + "://" + parsed.Host
+}
+
+func (w *webhook) notify(ctx context.Context, event Event, data Data) (err error) {
+	body := ""
+	if w.method != http.MethodGet {
+		body = render(w.bodyTemplate, event, data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, w.method, w.url, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	request.Header = w.headers.Clone()
+	if body != "" && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	return checkResponse(response)
+}
@@ -35,3 +35,7 @@ type Cmder interface {
 	Start(cmd *exec.Cmd) (stdoutLines, stderrLines <-chan string,
 		waitError <-chan error, startErr error)
 }
+
+type EventPublisher interface {
+	Publish(eventType string, data any)
+}
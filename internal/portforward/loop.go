@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/portforward/hooks"
 	"github.com/qdm12/gluetun/internal/portforward/service"
 )
 
@@ -21,6 +22,8 @@ type Loop struct {
 	portAllower PortAllower
 	logger      Logger
 	cmder       Cmder
+	events      EventPublisher
+	hooks       *hooks.Hooks
 	// Fixed parameters
 	uid, gid int
 	// Internal channels and locks
@@ -35,7 +38,7 @@ type Loop struct {
 
 func NewLoop(settings settings.PortForwarding, routing Routing,
 	client *http.Client, portAllower PortAllower,
-	logger Logger, cmder Cmder, uid, gid int,
+	logger Logger, cmder Cmder, eventPublisher EventPublisher, uid, gid int,
 ) *Loop {
 	return &Loop{
 		settings: Settings{
@@ -53,6 +56,8 @@ func NewLoop(settings settings.PortForwarding, routing Routing,
 		portAllower: portAllower,
 		logger:      logger,
 		cmder:       cmder,
+		events:      eventPublisher,
+		hooks:       hooks.New(settings.Hooks, client, logger),
 		uid:         uid,
 		gid:         gid,
 	}
@@ -119,7 +124,7 @@ func (l *Loop) run(runCtx context.Context, runDone chan<- struct{},
 		*serviceSettings.Enabled = *serviceSettings.Enabled && *l.settings.VPNIsUp
 
 		l.service = service.New(serviceSettings, l.routing, l.client,
-			l.portAllower, l.logger, l.cmder, l.uid, l.gid)
+			l.portAllower, l.logger, l.cmder, l.events, l.hooks, l.uid, l.gid)
 
 		var err error
 		serviceRunError, err = l.service.Start(runCtx)
@@ -3,6 +3,8 @@ package service
 import (
 	"fmt"
 	"strings"
+
+	"github.com/qdm12/gluetun/internal/portforward/hooks"
 )
 
 func portsToString(ports []uint16) (s string) {
@@ -20,3 +22,11 @@ func portsToString(ports []uint16) (s string) {
 			" and " + portStrings[len(portStrings)-1]
 	}
 }
+
+func (s *Service) hooksData(ports []uint16) hooks.Data {
+	return hooks.Data{
+		Ports:      ports,
+		Interface:  s.settings.Interface,
+		ServerName: s.settings.ServerName,
+	}
+}
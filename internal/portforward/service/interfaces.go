@@ -5,6 +5,7 @@ import (
 	"net/netip"
 	"os/exec"
 
+	"github.com/qdm12/gluetun/internal/portforward/hooks"
 	"github.com/qdm12/gluetun/internal/provider/utils"
 )
 
@@ -38,3 +39,12 @@ type Cmder interface {
 	Start(cmd *exec.Cmd) (stdoutLines, stderrLines <-chan string,
 		waitError <-chan error, startErr error)
 }
+
+type EventPublisher interface {
+	Publish(eventType string, data any)
+}
+
+type Hooks interface {
+	Up(ctx context.Context, data hooks.Data)
+	Down(ctx context.Context, data hooks.Data)
+}
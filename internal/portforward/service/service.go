@@ -20,6 +20,8 @@ type Service struct {
 	portAllower PortAllower
 	logger      Logger
 	cmder       Cmder
+	events      EventPublisher
+	hooks       Hooks
 	// Internal channels and locks
 	startStopMutex sync.Mutex
 	keepPortCancel context.CancelFunc
@@ -27,7 +29,8 @@ type Service struct {
 }
 
 func New(settings Settings, routing Routing, client *http.Client,
-	portAllower PortAllower, logger Logger, cmder Cmder, puid, pgid int,
+	portAllower PortAllower, logger Logger, cmder Cmder,
+	eventPublisher EventPublisher, hooks Hooks, puid, pgid int,
 ) *Service {
 	return &Service{
 		// Fixed parameters
@@ -40,6 +43,8 @@ func New(settings Settings, routing Routing, client *http.Client,
 		portAllower: portAllower,
 		logger:      logger,
 		cmder:       cmder,
+		events:      eventPublisher,
+		hooks:       hooks,
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/qdm12/gluetun/internal/events"
 	"github.com/qdm12/gluetun/internal/netlink"
 	"github.com/qdm12/gluetun/internal/provider/utils"
 )
@@ -72,6 +73,7 @@ func (s *Service) Start(ctx context.Context) (runError <-chan error, err error)
 	s.portMutex.Lock()
 	s.ports = ports
 	s.portMutex.Unlock()
+	s.events.Publish(events.TypePortForwarded, events.PortForwarded{Ports: ports})
 
 	if s.settings.UpCommand != "" {
 		err = runCommand(ctx, s.cmder, s.logger, s.settings.UpCommand, ports, s.settings.Interface)
@@ -81,6 +83,8 @@ func (s *Service) Start(ctx context.Context) (runError <-chan error, err error)
 		}
 	}
 
+	s.hooks.Up(ctx, s.hooksData(ports))
+
 	keepPortCtx, keepPortCancel := context.WithCancel(context.Background())
 	s.keepPortCancel = keepPortCancel
 	runErrorCh := make(chan error)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/qdm12/gluetun/internal/events"
 )
 
 func (s *Service) Stop() (err error) {
@@ -41,6 +43,13 @@ func (s *Service) cleanup() (err error) {
 		}
 	}
 
+	if len(s.ports) > 0 {
+		const hooksTimeout = 60 * time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), hooksTimeout)
+		s.hooks.Down(ctx, s.hooksData(s.ports))
+		cancel()
+	}
+
 	for _, port := range s.ports {
 		err = s.portAllower.RemoveAllowedPort(context.Background(), port)
 		if err != nil {
@@ -57,6 +66,9 @@ func (s *Service) cleanup() (err error) {
 		}
 	}
 
+	if len(s.ports) > 0 {
+		s.events.Publish(events.TypePortForwarded, events.PortForwarded{Ports: []uint16{}})
+	}
 	s.ports = nil
 
 	err = s.writePortForwardedFile(nil)
@@ -0,0 +1,346 @@
+package custom
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/configuration/sources/files"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+)
+
+// Profile is a VPN configuration file of the custom
+// configuration directory, used as a pseudo server.
+type Profile struct {
+	// Name is the profile name, which is the first underscore
+	// separated field of the file name without its extension,
+	// or the value of a '# name:' comment in the file.
+	Name string
+	// Country is the second underscore separated field of the
+	// file name, or the value of a '# country:' comment in the file.
+	Country string
+	// City is the third underscore separated field of the
+	// file name, or the value of a '# city:' comment in the file.
+	City string
+	// VPN is the VPN type, which is 'openvpn' for .ovpn files
+	// and 'wireguard' for wg*.conf files.
+	VPN string
+	// Filepath is the path to the configuration file.
+	Filepath string
+	// Wireguard contains the Wireguard settings parsed
+	// from the file, and is only set for Wireguard profiles.
+	Wireguard WireguardProfile
+}
+
+// WireguardProfile contains the Wireguard settings of a profile.
+// Fields left empty in the file are taken from the settings.
+type WireguardProfile struct {
+	PrivateKey   string
+	PreSharedKey string
+	Addresses    []netip.Prefix
+	PublicKey    string
+	EndpointIP   netip.Addr
+	EndpointPort uint16
+}
+
+var (
+	ErrProfileNameDuplicate = errors.New("profile name is used by more than one file")
+	ErrNoProfileFound       = errors.New("no profile found")
+	ErrNoProfileMatch       = errors.New("no profile matches the server selection")
+)
+
+// ReadProfiles reads the OpenVPN .ovpn and the Wireguard wg*.conf
+// files of the directory given, sorted by file name.
+func ReadProfiles(dirPath string, extractor Extractor) (profiles []Profile, err error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+
+	type nameKey struct {
+		vpn  string
+		name string
+	}
+	nameKeyToPath := make(map[nameKey]string, len(entries))
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var profile Profile
+		switch {
+		case entry.IsDir():
+			continue
+		case filepath.Ext(fileName) == ".ovpn":
+			profile.VPN = vpn.OpenVPN
+		case strings.HasPrefix(fileName, "wg") && filepath.Ext(fileName) == ".conf":
+			profile.VPN = vpn.Wireguard
+		default:
+			continue
+		}
+		profile.Filepath = filepath.Join(dirPath, fileName)
+
+		profile.Name, profile.Country, profile.City = parseProfileFileName(fileName)
+		err = parseProfileComments(profile.Filepath, &profile)
+		if err != nil {
+			return nil, fmt.Errorf("reading comments of %s: %w", fileName, err)
+		}
+
+		if profile.VPN == vpn.OpenVPN {
+			_, _, err = extractor.Data(profile.Filepath)
+			if err != nil {
+				return nil, fmt.Errorf("extracting data from %s: %w", fileName, err)
+			}
+		} else {
+			profile.Wireguard, err = parseWireguardProfile(profile.Filepath)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", fileName, err)
+			}
+		}
+
+		key := nameKey{vpn: profile.VPN, name: strings.ToLower(profile.Name)}
+		existingPath, exists := nameKeyToPath[key]
+		if exists {
+			return nil, fmt.Errorf("%w: %s in %s and %s", ErrProfileNameDuplicate,
+				profile.Name, existingPath, profile.Filepath)
+		}
+		nameKeyToPath[key] = profile.Filepath
+
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
+
+// parseProfileFileName parses file names in the form
+// name_country_city.ext, where country and city are optional
+// and dashes in the country and city are replaced with spaces.
+func parseProfileFileName(fileName string) (name, country, city string) {
+	fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	const maxFields = 3
+	fields := strings.SplitN(fileName, "_", maxFields)
+	name = fields[0]
+	if len(fields) > 1 {
+		country = strings.ReplaceAll(fields[1], "-", " ")
+	}
+	if len(fields) > 2 { //nolint:mnd
+		city = strings.ReplaceAll(fields[2], "-", " ")
+	}
+	return name, country, city
+}
+
+// parseProfileComments sets the profile name, country and city
+// from comments such as '# country: Germany', which take precedence
+// over the values parsed from the file name.
+func parseProfileComments(path string, profile *Profile) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		comment, ok := strings.CutPrefix(line, "#")
+		if !ok {
+			comment, ok = strings.CutPrefix(line, ";")
+			if !ok {
+				continue
+			}
+		}
+		key, value, ok := strings.Cut(comment, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "name":
+			profile.Name = value
+		case "country":
+			profile.Country = value
+		case "city":
+			profile.City = value
+		}
+	}
+	return scanner.Err()
+}
+
+var ErrEndpointIPNotValid = errors.New("endpoint IP address is not valid")
+
+func parseWireguardProfile(path string) (profile WireguardProfile, err error) {
+	config, err := files.ParseWireguardConf(path)
+	if err != nil {
+		return profile, err
+	}
+
+	if config.PrivateKey != nil {
+		profile.PrivateKey = *config.PrivateKey
+	}
+	if config.PreSharedKey != nil {
+		profile.PreSharedKey = *config.PreSharedKey
+	}
+	if config.PublicKey != nil {
+		profile.PublicKey = *config.PublicKey
+	}
+
+	if config.Addresses != nil {
+		for address := range strings.SplitSeq(*config.Addresses, ",") {
+			prefix, err := netip.ParsePrefix(strings.TrimSpace(address))
+			if err != nil {
+				return profile, fmt.Errorf("parsing interface address: %w", err)
+			}
+			profile.Addresses = append(profile.Addresses, prefix)
+		}
+	}
+
+	if config.EndpointIP != nil {
+		profile.EndpointIP, err = netip.ParseAddr(*config.EndpointIP)
+		if err != nil {
+			return profile, fmt.Errorf("%w: %w", ErrEndpointIPNotValid, err)
+		}
+	}
+
+	if config.EndpointPort != nil {
+		const base, bitSize = 10, 16
+		port, err := strconv.ParseUint(*config.EndpointPort, base, bitSize)
+		if err != nil {
+			return profile, fmt.Errorf("parsing endpoint port: %w", err)
+		}
+		profile.EndpointPort = uint16(port)
+	}
+
+	return profile, nil
+}
+
+// filterProfiles returns the profiles matching the VPN type, names,
+// countries and cities of the server selection, case insensitively.
+func filterProfiles(profiles []Profile, selection settings.ServerSelection) (filtered []Profile) {
+	for _, profile := range profiles {
+		if profile.VPN != selection.VPN ||
+			filterByPossibilities(profile.Name, selection.Names) ||
+			filterByPossibilities(profile.Country, selection.Countries) ||
+			filterByPossibilities(profile.City, selection.Cities) {
+			continue
+		}
+		filtered = append(filtered, profile)
+	}
+	return filtered
+}
+
+func filterByPossibilities(value string, possibilities []string) (filtered bool) {
+	if len(possibilities) == 0 {
+		return false
+	}
+	return !slices.ContainsFunc(possibilities, func(possibility string) bool {
+		return strings.EqualFold(value, possibility)
+	})
+}
+
+// SelectProfile reads the profiles of the custom configuration directory
+// and returns the settings given modified to use a random profile matching
+// the server selection. The previously selected profile is only picked again
+// if it is the only one matching, so each reconnection rotates the profile.
+func (p *Provider) SelectProfile(vpnSettings settings.VPN) (
+	selected settings.VPN, profile Profile, err error,
+) {
+	selection := vpnSettings.Provider.ServerSelection
+	profiles, err := ReadProfiles(*selection.CustomConfigDir, p.extractor)
+	if err != nil {
+		return selected, profile, fmt.Errorf("reading profiles: %w", err)
+	} else if len(profiles) == 0 {
+		return selected, profile, fmt.Errorf("%w in %s",
+			ErrNoProfileFound, *selection.CustomConfigDir)
+	}
+
+	profiles = filterProfiles(profiles, selection)
+	if len(profiles) == 0 {
+		return selected, profile, fmt.Errorf("%w: for VPN type %s, names [%s], countries [%s] and cities [%s]",
+			ErrNoProfileMatch, selection.VPN, strings.Join(selection.Names, ", "),
+			strings.Join(selection.Countries, ", "), strings.Join(selection.Cities, ", "))
+	}
+
+	p.lastProfileMutex.Lock()
+	defer p.lastProfileMutex.Unlock()
+	if len(profiles) > 1 {
+		profiles = slices.DeleteFunc(profiles, func(profile Profile) bool {
+			return profile.Filepath == p.lastProfilePath
+		})
+	}
+	profile = profiles[rand.IntN(len(profiles))] //nolint:gosec
+	p.lastProfilePath = profile.Filepath
+
+	selected = applyProfile(vpnSettings, profile)
+	if profile.VPN == vpn.Wireguard {
+		err = checkWireguardPeer(selected.Provider.ServerSelection.Wireguard)
+		if err != nil {
+			return selected, profile, fmt.Errorf("profile %s: %w", profile.Filepath, err)
+		}
+	}
+	return selected, profile, nil
+}
+
+var (
+	ErrWireguardEndpointIPNotSet   = errors.New("Wireguard endpoint IP address is not set")
+	ErrWireguardEndpointPortNotSet = errors.New("Wireguard endpoint port is not set")
+	ErrWireguardPublicKeyNotSet    = errors.New("Wireguard public key is not set")
+)
+
+// checkWireguardPeer checks the peer settings which are otherwise checked
+// in the settings validation if no custom configuration directory is used.
+func checkWireguardPeer(selection settings.WireguardSelection) (err error) {
+	switch {
+	case !selection.EndpointIP.IsValid() || selection.EndpointIP.IsUnspecified():
+		return fmt.Errorf("%w", ErrWireguardEndpointIPNotSet)
+	case *selection.EndpointPort == 0:
+		return fmt.Errorf("%w", ErrWireguardEndpointPortNotSet)
+	case selection.PublicKey == "":
+		return fmt.Errorf("%w", ErrWireguardPublicKeyNotSet)
+	default:
+		return nil
+	}
+}
+
+// applyProfile returns the settings given modified to use the profile.
+// Note assigning new pointers and slices does not mutate the settings
+// given, which may share them with the settings state.
+func applyProfile(vpnSettings settings.VPN, profile Profile) (modified settings.VPN) {
+	modified = vpnSettings
+	// The profile name is used as server name for the connection.
+	modified.Provider.ServerSelection.Names = []string{profile.Name}
+
+	if profile.VPN == vpn.OpenVPN {
+		modified.OpenVPN.ConfFile = &profile.Filepath
+		modified.Provider.ServerSelection.OpenVPN.ConfFile = &profile.Filepath
+		return modified
+	}
+
+	wireguard := profile.Wireguard
+	if wireguard.PrivateKey != "" {
+		modified.Wireguard.PrivateKey = &wireguard.PrivateKey
+	}
+	if wireguard.PreSharedKey != "" {
+		modified.Wireguard.PreSharedKey = &wireguard.PreSharedKey
+	}
+	if len(wireguard.Addresses) > 0 {
+		modified.Wireguard.Addresses = wireguard.Addresses
+	}
+	if wireguard.PublicKey != "" {
+		modified.Provider.ServerSelection.Wireguard.PublicKey = wireguard.PublicKey
+	}
+	if wireguard.EndpointIP.IsValid() {
+		modified.Provider.ServerSelection.Wireguard.EndpointIP = wireguard.EndpointIP
+	}
+	if wireguard.EndpointPort != 0 {
+		modified.Provider.ServerSelection.Wireguard.EndpointPort = &wireguard.EndpointPort
+	}
+	return modified
+}
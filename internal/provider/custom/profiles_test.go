@@ -0,0 +1,118 @@
+package custom
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/openvpn/extract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReadProfiles(t *testing.T) {
+	t.Parallel()
+
+	dirPath := t.TempDir()
+	const permission = 0o600
+	fileNameToContent := map[string]string{
+		"node1_germany_berlin.ovpn": "client\nremote 1.2.3.4 1194 udp\n",
+		"node2.ovpn":                "# country: United States\n; city: New York\nremote 5.6.7.8 443 tcp\n",
+		"wg_united-kingdom.conf": "# name: london\n[Interface]\n" +
+			"Address = 10.0.0.2/32\n[Peer]\nEndpoint = 9.9.9.9:51820\n",
+		"readme.txt": "ignored",
+	}
+	for fileName, content := range fileNameToContent {
+		err := os.WriteFile(filepath.Join(dirPath, fileName), []byte(content), permission)
+		require.NoError(t, err)
+	}
+
+	profiles, err := ReadProfiles(dirPath, extract.New())
+	require.NoError(t, err)
+
+	expected := []Profile{
+		{
+			Name:     "node1",
+			Country:  "germany",
+			City:     "berlin",
+			VPN:      vpn.OpenVPN,
+			Filepath: filepath.Join(dirPath, "node1_germany_berlin.ovpn"),
+		},
+		{
+			Name:     "node2",
+			Country:  "United States",
+			City:     "New York",
+			VPN:      vpn.OpenVPN,
+			Filepath: filepath.Join(dirPath, "node2.ovpn"),
+		},
+		{
+			Name:     "london",
+			Country:  "united kingdom",
+			VPN:      vpn.Wireguard,
+			Filepath: filepath.Join(dirPath, "wg_united-kingdom.conf"),
+			Wireguard: WireguardProfile{
+				Addresses:    []netip.Prefix{netip.MustParsePrefix("10.0.0.2/32")},
+				EndpointIP:   netip.MustParseAddr("9.9.9.9"),
+				EndpointPort: 51820,
+			},
+		},
+	}
+	assert.Equal(t, expected, profiles)
+}
+
+func Test_filterProfiles(t *testing.T) {
+	t.Parallel()
+
+	profiles := []Profile{
+		{Name: "a", Country: "Germany", City: "Berlin", VPN: vpn.OpenVPN},
+		{Name: "b", Country: "Germany", City: "Munich", VPN: vpn.OpenVPN},
+		{Name: "c", Country: "France", VPN: vpn.OpenVPN},
+		{Name: "d", Country: "Germany", VPN: vpn.Wireguard},
+	}
+
+	testCases := map[string]struct {
+		selection settings.ServerSelection
+		names     []string
+	}{
+		"vpn_type_only": {
+			selection: settings.ServerSelection{VPN: vpn.OpenVPN},
+			names:     []string{"a", "b", "c"},
+		},
+		"countries": {
+			selection: settings.ServerSelection{VPN: vpn.OpenVPN, Countries: []string{"germany"}},
+			names:     []string{"a", "b"},
+		},
+		"countries_and_cities": {
+			selection: settings.ServerSelection{
+				VPN:       vpn.OpenVPN,
+				Countries: []string{"germany"},
+				Cities:    []string{"MUNICH"},
+			},
+			names: []string{"b"},
+		},
+		"names": {
+			selection: settings.ServerSelection{VPN: vpn.Wireguard, Names: []string{"a", "D"}},
+			names:     []string{"d"},
+		},
+		"no_match": {
+			selection: settings.ServerSelection{VPN: vpn.OpenVPN, Cities: []string{"Paris"}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filtered := filterProfiles(profiles, testCase.selection)
+
+			var names []string
+			for _, profile := range filtered {
+				names = append(names, profile.Name)
+			}
+			assert.Equal(t, testCase.names, names)
+		})
+	}
+}
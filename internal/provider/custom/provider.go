@@ -1,6 +1,8 @@
 package custom
 
 import (
+	"sync"
+
 	"github.com/qdm12/gluetun/internal/constants/providers"
 	"github.com/qdm12/gluetun/internal/provider/common"
 	"github.com/qdm12/gluetun/internal/provider/utils"
@@ -9,6 +11,10 @@ import (
 type Provider struct {
 	extractor Extractor
 	common.Fetcher
+	// lastProfilePath is the file path of the last profile
+	// selected, to rotate profiles on each reconnection.
+	lastProfilePath  string
+	lastProfileMutex sync.Mutex
 }
 
 func New(extractor Extractor) *Provider {
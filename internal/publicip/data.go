@@ -10,6 +10,12 @@ func (l *Loop) GetData() (data models.PublicIP) {
 	return l.ipData
 }
 
+// GetIPChanges returns the number of times the public IP address
+// changed since the loop was created. It is notably used for metrics.
+func (l *Loop) GetIPChanges() (changes uint64) {
+	return l.ipChanges.Load()
+}
+
 // ClearData is used when the VPN connection goes down
 // and the public IP is not known anymore.
 func (l *Loop) ClearData() (err error) {
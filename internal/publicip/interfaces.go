@@ -5,3 +5,7 @@ type Logger interface {
 	Warn(s string)
 	Error(s string)
 }
+
+type EventPublisher interface {
+	Publish(eventType string, data any)
+}
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"net/netip"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/events"
 	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/publicip/api"
 )
@@ -19,10 +21,13 @@ type Loop struct {
 	settingsMutex sync.RWMutex
 	ipData        models.PublicIP
 	ipDataMutex   sync.RWMutex
+	lastIP        netip.Addr // not cleared by ClearData
+	ipChanges     atomic.Uint64
 	fetcher       *api.ResilientFetcher
 	// Fixed injected objects
 	httpClient *http.Client
 	logger     Logger
+	events     EventPublisher
 	// Fixed parameters
 	puid int
 	pgid int
@@ -41,7 +46,7 @@ type Loop struct {
 }
 
 func NewLoop(settings settings.PublicIP, puid, pgid int,
-	httpClient *http.Client, logger Logger,
+	httpClient *http.Client, eventPublisher EventPublisher, logger Logger,
 ) (loop *Loop, err error) {
 	fetchers, err := api.New(makeNameTokenPairs(settings.APIs), httpClient)
 	if err != nil {
@@ -53,6 +58,7 @@ func NewLoop(settings settings.PublicIP, puid, pgid int,
 		httpClient: httpClient,
 		fetcher:    api.NewResilient(fetchers, logger),
 		logger:     logger,
+		events:     eventPublisher,
 		puid:       puid,
 		pgid:       pgid,
 		timeNow:    time.Now,
@@ -135,6 +141,12 @@ func (l *Loop) run(runCtx context.Context, runDone chan<- struct{},
 		l.ipData = result
 		l.ipDataMutex.Unlock()
 
+		if l.lastIP.IsValid() && l.lastIP != result.IP {
+			l.ipChanges.Add(1)
+		}
+		l.lastIP = result.IP
+		l.events.Publish(events.TypePublicIP, result)
+
 		filepath := *l.settings.IPFilepath
 		err = persistPublicIP(filepath, result.IP.String(), l.puid, l.pgid)
 		if err != nil {
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/server/middlewares/auth"
+)
+
+// authReloader polls the authentication file for changes and
+// swaps the auth middleware settings once the new file content
+// is validated. An invalid or removed file keeps the current settings.
+type authReloader struct {
+	settings   settings.ControlServer
+	middleware *auth.Middleware
+	logger     Logger
+	current    auth.Settings
+	fileState  authFileState
+}
+
+type authFileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func newAuthReloader(settings settings.ControlServer, current auth.Settings,
+	middleware *auth.Middleware, logger Logger,
+) *authReloader {
+	return &authReloader{
+		settings:   settings,
+		middleware: middleware,
+		logger:     logger,
+		current:    current,
+		fileState:  statAuthFile(settings.AuthFilePath),
+	}
+}
+
+func statAuthFile(path string) (state authFileState) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return authFileState{}
+	}
+	return authFileState{
+		exists:  true,
+		modTime: stat.ModTime(),
+		size:    stat.Size(),
+	}
+}
+
+func (r *authReloader) run(ctx context.Context) {
+	const period = 5 * time.Second
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.check()
+		}
+	}
+}
+
+func (r *authReloader) check() {
+	fileState := statAuthFile(r.settings.AuthFilePath)
+	if fileState == r.fileState {
+		return
+	}
+	r.fileState = fileState
+
+	if !fileState.exists {
+		r.logger.Warnf("authentication file %s removed, keeping current authentication settings",
+			r.settings.AuthFilePath)
+		return
+	}
+
+	newSettings, err := readAuthSettings(r.settings, r.logger)
+	if err == nil {
+		err = r.middleware.Update(newSettings)
+	}
+	if err != nil {
+		r.logger.Warnf("reloading authentication file, keeping current authentication settings: %s",
+			err)
+		return
+	}
+
+	added, removed, changed := diffRoles(r.current.Roles, newSettings.Roles)
+	r.current = newSettings
+	r.logger.Infof("authentication file reloaded: roles added [%s], removed [%s], changed [%s]",
+		strings.Join(added, ", "), strings.Join(removed, ", "), strings.Join(changed, ", "))
+}
+
+// diffRoles returns the names of roles added, removed and changed
+// between the old and new roles, in the order they are defined.
+func diffRoles(oldRoles, newRoles []auth.Role) (added, removed, changed []string) {
+	oldByName := make(map[string]auth.Role, len(oldRoles))
+	for _, role := range oldRoles {
+		oldByName[role.Name] = role
+	}
+	newByName := make(map[string]auth.Role, len(newRoles))
+	for _, role := range newRoles {
+		newByName[role.Name] = role
+		oldRole, ok := oldByName[role.Name]
+		switch {
+		case !ok:
+			added = append(added, role.Name)
+		case !reflect.DeepEqual(oldRole, role):
+			changed = append(changed, role.Name)
+		}
+	}
+	for _, role := range oldRoles {
+		if _, ok := newByName[role.Name]; !ok {
+			removed = append(removed, role.Name)
+		}
+	}
+	return added, removed, changed
+}
@@ -35,6 +35,13 @@ func (h *dnsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		default:
 			errMethodNotSupported(w, r.Method)
 		}
+	case "/stats":
+		switch r.Method {
+		case http.MethodGet:
+			h.getStats(w)
+		default:
+			errMethodNotSupported(w, r.Method)
+		}
 	default:
 		errRouteNotSupported(w, r.RequestURI)
 	}
@@ -75,3 +82,16 @@ func (h *dnsHandler) setStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// getStats writes statistics on the DNS queries answered,
+// including the 10 most queried and most blocked domain names.
+func (h *dnsHandler) getStats(w http.ResponseWriter) {
+	const top = 10
+	stats := h.loop.GetStats(top)
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(stats); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
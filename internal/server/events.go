@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/events"
+)
+
+func newEventsHandler(ctx context.Context,
+	subscriber EventSubscriber, warner warner,
+) http.Handler {
+	return &eventsHandler{
+		ctx:        ctx,
+		subscriber: subscriber,
+		warner:     warner,
+	}
+}
+
+type eventsHandler struct {
+	ctx        context.Context //nolint:containedctx
+	subscriber EventSubscriber
+	warner     warner
+}
+
+func (h *eventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.streamEvents(w, r)
+	default:
+		errMethodNotSupported(w, r.Method)
+	}
+}
+
+// streamEvents streams events as server-sent events until the client
+// disconnects or the server shuts down. The optional `types` query
+// parameter is a comma separated list of event types to receive.
+func (h *eventsHandler) streamEvents(w http.ResponseWriter, r *http.Request) {
+	var typesFilter []string
+	if typesValue := r.URL.Query().Get("types"); typesValue != "" {
+		typesFilter = strings.Split(typesValue, ",")
+		for _, eventType := range typesFilter {
+			if !slices.Contains(events.Types(), eventType) {
+				http.Error(w, fmt.Sprintf("event type %q is not valid, it must be one of: %s",
+					eventType, strings.Join(events.Types(), ", ")), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	responseController := http.NewResponseController(w)
+
+	eventsCh, unsubscribe := h.subscriber.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	err := responseController.Flush()
+	if err != nil {
+		h.warner.Warn("flushing event stream headers: " + err.Error())
+		return
+	}
+
+	// Send a comment periodically to keep the connection
+	// alive through proxies and detect disconnected clients.
+	const keepAlivePeriod = 30 * time.Second
+	ticker := time.NewTicker(keepAlivePeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		case event := <-eventsCh:
+			if typesFilter != nil && !slices.Contains(typesFilter, event.Type) {
+				continue
+			}
+			err = writeEvent(w, event)
+		}
+		if err == nil {
+			err = responseController.Flush()
+		}
+		if err != nil {
+			if r.Context().Err() == nil { // client still connected
+				h.warner.Warn("writing event stream: " + err.Error())
+			}
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) (err error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -12,13 +12,19 @@ import (
 )
 
 func newHandler(ctx context.Context, logger Logger, logging bool,
-	authSettings auth.Settings,
+	authMiddleware *auth.Middleware,
 	buildInfo models.BuildInformation,
 	vpnLooper VPNLooper,
 	pfGetter PortForwardedGetter,
 	dnsLooper DNSLoop,
 	updaterLooper UpdaterLooper,
 	publicIPLooper PublicIPLoop,
+	healthChecker HealthChecker,
+	httpProxyLooper HTTPProxyLoop,
+	shadowsocksLooper ShadowsocksLoop,
+	socks5Looper Socks5Loop,
+	eventSubscriber EventSubscriber,
+	leakTester LeakTester,
 	storage Storage,
 	ipv6Supported bool,
 ) (httpHandler http.Handler, err error) {
@@ -27,20 +33,26 @@ func newHandler(ctx context.Context, logger Logger, logging bool,
 	vpn := newVPNHandler(ctx, vpnLooper, storage, ipv6Supported, logger)
 	openvpn := newOpenvpnHandler(ctx, vpnLooper, logger)
 	dns := newDNSHandler(ctx, dnsLooper, logger)
-	updater := newUpdaterHandler(ctx, updaterLooper, logger)
+	updater := newUpdaterHandler(ctx, updaterLooper, storage, logger)
 	publicip := newPublicIPHandler(publicIPLooper, logger)
 	portForward := newPortForwardHandler(ctx, pfGetter, logger)
+	events := newEventsHandler(ctx, eventSubscriber, logger)
+	socks5 := newSocks5Handler(ctx, socks5Looper, logger)
+	servers := newServersHandler(vpnLooper, storage, logger)
+	leakTest := newLeakTestHandler(ctx, leakTester, logger)
 
 	handler.v0 = newHandlerV0(ctx, logger, vpnLooper, dnsLooper, updaterLooper)
-	handler.v1 = newHandlerV1(logger, buildInfo, vpn, openvpn, dns, updater, publicip, portForward)
-
-	authMiddleware, err := auth.New(authSettings, logger)
+	handler.v1 = newHandlerV1(logger, buildInfo, vpn, openvpn, dns, updater, publicip,
+		portForward, events, socks5, servers, leakTest)
+	handler.metrics, err = newMetricsHandler(vpnLooper, healthChecker, pfGetter,
+		publicIPLooper, dnsLooper, updaterLooper, httpProxyLooper, shadowsocksLooper,
+		socks5Looper, logger)
 	if err != nil {
-		return nil, fmt.Errorf("creating auth middleware: %w", err)
+		return nil, fmt.Errorf("creating metrics handler: %w", err)
 	}
 
 	middlewares := []func(http.Handler) http.Handler{
-		authMiddleware,
+		authMiddleware.Wrap,
 		log.New(logger, logging),
 	}
 	httpHandler = handler
@@ -51,12 +63,17 @@ func newHandler(ctx context.Context, logger Logger, logging bool,
 }
 
 type handler struct {
-	v0 http.Handler
-	v1 http.Handler
+	v0      http.Handler
+	v1      http.Handler
+	metrics http.Handler
 }
 
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	r.RequestURI = strings.TrimSuffix(r.RequestURI, "/")
+	if r.URL.Path == "/metrics" {
+		h.metrics.ServeHTTP(w, r)
+		return
+	}
 	if !strings.HasPrefix(r.RequestURI, "/v1/") && r.RequestURI != "/v1" {
 		h.v0.ServeHTTP(w, r)
 		return
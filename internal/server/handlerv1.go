@@ -10,7 +10,8 @@ import (
 )
 
 func newHandlerV1(w warner, buildInfo models.BuildInformation,
-	vpn, openvpn, dns, updater, publicip, portForward http.Handler,
+	vpn, openvpn, dns, updater, publicip, portForward, events, socks5,
+	servers, leakTest http.Handler,
 ) http.Handler {
 	return &handlerV1{
 		warner:      w,
@@ -21,6 +22,10 @@ func newHandlerV1(w warner, buildInfo models.BuildInformation,
 		updater:     updater,
 		publicip:    publicip,
 		portForward: portForward,
+		events:      events,
+		socks5:      socks5,
+		servers:     servers,
+		leakTest:    leakTest,
 	}
 }
 
@@ -33,6 +38,10 @@ type handlerV1 struct {
 	updater     http.Handler
 	publicip    http.Handler
 	portForward http.Handler
+	events      http.Handler
+	socks5      http.Handler
+	servers     http.Handler
+	leakTest    http.Handler
 }
 
 func (h *handlerV1) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -51,6 +60,14 @@ func (h *handlerV1) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.publicip.ServeHTTP(w, r)
 	case strings.HasPrefix(r.RequestURI, "/portforward"):
 		h.portForward.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/events"):
+		h.events.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/socks5"):
+		h.socks5.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/servers"):
+		h.servers.ServeHTTP(w, r)
+	case strings.HasPrefix(r.RequestURI, "/leaktest"):
+		h.leakTest.ServeHTTP(w, r)
 	default:
 		errString := fmt.Sprintf("%s %s not found", r.Method, r.RequestURI)
 		http.Error(w, errString, http.StatusBadRequest)
@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"net/netip"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/events"
 	"github.com/qdm12/gluetun/internal/models"
 )
 
@@ -13,12 +15,39 @@ type VPNLooper interface {
 		outcome string, err error)
 	GetSettings() (settings settings.VPN)
 	SetSettings(ctx context.Context, settings settings.VPN) (outcome string)
+	GetReconnects() (reconnects uint64)
+	Rotate(ctx context.Context) (outcome string, err error)
 }
 
 type DNSLoop interface {
 	ApplyStatus(ctx context.Context, status models.LoopStatus) (
 		outcome string, err error)
 	GetStatus() (status models.LoopStatus)
+	GetBlockListSizes() (hostnames, ips, ipPrefixes int)
+	GetStats(top int) (stats models.DNSStats)
+}
+
+type HealthChecker interface {
+	GetResultCounts() (successes, failures uint64)
+}
+
+type HTTPProxyLoop interface {
+	GetStatus() (status models.LoopStatus)
+	GetConnectionCounts() (total uint64, active int64)
+}
+
+type ShadowsocksLoop interface {
+	GetStatus() (status models.LoopStatus)
+}
+
+type Socks5Loop interface {
+	ApplyStatus(ctx context.Context, status models.LoopStatus) (
+		outcome string, err error)
+	GetStatus() (status models.LoopStatus)
+}
+
+type EventSubscriber interface {
+	Subscribe() (events <-chan events.Event, unsubscribe func())
 }
 
 type PortForwardedGetter interface {
@@ -27,8 +56,18 @@ type PortForwardedGetter interface {
 
 type PublicIPLoop interface {
 	GetData() (data models.PublicIP)
+	GetIPChanges() (changes uint64)
+}
+
+type LeakTester interface {
+	Run(ctx context.Context) (report models.LeakTestReport)
 }
 
 type Storage interface {
 	GetFilterChoices(provider string) models.FilterChoices
+	FilterServers(provider string, selection settings.ServerSelection) (
+		servers []models.Server, err error)
+	GetServersChanges(provider string) (changes []models.ServersChange)
+	GetBlacklist() (servers []models.BlacklistedServer)
+	ClearBlacklist(ip netip.Addr) (removed int, err error)
 }
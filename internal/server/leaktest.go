@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+func newLeakTestHandler(ctx context.Context, tester LeakTester, w warner) http.Handler {
+	return &leakTestHandler{
+		ctx:    ctx,
+		tester: tester,
+		warner: w,
+	}
+}
+
+type leakTestHandler struct {
+	ctx    context.Context //nolint:containedctx
+	tester LeakTester
+	warner warner
+}
+
+func (h *leakTestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.RequestURI = strings.TrimPrefix(r.RequestURI, "/leaktest")
+	switch r.RequestURI {
+	case "":
+		switch r.Method {
+		case http.MethodPost:
+			h.run(w)
+		default:
+			errMethodNotSupported(w, r.Method)
+		}
+	default:
+		errRouteNotSupported(w, r.RequestURI)
+	}
+}
+
+func (h *leakTestHandler) run(w http.ResponseWriter) {
+	report := h.tester.Run(h.ctx)
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(report); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
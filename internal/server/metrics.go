@@ -0,0 +1,206 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+func newMetricsHandler(vpnLooper VPNLooper, healthChecker HealthChecker,
+	pfGetter PortForwardedGetter, publicIPLooper PublicIPLoop,
+	dnsLooper DNSLoop, updaterLooper UpdaterLooper,
+	httpProxyLooper HTTPProxyLoop, shadowsocksLooper ShadowsocksLoop,
+	socks5Looper Socks5Loop, w warner,
+) (handler http.Handler, err error) {
+	collector := &metricsCollector{
+		vpn:         vpnLooper,
+		health:      healthChecker,
+		portForward: pfGetter,
+		publicIP:    publicIPLooper,
+		dns:         dnsLooper,
+		updater:     updaterLooper,
+		httpProxy:   httpProxyLooper,
+		shadowsocks: shadowsocksLooper,
+		socks5:      socks5Looper,
+	}
+
+	registry := prometheus.NewRegistry()
+	err = registry.Register(collector)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metricsHandler{
+		promHandler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+			ErrorLog:      &promLogger{warner: w},
+			ErrorHandling: promhttp.ContinueOnError,
+		}),
+	}, nil
+}
+
+type metricsHandler struct {
+	promHandler http.Handler
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		errMethodNotSupported(w, r.Method)
+		return
+	}
+	h.promHandler.ServeHTTP(w, r)
+}
+
+type promLogger struct {
+	warner warner
+}
+
+func (l *promLogger) Println(v ...any) {
+	for _, element := range v {
+		if err, ok := element.(error); ok {
+			l.warner.Warn("metrics: " + err.Error())
+			return
+		}
+	}
+}
+
+const metricsNamespace = "gluetun"
+
+//nolint:gochecknoglobals
+var (
+	loopStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "loop_status"),
+		"Current status of a loop, set to 1 for the status label value",
+		[]string{"loop", "status"}, nil)
+	vpnReconnectsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "vpn", "reconnects_total"),
+		"Number of VPN connections started after the first one",
+		nil, nil)
+	healthCheckSuccessesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "healthcheck", "successes_total"),
+		"Number of successful health checks",
+		nil, nil)
+	healthCheckFailuresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "healthcheck", "failures_total"),
+		"Number of failed health checks",
+		nil, nil)
+	portsForwardedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "portforward", "ports"),
+		"Number of ports currently forwarded",
+		nil, nil)
+	portForwardedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "portforward", "port_info"),
+		"Port currently forwarded, set to 1 for the port label value",
+		[]string{"port"}, nil)
+	publicIPChangesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "publicip", "changes_total"),
+		"Number of public IP address changes",
+		nil, nil)
+	publicIPDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "publicip", "info"),
+		"Current public IP address information, set to 1 if known",
+		[]string{"ip", "country", "region", "city"}, nil)
+	dnsBlockListSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "dns", "blocklist_size"),
+		"Number of entries blocked by the DNS server filter",
+		[]string{"type"}, nil)
+	httpProxyConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "httpproxy", "connections_total"),
+		"Number of authorized HTTP proxy connections handled",
+		nil, nil)
+	httpProxyActiveConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "httpproxy", "connections_active"),
+		"Number of HTTP proxy connections currently active",
+		nil, nil)
+)
+
+type metricsCollector struct {
+	vpn         VPNLooper
+	health      HealthChecker
+	portForward PortForwardedGetter
+	publicIP    PublicIPLoop
+	dns         DNSLoop
+	updater     UpdaterLooper
+	httpProxy   HTTPProxyLoop
+	shadowsocks ShadowsocksLoop
+	socks5      Socks5Loop
+}
+
+func (c *metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	descriptions := []*prometheus.Desc{
+		loopStatusDesc,
+		vpnReconnectsDesc,
+		healthCheckSuccessesDesc,
+		healthCheckFailuresDesc,
+		portsForwardedDesc,
+		portForwardedDesc,
+		publicIPChangesDesc,
+		publicIPDesc,
+		dnsBlockListSizeDesc,
+		httpProxyConnectionsDesc,
+		httpProxyActiveConnectionsDesc,
+	}
+	for _, description := range descriptions {
+		ch <- description
+	}
+}
+
+func (c *metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	loopStatuses := map[string]models.LoopStatus{
+		"vpn":         c.vpn.GetStatus(),
+		"dns":         c.dns.GetStatus(),
+		"updater":     c.updater.GetStatus(),
+		"httpproxy":   c.httpProxy.GetStatus(),
+		"shadowsocks": c.shadowsocks.GetStatus(),
+		"socks5":      c.socks5.GetStatus(),
+	}
+	for loop, status := range loopStatuses {
+		ch <- prometheus.MustNewConstMetric(loopStatusDesc,
+			prometheus.GaugeValue, 1, loop, string(status))
+	}
+
+	ch <- prometheus.MustNewConstMetric(vpnReconnectsDesc,
+		prometheus.CounterValue, float64(c.vpn.GetReconnects()))
+
+	successes, failures := c.health.GetResultCounts()
+	ch <- prometheus.MustNewConstMetric(healthCheckSuccessesDesc,
+		prometheus.CounterValue, float64(successes))
+	ch <- prometheus.MustNewConstMetric(healthCheckFailuresDesc,
+		prometheus.CounterValue, float64(failures))
+
+	ports := c.portForward.GetPortsForwarded()
+	ch <- prometheus.MustNewConstMetric(portsForwardedDesc,
+		prometheus.GaugeValue, float64(len(ports)))
+	for _, port := range ports {
+		ch <- prometheus.MustNewConstMetric(portForwardedDesc,
+			prometheus.GaugeValue, 1, strconv.Itoa(int(port)))
+	}
+
+	ch <- prometheus.MustNewConstMetric(publicIPChangesDesc,
+		prometheus.CounterValue, float64(c.publicIP.GetIPChanges()))
+	publicIPData := c.publicIP.GetData()
+	if publicIPData.IP.IsValid() {
+		ch <- prometheus.MustNewConstMetric(publicIPDesc,
+			prometheus.GaugeValue, 1, publicIPData.IP.String(),
+			publicIPData.Country, publicIPData.Region, publicIPData.City)
+	}
+
+	hostnames, ips, ipPrefixes := c.dns.GetBlockListSizes()
+	blockListSizes := map[string]int{
+		"hostnames":   hostnames,
+		"ips":         ips,
+		"ip_prefixes": ipPrefixes,
+	}
+	for blockListType, size := range blockListSizes {
+		ch <- prometheus.MustNewConstMetric(dnsBlockListSizeDesc,
+			prometheus.GaugeValue, float64(size), blockListType)
+	}
+
+	total, active := c.httpProxy.GetConnectionCounts()
+	ch <- prometheus.MustNewConstMetric(httpProxyConnectionsDesc,
+		prometheus.CounterValue, float64(total))
+	ch <- prometheus.MustNewConstMetric(httpProxyActiveConnectionsDesc,
+		prometheus.GaugeValue, float64(active))
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	auditOutcomeAuthorized   = "authorized"
+	auditOutcomeUnauthorized = "unauthorized"
+	auditOutcomeRateLimited  = "rate_limited"
+)
+
+// auditLogger appends JSON lines describing requests to a file.
+// The file is opened for each entry, so it can be rotated by
+// an external program without restarting gluetun.
+type auditLogger struct {
+	filepath string
+	mutex    sync.Mutex
+	timeNow  func() time.Time
+}
+
+func newAuditLogger(filepath string) *auditLogger {
+	if filepath == "" {
+		return nil
+	}
+	return &auditLogger{
+		filepath: filepath,
+		timeNow:  time.Now,
+	}
+}
+
+type auditEntry struct {
+	Time     time.Time `json:"time"`
+	Outcome  string    `json:"outcome"`
+	Route    string    `json:"route"`
+	Role     string    `json:"role,omitempty"`
+	Roles    []string  `json:"roles_checked,omitempty"`
+	SourceIP string    `json:"source_ip"`
+}
+
+// log appends an entry for the request to the audit log file.
+// role is the name of the role authorized or rate limited,
+// and checkedRoles are the names of the roles checked for
+// unauthorized requests. It is a no-op if the receiver is nil.
+func (a *auditLogger) log(request *http.Request, route, outcome, role string,
+	checkedRoles []string,
+) (err error) {
+	if a == nil {
+		return nil
+	}
+
+	entry := auditEntry{
+		Time:     a.timeNow(),
+		Outcome:  outcome,
+		Route:    route,
+		Role:     role,
+		Roles:    checkedRoles,
+		SourceIP: sourceIP(request),
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	const perms os.FileMode = 0o600
+	file, err := os.OpenFile(a.filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perms)
+	if err != nil {
+		return fmt.Errorf("opening audit log file: %w", err)
+	}
+
+	_, err = file.Write(line)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("writing to audit log file: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("closing audit log file: %w", err)
+	}
+	return nil
+}
+
+func sourceIP(request *http.Request) string {
+	addrPort, err := netip.ParseAddrPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return addrPort.Addr().Unmap().String()
+}
@@ -30,7 +30,12 @@ func Test_Read(t *testing.T) {
  | ~~~~~~~ missing field`,
 		},
 		"filled_settings": {
-			fileContent: `[[roles]]
+			fileContent: `audit_log_path = "/gluetun/auth/audit.log"
+
+[presets]
+monitoring = ["GET /v1/vpn/status", "GET /metrics"]
+
+[[roles]]
 name = "public"
 auth = "none"
 routes = ["GET /v1/vpn/status", "PUT /v1/vpn/status"]
@@ -39,18 +44,26 @@ routes = ["GET /v1/vpn/status", "PUT /v1/vpn/status"]
 name = "client"
 auth = "apikey"
 apikey = "xyz"
+rate_limit = 10
 routes = ["GET /v1/vpn/status"]
+presets = ["monitoring"]
 `,
 			settings: Settings{
+				AuditLogPath: "/gluetun/auth/audit.log",
+				Presets: map[string][]string{
+					"monitoring": {"GET /v1/vpn/status", "GET /metrics"},
+				},
 				Roles: []Role{{
 					Name:   "public",
 					Auth:   AuthNone,
 					Routes: []string{"GET /v1/vpn/status", "PUT /v1/vpn/status"},
 				}, {
-					Name:   "client",
-					Auth:   AuthAPIKey,
-					APIKey: "xyz",
-					Routes: []string{"GET /v1/vpn/status"},
+					Name:      "client",
+					Auth:      AuthAPIKey,
+					APIKey:    "xyz",
+					RateLimit: 10,
+					Routes:    []string{"GET /v1/vpn/status"},
+					Presets:   []string{"monitoring"},
 				}},
 			},
 		},
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+var (
+	ErrJWKSNoKey            = errors.New("JWKS contains no usable key")
+	ErrJWKKeyTypeNotValid   = errors.New("JWK key type is not supported")
+	ErrJWKCurveNotValid     = errors.New("JWK curve is not supported")
+	ErrJWKAlgorithmMismatch = errors.New("JWK algorithm does not match its key type")
+	ErrJWKKeySizeNotValid   = errors.New("JWK key size is not valid")
+)
+
+// readJWKSFile reads the JSON Web Key Set file at the path given
+// and returns its signature verification keys. Keys with a use
+// other than "sig" are ignored.
+func readJWKSFile(filepath string) (keys []jwtKey, err error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	err = json.Unmarshal(data, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+
+	keys = make([]jwtKey, 0, len(jwks.Keys))
+	for i, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.toKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %d of %d: %w", i+1, len(jwks.Keys), err)
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w", ErrJWKSNoKey)
+	}
+	return keys, nil
+}
+
+// jwk is a JSON Web Key as described in RFC 7517,
+// restricted to the fields needed for signature verification.
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// Symmetric key
+	K string `json:"k"`
+	// RSA public key
+	N string `json:"n"`
+	E string `json:"e"`
+	// Octet key pair public key
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+}
+
+func (j jwk) toKey() (key jwtKey, err error) {
+	key.id = j.KeyID
+
+	switch j.KeyType {
+	case "oct":
+		key.algorithm = jwtAlgHS256
+		key.hmacKey, err = base64.RawURLEncoding.DecodeString(j.K)
+		if err != nil {
+			return key, fmt.Errorf("decoding symmetric key: %w", err)
+		}
+	case "RSA":
+		key.algorithm = jwtAlgRS256
+		key.rsaKey, err = j.rsaPublicKey()
+		if err != nil {
+			return key, err
+		}
+	case "OKP":
+		if j.Curve != "Ed25519" {
+			return key, fmt.Errorf("%w: %s", ErrJWKCurveNotValid, j.Curve)
+		}
+		key.algorithm = jwtAlgEdDSA
+		key.ed25519Key, err = base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return key, fmt.Errorf("decoding Ed25519 public key: %w", err)
+		} else if len(key.ed25519Key) != ed25519.PublicKeySize {
+			return key, fmt.Errorf("%w: Ed25519 public key has %d bytes instead of %d",
+				ErrJWKKeySizeNotValid, len(key.ed25519Key), ed25519.PublicKeySize)
+		}
+	default:
+		return key, fmt.Errorf("%w: %s", ErrJWKKeyTypeNotValid, j.KeyType)
+	}
+
+	if j.Algorithm != "" && j.Algorithm != key.algorithm {
+		return key, fmt.Errorf("%w: %s for key type %s",
+			ErrJWKAlgorithmMismatch, j.Algorithm, j.KeyType)
+	}
+
+	return key, nil
+}
+
+func (j jwk) rsaPublicKey() (publicKey *rsa.PublicKey, err error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(j.N)
+	if err != nil {
+		return nil, fmt.Errorf("decoding RSA modulus: %w", err)
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(j.E)
+	if err != nil {
+		return nil, fmt.Errorf("decoding RSA exponent: %w", err)
+	}
+
+	const minBits = 2048
+	publicKey = &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}
+	if publicKey.N.BitLen() < minBits {
+		return nil, fmt.Errorf("%w: RSA modulus has %d bits, minimum is %d",
+			ErrJWKKeySizeNotValid, publicKey.N.BitLen(), minBits)
+	}
+	return publicKey, nil
+}
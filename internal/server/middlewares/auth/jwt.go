@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	jwtAlgHS256 = "HS256"
+	jwtAlgRS256 = "RS256"
+	jwtAlgEdDSA = "EdDSA"
+)
+
+type jwtMethod struct {
+	secretDigest [32]byte
+	jwksFilepath string
+	keys         []jwtKey
+	issuer       string
+	audience     string
+	claims       map[string]string
+	timeNow      func() time.Time
+}
+
+func newJWTMethod(secret, jwksFilepath, issuer, audience string,
+	claims map[string]string,
+) (method *jwtMethod, err error) {
+	method = &jwtMethod{
+		jwksFilepath: jwksFilepath,
+		issuer:       issuer,
+		audience:     audience,
+		claims:       claims,
+		timeNow:      time.Now,
+	}
+
+	if secret != "" {
+		method.secretDigest = sha256.Sum256([]byte(secret))
+		method.keys = append(method.keys, jwtKey{
+			algorithm: jwtAlgHS256,
+			hmacKey:   []byte(secret),
+		})
+	}
+
+	if jwksFilepath != "" {
+		keys, err := readJWKSFile(jwksFilepath)
+		if err != nil {
+			return nil, fmt.Errorf("reading JWKS file: %w", err)
+		}
+		method.keys = append(method.keys, keys...)
+	}
+
+	return method, nil
+}
+
+// equal returns true if another auth checker is equal.
+// This is used to deduplicate checkers for a particular route.
+func (j *jwtMethod) equal(other authorizationChecker) bool {
+	otherJWTMethod, ok := other.(*jwtMethod)
+	if !ok {
+		return false
+	}
+	return j.secretDigest == otherJWTMethod.secretDigest &&
+		j.jwksFilepath == otherJWTMethod.jwksFilepath &&
+		j.issuer == otherJWTMethod.issuer &&
+		j.audience == otherJWTMethod.audience &&
+		maps.Equal(j.claims, otherJWTMethod.claims)
+}
+
+func (j *jwtMethod) isAuthorized(headers http.Header, request *http.Request) bool {
+	token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		headers.Add("WWW-Authenticate", `Bearer realm="restricted"`)
+		return false
+	}
+	return j.verify(strings.TrimSpace(token)) == nil
+}
+
+var (
+	ErrJWTMalformed         = errors.New("JWT is malformed")
+	ErrJWTAlgorithmNotValid = errors.New("JWT algorithm is not supported")
+	ErrJWTSignatureNotValid = errors.New("JWT signature is not valid")
+	ErrJWTExpired           = errors.New("JWT is expired")
+	ErrJWTNotValidYet       = errors.New("JWT is not valid yet")
+	ErrJWTIssuerMismatch    = errors.New("JWT issuer does not match")
+	ErrJWTAudienceMismatch  = errors.New("JWT audience does not match")
+	ErrJWTClaimMismatch     = errors.New("JWT claim does not match")
+	ErrJWTNoKeyForAlgorithm = errors.New("no key configured for JWT algorithm")
+	ErrJWTClaimNotString    = errors.New("claim is not a string or array of strings")
+)
+
+// verify verifies the token signature and its claims.
+func (j *jwtMethod) verify(token string) (err error) {
+	parts := strings.Split(token, ".")
+	const expectedParts = 3
+	if len(parts) != expectedParts {
+		return fmt.Errorf("%w: %d parts instead of %d", ErrJWTMalformed, len(parts), expectedParts)
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	err = decodeJWTPart(parts[0], &header)
+	if err != nil {
+		return fmt.Errorf("decoding header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: decoding signature: %w", ErrJWTMalformed, err)
+	}
+
+	err = j.verifySignature(header.Algorithm, header.KeyID,
+		[]byte(parts[0]+"."+parts[1]), signature)
+	if err != nil {
+		return err
+	}
+
+	var claims map[string]any
+	err = decodeJWTPart(parts[1], &claims)
+	if err != nil {
+		return fmt.Errorf("decoding claims: %w", err)
+	}
+	return j.verifyClaims(claims)
+}
+
+func decodeJWTPart(part string, v any) (err error) {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrJWTMalformed, err)
+	}
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrJWTMalformed, err)
+	}
+	return nil
+}
+
+func (j *jwtMethod) verifySignature(algorithm, keyID string,
+	signed, signature []byte,
+) (err error) {
+	switch algorithm {
+	case jwtAlgHS256, jwtAlgRS256, jwtAlgEdDSA:
+	default:
+		return fmt.Errorf("%w: %s", ErrJWTAlgorithmNotValid, algorithm)
+	}
+
+	keyFound := false
+	for _, key := range j.keys {
+		// Only try keys of the token algorithm, to prevent algorithm
+		// confusion attacks such as using a public key as HMAC secret.
+		if key.algorithm != algorithm ||
+			(keyID != "" && key.id != "" && key.id != keyID) {
+			continue
+		}
+		keyFound = true
+		if key.verify(signed, signature) {
+			return nil
+		}
+	}
+
+	if !keyFound {
+		return fmt.Errorf("%w: %s", ErrJWTNoKeyForAlgorithm, algorithm)
+	}
+	return fmt.Errorf("%w", ErrJWTSignatureNotValid)
+}
+
+func (j *jwtMethod) verifyClaims(claims map[string]any) (err error) {
+	// leeway tolerates small clock differences with the token issuer.
+	const leeway = 30 * time.Second
+	now := j.timeNow()
+
+	if expiry, ok := claims["exp"].(float64); ok &&
+		now.After(time.Unix(int64(expiry), 0).Add(leeway)) {
+		return fmt.Errorf("%w", ErrJWTExpired)
+	}
+
+	if notBefore, ok := claims["nbf"].(float64); ok &&
+		now.Add(leeway).Before(time.Unix(int64(notBefore), 0)) {
+		return fmt.Errorf("%w", ErrJWTNotValidYet)
+	}
+
+	if j.issuer != "" && claims["iss"] != j.issuer {
+		return fmt.Errorf("%w: %v", ErrJWTIssuerMismatch, claims["iss"])
+	}
+
+	if j.audience != "" {
+		audiences, err := claimStrings(claims["aud"])
+		if err != nil || !slices.Contains(audiences, j.audience) {
+			return fmt.Errorf("%w: %v", ErrJWTAudienceMismatch, claims["aud"])
+		}
+	}
+
+	for name, expected := range j.claims {
+		values, err := claimStrings(claims[name])
+		if err != nil || !slices.Contains(values, expected) {
+			return fmt.Errorf("%w: %s", ErrJWTClaimMismatch, name)
+		}
+	}
+
+	return nil
+}
+
+// claimStrings returns the claim value as a slice of strings,
+// if it is a string or an array of strings.
+func claimStrings(claim any) (values []string, err error) {
+	switch typed := claim.(type) {
+	case string:
+		return []string{typed}, nil
+	case []any:
+		values = make([]string, len(typed))
+		for i, element := range typed {
+			value, ok := element.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w", ErrJWTClaimNotString)
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%w", ErrJWTClaimNotString)
+	}
+}
+
+type jwtKey struct {
+	id         string
+	algorithm  string
+	hmacKey    []byte
+	rsaKey     *rsa.PublicKey
+	ed25519Key ed25519.PublicKey
+}
+
+func (k jwtKey) verify(signed, signature []byte) (ok bool) {
+	switch k.algorithm {
+	case jwtAlgHS256:
+		mac := hmac.New(sha256.New, k.hmacKey)
+		_, _ = mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case jwtAlgRS256:
+		digest := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(k.rsaKey, crypto.SHA256, digest[:], signature) == nil
+	case jwtAlgEdDSA:
+		return ed25519.Verify(k.ed25519Key, signed, signature)
+	default:
+		return false
+	}
+}
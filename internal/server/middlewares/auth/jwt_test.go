@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestJWT(t *testing.T, header, claims map[string]any,
+	sign func(signed []byte) []byte,
+) string {
+	t.Helper()
+	headerJSON, err := json.Marshal(header)
+	require.NoError(t, err)
+	claimsJSON, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(headerJSON) + "." +
+		base64.RawURLEncoding.EncodeToString(claimsJSON)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func Test_jwtMethod_verify(t *testing.T) {
+	t.Parallel()
+
+	const secret = "secret"
+	signHS256 := func(signed []byte) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write(signed)
+		return mac.Sum(nil)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048) //nolint:gosec
+	require.NoError(t, err)
+	signRS256 := func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signature
+	}
+
+	ed25519PublicKey, ed25519PrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signEdDSA := func(signed []byte) []byte {
+		return ed25519.Sign(ed25519PrivateKey, signed)
+	}
+
+	jwks := map[string]any{"keys": []map[string]any{{
+		"kty": "RSA",
+		"kid": "rsa",
+		"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}, {
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"kid": "ed",
+		"x":   base64.RawURLEncoding.EncodeToString(ed25519PublicKey),
+	}}}
+	jwksData, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	jwksPath := t.TempDir() + "/jwks.json"
+	err = os.WriteFile(jwksPath, jwksData, 0o600)
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	method, err := newJWTMethod(secret, jwksPath, "https://sso.example.com", "gluetun",
+		map[string]string{"groups": "vpn-admins"})
+	require.NoError(t, err)
+	method.timeNow = func() time.Time { return now }
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss":    "https://sso.example.com",
+			"aud":    []string{"other", "gluetun"},
+			"exp":    now.Add(time.Minute).Unix(),
+			"groups": []string{"users", "vpn-admins"},
+		}
+	}
+
+	testCases := map[string]struct {
+		header     map[string]any
+		claims     func() map[string]any
+		sign       func(signed []byte) []byte
+		errWrapped error
+	}{
+		"HS256": {
+			header: map[string]any{"alg": "HS256", "typ": "JWT"},
+			claims: validClaims,
+			sign:   signHS256,
+		},
+		"RS256": {
+			header: map[string]any{"alg": "RS256", "kid": "rsa"},
+			claims: validClaims,
+			sign:   signRS256,
+		},
+		"EdDSA": {
+			header: map[string]any{"alg": "EdDSA", "kid": "ed"},
+			claims: validClaims,
+			sign:   signEdDSA,
+		},
+		"algorithm_none": {
+			header:     map[string]any{"alg": "none"},
+			claims:     validClaims,
+			sign:       func([]byte) []byte { return nil },
+			errWrapped: ErrJWTAlgorithmNotValid,
+		},
+		"algorithm_confusion": {
+			header:     map[string]any{"alg": "EdDSA"},
+			claims:     validClaims,
+			sign:       signHS256,
+			errWrapped: ErrJWTSignatureNotValid,
+		},
+		"expired": {
+			header: map[string]any{"alg": "HS256"},
+			claims: func() map[string]any {
+				claims := validClaims()
+				claims["exp"] = now.Add(-time.Hour).Unix()
+				return claims
+			},
+			sign:       signHS256,
+			errWrapped: ErrJWTExpired,
+		},
+		"audience_mismatch": {
+			header: map[string]any{"alg": "HS256"},
+			claims: func() map[string]any {
+				claims := validClaims()
+				claims["aud"] = "other"
+				return claims
+			},
+			sign:       signHS256,
+			errWrapped: ErrJWTAudienceMismatch,
+		},
+		"claim_mismatch": {
+			header: map[string]any{"alg": "RS256"},
+			claims: func() map[string]any {
+				claims := validClaims()
+				claims["groups"] = "users"
+				return claims
+			},
+			sign:       signRS256,
+			errWrapped: ErrJWTClaimMismatch,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			token := makeTestJWT(t, testCase.header, testCase.claims(), testCase.sign)
+
+			err := method.verify(token)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+		})
+	}
+}
@@ -2,11 +2,16 @@ package auth
 
 import (
 	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
 )
 
 type internalRole struct {
 	name    string
 	checker authorizationChecker
+	// limiter is nil if the role has no rate limit.
+	limiter *rate.Limiter
 }
 
 func settingsToLookupMap(settings Settings) (routeToRoles map[string][]internalRole, err error) {
@@ -20,6 +25,14 @@ func settingsToLookupMap(settings Settings) (routeToRoles map[string][]internalR
 			checker = newAPIKeyMethod(role.APIKey)
 		case AuthBasic:
 			checker = newBasicAuthMethod(role.Username, role.Password)
+		case AuthJWT:
+			checker, err = newJWTMethod(role.JWTSecret, role.JWTJWKSFile,
+				role.JWTIssuer, role.JWTAudience, role.JWTClaims)
+			if err != nil {
+				return nil, fmt.Errorf("creating JWT method for role %s: %w", role.Name, err)
+			}
+		case AuthMTLS:
+			checker = newMTLSMethod(role.MTLSCommonName)
 		default:
 			return nil, fmt.Errorf("%w: %s", ErrMethodNotSupported, role.Auth)
 		}
@@ -28,7 +41,17 @@ func settingsToLookupMap(settings Settings) (routeToRoles map[string][]internalR
 			name:    role.Name,
 			checker: checker,
 		}
-		for _, route := range role.Routes {
+		if role.RateLimit > 0 {
+			// The limiter is shared by all the routes of the role.
+			// The burst allows a full minute of requests at once.
+			limit := rate.Every(time.Minute / time.Duration(role.RateLimit))
+			iRole.limiter = rate.NewLimiter(limit, int(role.RateLimit))
+		}
+		routes, err := settings.roleRoutes(role)
+		if err != nil {
+			return nil, fmt.Errorf("expanding routes of role %s: %w", role.Name, err)
+		}
+		for _, route := range routes {
 			checkerExists := false
 			for _, role := range routeToRoles[route] {
 				if role.checker.equal(iRole.checker) {
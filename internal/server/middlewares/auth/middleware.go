@@ -2,53 +2,114 @@ package auth
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"sync/atomic"
 )
 
+// Middleware is the authentication middleware, whose settings
+// can be replaced at runtime using [Middleware.Update].
+type Middleware struct {
+	state  atomic.Pointer[middlewareState]
+	logger DebugLogger
+}
+
+type middlewareState struct {
+	routeToRoles map[string][]internalRole
+	auditLogger  *auditLogger
+}
+
 func New(settings Settings, debugLogger DebugLogger) (
-	middleware func(http.Handler) http.Handler,
-	err error,
+	middleware *Middleware, err error,
 ) {
+	middleware = &Middleware{
+		logger: debugLogger,
+	}
+	err = middleware.Update(settings)
+	if err != nil {
+		return nil, err
+	}
+	return middleware, nil
+}
+
+// Update atomically replaces the roles and audit log path used
+// by the middleware with the ones from the settings given, which
+// should be validated beforehand. Requests already being processed
+// complete with the previous settings, and role rate limits are reset.
+// The previous settings are kept if an error is returned.
+func (m *Middleware) Update(settings Settings) (err error) {
 	routeToRoles, err := settingsToLookupMap(settings)
 	if err != nil {
-		return nil, fmt.Errorf("converting settings to lookup maps: %w", err)
+		return fmt.Errorf("converting settings to lookup maps: %w", err)
 	}
 
-	return func(handler http.Handler) http.Handler {
-		return &authHandler{
-			childHandler: handler,
-			routeToRoles: routeToRoles,
-			logger:       debugLogger,
-		}
-	}, nil
+	m.state.Store(&middlewareState{
+		routeToRoles: routeToRoles,
+		auditLogger:  newAuditLogger(settings.AuditLogPath),
+	})
+	return nil
+}
+
+// Wrap returns the handler given wrapped with the authentication middleware.
+func (m *Middleware) Wrap(handler http.Handler) http.Handler {
+	return &authHandler{
+		childHandler: handler,
+		middleware:   m,
+		logger:       m.logger,
+	}
 }
 
 type authHandler struct {
 	childHandler http.Handler
-	routeToRoles map[string][]internalRole
+	middleware   *Middleware
 	logger       DebugLogger
 }
 
 func (h *authHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	state := h.middleware.state.Load()
 	route := request.Method + " " + request.URL.Path
-	roles := h.routeToRoles[route]
+	roles := state.routeToRoles[route]
 	if len(roles) == 0 {
 		h.logger.Debugf("no authentication role defined for route %s", route)
+		h.audit(state, request, route, auditOutcomeUnauthorized, "", nil)
 		http.Error(writer, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 		return
 	}
 
 	responseHeader := make(http.Header, 0)
+	var rateLimitedRole *internalRole
 	for _, role := range roles {
 		if !role.checker.isAuthorized(responseHeader, request) {
 			continue
 		}
 
+		if role.limiter != nil && !role.limiter.Allow() {
+			// Another authorized role may not be rate limited.
+			if rateLimitedRole == nil {
+				rateLimitedRole = &role
+			}
+			continue
+		}
+
 		h.logger.Debugf("access to route %s authorized for role %s", route, role.name)
+		h.audit(state, request, route, auditOutcomeAuthorized, role.name, nil)
 		h.childHandler.ServeHTTP(writer, request)
 		return
 	}
 
+	if rateLimitedRole != nil {
+		h.logger.Debugf("access to route %s rate limited for role %s", route, rateLimitedRole.name)
+		h.audit(state, request, route, auditOutcomeRateLimited, rateLimitedRole.name, nil)
+		reservation := rateLimitedRole.limiter.Reserve()
+		retryAfter := reservation.Delay()
+		reservation.Cancel()
+		// Retry-After is at least 1 second so clients do not retry immediately.
+		retryAfterSeconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+		writer.Header().Set("Retry-After", fmt.Sprint(retryAfterSeconds))
+		http.Error(writer, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
 	// Flush out response headers if all roles failed to authenticate
 	for headerKey, headerValues := range responseHeader {
 		for _, headerValue := range headerValues {
@@ -62,5 +123,15 @@ func (h *authHandler) ServeHTTP(writer http.ResponseWriter, request *http.Reques
 	}
 	h.logger.Debugf("access to route %s unauthorized after checking for roles %s",
 		route, andStrings(allRoleNames))
+	h.audit(state, request, route, auditOutcomeUnauthorized, "", allRoleNames)
 	http.Error(writer, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 }
+
+func (h *authHandler) audit(state *middlewareState, request *http.Request,
+	route, outcome, role string, checkedRoles []string,
+) {
+	err := state.auditLogger.log(request, route, outcome, role, checkedRoles)
+	if err != nil {
+		h.logger.Warnf("audit logging: %s", err)
+	}
+}
@@ -2,10 +2,13 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -73,7 +76,7 @@ func Test_authHandler_ServeHTTP(t *testing.T) {
 			childHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 				w.WriteHeader(http.StatusOK)
 			})
-			handler := middleware(childHandler)
+			handler := middleware.Wrap(childHandler)
 
 			server := httptest.NewServer(handler)
 			t.Cleanup(server.Close)
@@ -100,3 +103,106 @@ func Test_authHandler_ServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+func Test_authHandler_rateLimitAndAudit(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	auditLogPath := t.TempDir() + "/audit.log"
+	settings := Settings{
+		AuditLogPath: auditLogPath,
+		Roles: []Role{
+			{Name: "limited", Auth: AuthNone, RateLimit: 1, Routes: []string{"GET /a"}},
+			{Name: "client", Auth: AuthAPIKey, APIKey: "xyz", Routes: []string{"GET /b"}},
+		},
+	}
+	logger := NewMockDebugLogger(ctrl)
+	logger.EXPECT().Debugf("access to route %s authorized for role %s", "GET /a", "limited")
+	logger.EXPECT().Debugf("access to route %s rate limited for role %s", "GET /a", "limited")
+	logger.EXPECT().Debugf("access to route %s unauthorized after checking for roles %s",
+		"GET /b", "client")
+
+	middleware, err := New(settings, logger)
+	require.NoError(t, err)
+	handler := middleware.Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	requests := []struct {
+		path       string
+		statusCode int
+	}{
+		{path: "/a", statusCode: http.StatusOK},
+		{path: "/a", statusCode: http.StatusTooManyRequests},
+		{path: "/b", statusCode: http.StatusUnauthorized},
+	}
+	for _, request := range requests {
+		httpRequest := httptest.NewRequest(http.MethodGet, request.path, nil)
+		httpRequest.RemoteAddr = "1.2.3.4:5678"
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httpRequest)
+		assert.Equal(t, request.statusCode, recorder.Code)
+	}
+
+	data, err := os.ReadFile(auditLogPath)
+	require.NoError(t, err)
+	var outcomes []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry auditEntry
+		err = json.Unmarshal([]byte(line), &entry)
+		require.NoError(t, err)
+		assert.Equal(t, "1.2.3.4", entry.SourceIP)
+		outcomes = append(outcomes, entry.Outcome+" "+entry.Route+" "+
+			entry.Role+strings.Join(entry.Roles, ","))
+	}
+	expectedOutcomes := []string{
+		"authorized GET /a limited",
+		"rate_limited GET /a limited",
+		"unauthorized GET /b client",
+	}
+	assert.Equal(t, expectedOutcomes, outcomes)
+}
+
+func Test_Middleware_Update(t *testing.T) {
+	t.Parallel()
+
+	settings := Settings{
+		Roles: []Role{
+			{Name: "client", Auth: AuthAPIKey, APIKey: "old", Routes: []string{"GET /a"}},
+		},
+	}
+	middleware, err := New(settings, &noopDebugLogger{})
+	require.NoError(t, err)
+	handler := middleware.Wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(apiKey string) (statusCode int) {
+		request := httptest.NewRequest(http.MethodGet, "/a", nil)
+		request.Header.Set("X-API-Key", apiKey)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("old"))
+
+	settings.Roles[0].APIKey = "new"
+	err = middleware.Update(settings)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, serve("old"))
+	assert.Equal(t, http.StatusOK, serve("new"))
+
+	// Failed update keeps the current settings
+	err = middleware.Update(Settings{
+		Roles: []Role{{Name: "bad", Auth: "unknown", Routes: []string{"GET /a"}}},
+	})
+	require.ErrorIs(t, err, ErrMethodNotSupported)
+	assert.Equal(t, http.StatusOK, serve("new"))
+}
+
+type noopDebugLogger struct{}
+
+func (noopDebugLogger) Debugf(string, ...any) {}
+func (noopDebugLogger) Warnf(string, ...any)  {}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+)
+
+type mtlsMethod struct {
+	commonName string
+}
+
+func newMTLSMethod(commonName string) *mtlsMethod {
+	return &mtlsMethod{
+		commonName: commonName,
+	}
+}
+
+// equal returns true if another auth checker is equal.
+// This is used to deduplicate checkers for a particular route.
+func (m *mtlsMethod) equal(other authorizationChecker) bool {
+	otherMTLSMethod, ok := other.(*mtlsMethod)
+	if !ok {
+		return false
+	}
+	return m.commonName == otherMTLSMethod.commonName
+}
+
+// isAuthorized returns true if the request client certificate was
+// verified by the TLS server and its subject common name or one of
+// its DNS subject alternative names matches the role common name.
+func (m *mtlsMethod) isAuthorized(_ http.Header, request *http.Request) bool {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 ||
+		len(request.TLS.VerifiedChains[0]) == 0 {
+		return false
+	}
+	certificate := request.TLS.VerifiedChains[0][0]
+	return certificate.Subject.CommonName == m.commonName ||
+		slices.Contains(certificate.DNSNames, m.commonName)
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_mtlsMethod_isAuthorized(t *testing.T) {
+	t.Parallel()
+
+	verifiedState := func(commonName string, dnsNames ...string) *tls.ConnectionState {
+		certificate := &x509.Certificate{
+			Subject:  pkix.Name{CommonName: commonName},
+			DNSNames: dnsNames,
+		}
+		return &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{certificate},
+			VerifiedChains:   [][]*x509.Certificate{{certificate}},
+		}
+	}
+
+	testCases := map[string]struct {
+		tlsState   *tls.ConnectionState
+		authorized bool
+	}{
+		"plaintext": {},
+		"no_client_certificate": {
+			tlsState: &tls.ConnectionState{},
+		},
+		"unverified_client_certificate": {
+			tlsState: &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{{
+					Subject: pkix.Name{CommonName: "sidecar"},
+				}},
+			},
+		},
+		"common_name_match": {
+			tlsState:   verifiedState("sidecar"),
+			authorized: true,
+		},
+		"dns_name_match": {
+			tlsState:   verifiedState("other", "sidecar"),
+			authorized: true,
+		},
+		"mismatch": {
+			tlsState: verifiedState("other", "other.example.com"),
+		},
+	}
+
+	method := newMTLSMethod("sidecar")
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/vpn/status", nil)
+			request.TLS = testCase.tlsState
+
+			authorized := method.isAuthorized(http.Header{}, request)
+
+			assert.Equal(t, testCase.authorized, authorized)
+		})
+	}
+}
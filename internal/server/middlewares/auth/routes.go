@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	// PresetReadOnly gives access to all routes reading state,
+	// excluding legacy GET routes performing actions.
+	PresetReadOnly = "read-only"
+	// PresetReadWrite gives access to all routes.
+	PresetReadWrite = "read-write"
+)
+
+// builtinPresets maps built-in preset names to their route patterns.
+// WARNING: do not mutate programmatically.
+var builtinPresets = map[string][]string{ //nolint:gochecknoglobals
+	PresetReadOnly: {
+		http.MethodGet + " /v1/*",
+		http.MethodGet + " /metrics",
+		http.MethodGet + " /openvpn/portforwarded",
+		http.MethodGet + " /openvpn/settings",
+	},
+	PresetReadWrite: {"* *"},
+}
+
+var (
+	ErrRoutePatternMalformed = errors.New("route pattern is malformed")
+	ErrRoutePatternNoMatch   = errors.New("route pattern matches no route")
+	ErrPresetNotFound        = errors.New("preset not found")
+	ErrPresetNameReserved    = errors.New("preset name is reserved for a built-in preset")
+)
+
+// expandRoutePattern returns the sorted valid routes matching the
+// route pattern given, in the format "METHOD PATH". METHOD can be `*`
+// to match any HTTP method, and PATH can contain `*` characters to
+// match any sequence of characters, including `/`.
+// It returns an error if the pattern is malformed or matches no route.
+func expandRoutePattern(pattern string) (routes []string, err error) {
+	methodPattern, pathPattern, ok := strings.Cut(pattern, " ")
+	switch {
+	case !ok, methodPattern == "", pathPattern == "",
+		strings.Contains(pathPattern, " "):
+		return nil, fmt.Errorf("%w: %q does not follow the format \"METHOD PATH\"",
+			ErrRoutePatternMalformed, pattern)
+	case !strings.HasPrefix(pathPattern, "/") && !strings.HasPrefix(pathPattern, "*"):
+		return nil, fmt.Errorf("%w: path of %q does not start with / or *",
+			ErrRoutePatternMalformed, pattern)
+	case methodPattern != "*" && strings.Contains(methodPattern, "*"):
+		return nil, fmt.Errorf("%w: method of %q must be a method or *",
+			ErrRoutePatternMalformed, pattern)
+	}
+
+	for route := range validRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		if (methodPattern == "*" || methodPattern == method) &&
+			matchWildcard(pathPattern, path) {
+			routes = append(routes, route)
+		}
+	}
+
+	switch {
+	case len(routes) > 0:
+	case !strings.Contains(pattern, "*"):
+		return nil, fmt.Errorf("%w: %s", ErrRouteNotSupported, pattern)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrRoutePatternNoMatch, pattern)
+	}
+	slices.Sort(routes)
+	return routes, nil
+}
+
+// matchWildcard returns true if s matches the pattern given,
+// where `*` matches any sequence of characters.
+func matchWildcard(pattern, s string) bool {
+	// Iterative matching with backtracking to the last `*` seen.
+	patternIndex, sIndex := 0, 0
+	starIndex, starMatchIndex := -1, 0
+	for sIndex < len(s) {
+		switch {
+		case patternIndex < len(pattern) && pattern[patternIndex] == '*':
+			starIndex = patternIndex
+			starMatchIndex = sIndex
+			patternIndex++
+		case patternIndex < len(pattern) && pattern[patternIndex] == s[sIndex]:
+			patternIndex++
+			sIndex++
+		case starIndex != -1:
+			patternIndex = starIndex + 1
+			starMatchIndex++
+			sIndex = starMatchIndex
+		default:
+			return false
+		}
+	}
+
+	for patternIndex < len(pattern) && pattern[patternIndex] == '*' {
+		patternIndex++
+	}
+	return patternIndex == len(pattern)
+}
+
+// presetPatterns returns the route patterns of the preset
+// named, looking first at user defined presets.
+func (s Settings) presetPatterns(name string) (patterns []string, err error) {
+	patterns, ok := s.Presets[name]
+	if ok {
+		return patterns, nil
+	}
+	patterns, ok = builtinPresets[name]
+	if ok {
+		return patterns, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPresetNotFound, name)
+}
+
+// RouteExpansion describes the concrete routes a role
+// route pattern or preset expands to.
+type RouteExpansion struct {
+	// Role is the role name.
+	Role string
+	// Pattern is the route pattern, or the preset name
+	// prefixed with "preset ".
+	Pattern string
+	// Routes are the sorted concrete routes the pattern expands to.
+	Routes []string
+}
+
+// Expansions returns the concrete routes each route pattern and
+// preset of each role expands to. It returns an error if a pattern
+// is malformed or matches no route, or if a preset does not exist.
+func (s Settings) Expansions() (expansions []RouteExpansion, err error) {
+	for _, role := range s.Roles {
+		roleExpansions, err := s.roleExpansions(role)
+		if err != nil {
+			return nil, fmt.Errorf("role %s: %w", role.Name, err)
+		}
+		expansions = append(expansions, roleExpansions...)
+	}
+	return expansions, nil
+}
+
+func (s Settings) roleExpansions(role Role) (expansions []RouteExpansion, err error) {
+	expansions = make([]RouteExpansion, 0, len(role.Routes)+len(role.Presets))
+	for _, pattern := range role.Routes {
+		// Exact routes are kept as they are, and are
+		// validated by [Role.Validate].
+		routes := []string{pattern}
+		if strings.Contains(pattern, "*") {
+			routes, err = expandRoutePattern(pattern)
+			if err != nil {
+				return nil, err
+			}
+		}
+		expansions = append(expansions, RouteExpansion{
+			Role:    role.Name,
+			Pattern: pattern,
+			Routes:  routes,
+		})
+	}
+
+	for _, preset := range role.Presets {
+		patterns, err := s.presetPatterns(preset)
+		if err != nil {
+			return nil, err
+		}
+		routesSet := make(map[string]struct{})
+		for _, pattern := range patterns {
+			routes, err := expandRoutePattern(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("preset %s: %w", preset, err)
+			}
+			for _, route := range routes {
+				routesSet[route] = struct{}{}
+			}
+		}
+		routes := make([]string, 0, len(routesSet))
+		for route := range routesSet {
+			routes = append(routes, route)
+		}
+		slices.Sort(routes)
+		expansions = append(expansions, RouteExpansion{
+			Role:    role.Name,
+			Pattern: "preset " + preset,
+			Routes:  routes,
+		})
+	}
+	return expansions, nil
+}
+
+// roleRoutes returns the sorted and deduplicated concrete
+// routes the role route patterns and presets expand to.
+func (s Settings) roleRoutes(role Role) (routes []string, err error) {
+	expansions, err := s.roleExpansions(role)
+	if err != nil {
+		return nil, err
+	}
+	for _, expansion := range expansions {
+		routes = append(routes, expansion.Routes...)
+	}
+	slices.Sort(routes)
+	return slices.Compact(routes), nil
+}
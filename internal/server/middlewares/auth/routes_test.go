@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_expandRoutePattern(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		pattern    string
+		routes     []string
+		errWrapped error
+		errMessage string
+	}{
+		"exact_route": {
+			pattern: "GET /v1/vpn/status",
+			routes:  []string{"GET /v1/vpn/status"},
+		},
+		"any_method": {
+			pattern: "* /v1/dns/status",
+			routes:  []string{"GET /v1/dns/status", "PUT /v1/dns/status"},
+		},
+		"path_wildcard": {
+			pattern: "PUT /v1/*/status",
+			routes: []string{
+				"PUT /v1/dns/status", "PUT /v1/openvpn/status", "PUT /v1/socks5/status",
+				"PUT /v1/updater/status", "PUT /v1/vpn/status",
+			},
+		},
+		"route_not_supported": {
+			pattern:    "GET /v1/unknown",
+			errWrapped: ErrRouteNotSupported,
+			errMessage: "route not supported by the control server: GET /v1/unknown",
+		},
+		"no_match": {
+			pattern:    "PATCH /v1/*",
+			errWrapped: ErrRoutePatternNoMatch,
+			errMessage: "route pattern matches no route: PATCH /v1/*",
+		},
+		"malformed": {
+			pattern:    "/v1/vpn/status",
+			errWrapped: ErrRoutePatternMalformed,
+			errMessage: `route pattern is malformed: "/v1/vpn/status" does not follow the format "METHOD PATH"`,
+		},
+		"method_wildcard_partial": {
+			pattern:    "G* /v1/vpn/status",
+			errWrapped: ErrRoutePatternMalformed,
+			errMessage: `route pattern is malformed: method of "G* /v1/vpn/status" must be a method or *`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			routes, err := expandRoutePattern(testCase.pattern)
+
+			assert.Equal(t, testCase.routes, routes)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
+
+func Test_matchWildcard(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		pattern string
+		s       string
+		match   bool
+	}{
+		"exact":            {pattern: "/v1/vpn", s: "/v1/vpn", match: true},
+		"exact_mismatch":   {pattern: "/v1/vpn", s: "/v1/dns"},
+		"star_only":        {pattern: "*", s: "/v1/vpn/status", match: true},
+		"trailing_star":    {pattern: "/v1/*", s: "/v1/vpn/status", match: true},
+		"middle_star":      {pattern: "/v1/*/status", s: "/v1/vpn/status", match: true},
+		"middle_star_miss": {pattern: "/v1/*/status", s: "/v1/vpn/settings"},
+		"backtracking":     {pattern: "/*s*s", s: "/v1/vpn/status", match: true},
+		"prefix_mismatch":  {pattern: "/v1/*", s: "/metrics"},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			match := matchWildcard(testCase.pattern, testCase.s)
+
+			assert.Equal(t, testCase.match, match)
+		})
+	}
+}
+
+func Test_Settings_Expansions(t *testing.T) {
+	t.Parallel()
+
+	settings := Settings{
+		Presets: map[string][]string{
+			"monitoring": {"GET /v1/vpn/status", "GET /metrics"},
+		},
+		Roles: []Role{{
+			Name:    "monitoring",
+			Auth:    AuthNone,
+			Presets: []string{"monitoring"},
+		}, {
+			Name:    "reader",
+			Auth:    AuthAPIKey,
+			APIKey:  "xyz",
+			Routes:  []string{"* /v1/portforward"},
+			Presets: []string{PresetReadOnly},
+		}},
+	}
+	require.NoError(t, settings.Validate())
+
+	expansions, err := settings.Expansions()
+	require.NoError(t, err)
+
+	require.Len(t, expansions, 3)
+	assert.Equal(t, RouteExpansion{
+		Role:    "monitoring",
+		Pattern: "preset monitoring",
+		Routes:  []string{"GET /metrics", "GET /v1/vpn/status"},
+	}, expansions[0])
+	assert.Equal(t, RouteExpansion{
+		Role:    "reader",
+		Pattern: "* /v1/portforward",
+		Routes:  []string{"GET /v1/portforward"},
+	}, expansions[1])
+	assert.Equal(t, "preset read-only", expansions[2].Pattern)
+	assert.Contains(t, expansions[2].Routes, "GET /v1/vpn/status")
+	assert.NotContains(t, expansions[2].Routes, "PUT /v1/vpn/status")
+	assert.NotContains(t, expansions[2].Routes, "GET /openvpn/actions/restart")
+
+	settings.Roles[0].Presets = []string{"unknown"}
+	err = settings.Validate()
+	assert.ErrorIs(t, err, ErrPresetNotFound)
+	settings.Presets = map[string][]string{PresetReadWrite: {"* *"}}
+	err = settings.Validate()
+	assert.ErrorIs(t, err, ErrPresetNameReserved)
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"path/filepath"
 	"slices"
 
 	"github.com/qdm12/gosettings"
@@ -14,6 +16,14 @@ import (
 )
 
 type Settings struct {
+	// Presets maps user defined preset names to route patterns.
+	// Roles can use these presets as well as the built-in presets
+	// 'read-only' and 'read-write'.
+	Presets map[string][]string `toml:"presets"`
+	// AuditLogPath is the path to the audit log file, where each
+	// authorized and rejected request is logged as a JSON line.
+	// It defaults to the empty string, meaning no audit log is written.
+	AuditLogPath string `toml:"audit_log_path"`
 	// Roles is a list of roles with their associated authentication
 	// and routes.
 	Roles []Role
@@ -40,7 +50,11 @@ func (s *Settings) SetDefaultRole(jsonRole string) error {
 
 	authenticatedRoutes := make(map[string]struct{}, len(validRoutes))
 	for _, role := range s.Roles {
-		for _, route := range role.Routes {
+		routes, err := s.roleRoutes(role)
+		if err != nil {
+			return fmt.Errorf("expanding routes of role %s: %w", role.Name, err)
+		}
+		for _, route := range routes {
 			authenticatedRoutes[route] = struct{}{}
 		}
 	}
@@ -64,12 +78,40 @@ func (s *Settings) SetDefaultRole(jsonRole string) error {
 }
 
 func (s Settings) Validate() (err error) {
+	if s.AuditLogPath != "" {
+		_, err = filepath.Abs(s.AuditLogPath)
+		if err != nil {
+			return fmt.Errorf("audit log path is not valid: %w", err)
+		}
+	}
+
+	for name, patterns := range s.Presets {
+		_, isBuiltin := builtinPresets[name]
+		if isBuiltin {
+			return fmt.Errorf("%w: %s", ErrPresetNameReserved, name)
+		}
+		for _, pattern := range patterns {
+			_, err = expandRoutePattern(pattern)
+			if err != nil {
+				return fmt.Errorf("preset %s: %w", name, err)
+			}
+		}
+	}
+
 	for i, role := range s.Roles {
 		err = role.Validate()
 		if err != nil {
 			return fmt.Errorf("role %s (%d of %d): %w",
 				role.Name, i+1, len(s.Roles), err)
 		}
+
+		for _, preset := range role.Presets {
+			_, err = s.presetPatterns(preset)
+			if err != nil {
+				return fmt.Errorf("role %s (%d of %d): %w",
+					role.Name, i+1, len(s.Roles), err)
+			}
+		}
 	}
 
 	return nil
@@ -79,6 +121,8 @@ const (
 	AuthNone   = "none"
 	AuthAPIKey = "apikey"
 	AuthBasic  = "basic"
+	AuthJWT    = "jwt"
+	AuthMTLS   = "mtls"
 )
 
 // Role contains the role name, authentication method name and
@@ -87,7 +131,8 @@ type Role struct {
 	// Name is the role name and is only used for documentation
 	// and in the authentication middleware debug logs.
 	Name string `json:"name"`
-	// Auth is the authentication method to use, which can be 'none', 'basic' or 'apikey'.
+	// Auth is the authentication method to use, which can be 'none', 'basic', 'apikey',
+	// 'jwt' or 'mtls'.
 	Auth string `json:"auth"`
 	// APIKey is the API key to use when using the 'apikey' authentication.
 	APIKey string `json:"apikey"`
@@ -95,21 +140,52 @@ type Role struct {
 	Username string `json:"username"`
 	// Password for HTTP Basic authentication method.
 	Password string `json:"password"`
-	// Routes is a list of routes that the role can access in the format
-	// "HTTP_METHOD PATH", for example "GET /v1/vpn/status"
+	// JWTSecret is the shared secret to verify HS256 signed bearer tokens
+	// when using the 'jwt' authentication.
+	JWTSecret string `json:"jwt_secret" toml:"jwt_secret"`
+	// JWTJWKSFile is the path to a local JSON Web Key Set file containing
+	// the keys to verify HS256, RS256 and EdDSA signed bearer tokens
+	// when using the 'jwt' authentication.
+	JWTJWKSFile string `json:"jwt_jwks_file" toml:"jwt_jwks_file"`
+	// JWTIssuer is the issuer the token 'iss' claim must match.
+	// It is ignored if empty.
+	JWTIssuer string `json:"jwt_issuer" toml:"jwt_issuer"`
+	// JWTAudience is the audience the token 'aud' claim must contain.
+	// It is ignored if empty.
+	JWTAudience string `json:"jwt_audience" toml:"jwt_audience"`
+	// JWTClaims maps claim names to the value they must have, or contain
+	// if the claim is an array, for the token to give access to the role routes.
+	JWTClaims map[string]string `json:"jwt_claims" toml:"jwt_claims"`
+	// MTLSCommonName is the subject common name or DNS subject alternative
+	// name the client certificate must have when using the 'mtls' authentication.
+	// The client certificate must be signed by the control server TLS client CA.
+	MTLSCommonName string `json:"mtls_common_name" toml:"mtls_common_name"`
+	// RateLimit is the maximum number of requests per minute
+	// allowed for the role, shared by all its clients.
+	// It defaults to 0, meaning no rate limit.
+	RateLimit uint `json:"rate_limit" toml:"rate_limit"`
+	// Routes is a list of route patterns that the role can access in the format
+	// "HTTP_METHOD PATH", for example "GET /v1/vpn/status". The method can be `*`
+	// to match any method, and the path can contain `*` to match any sequence
+	// of characters, for example "GET /v1/*" or "* /v1/portforward".
 	Routes []string `json:"-"`
+	// Presets is a list of preset names the role can access the routes of,
+	// which can be 'read-only', 'read-write' or a user defined preset.
+	Presets []string `json:"-"`
 }
 
 var (
-	ErrMethodNotSupported = errors.New("authentication method not supported")
-	ErrAPIKeyEmpty        = errors.New("api key is empty")
-	ErrBasicUsernameEmpty = errors.New("username is empty")
-	ErrBasicPasswordEmpty = errors.New("password is empty")
-	ErrRouteNotSupported  = errors.New("route not supported by the control server")
+	ErrMethodNotSupported  = errors.New("authentication method not supported")
+	ErrAPIKeyEmpty         = errors.New("api key is empty")
+	ErrBasicUsernameEmpty  = errors.New("username is empty")
+	ErrBasicPasswordEmpty  = errors.New("password is empty")
+	ErrJWTKeyMissing       = errors.New("JWT secret and JWKS file are both empty")
+	ErrMTLSCommonNameEmpty = errors.New("mTLS common name is empty")
+	ErrRouteNotSupported   = errors.New("route not supported by the control server")
 )
 
 func (r Role) Validate() (err error) {
-	err = validate.IsOneOf(r.Auth, AuthNone, AuthAPIKey, AuthBasic)
+	err = validate.IsOneOf(r.Auth, AuthNone, AuthAPIKey, AuthBasic, AuthJWT, AuthMTLS)
 	if err != nil {
 		return fmt.Errorf("%w: %s", ErrMethodNotSupported, r.Auth)
 	}
@@ -121,13 +197,16 @@ func (r Role) Validate() (err error) {
 		return fmt.Errorf("for role %s: %w", r.Name, ErrBasicUsernameEmpty)
 	case r.Auth == AuthBasic && r.Password == "":
 		return fmt.Errorf("for role %s: %w", r.Name, ErrBasicPasswordEmpty)
+	case r.Auth == AuthJWT && r.JWTSecret == "" && r.JWTJWKSFile == "":
+		return fmt.Errorf("for role %s: %w", r.Name, ErrJWTKeyMissing)
+	case r.Auth == AuthMTLS && r.MTLSCommonName == "":
+		return fmt.Errorf("for role %s: %w", r.Name, ErrMTLSCommonNameEmpty)
 	}
 
 	for i, route := range r.Routes {
-		_, ok := validRoutes[route]
-		if !ok {
-			return fmt.Errorf("route %d of %d: %w: %s",
-				i+1, len(r.Routes), ErrRouteNotSupported, route)
+		_, err = expandRoutePattern(route)
+		if err != nil {
+			return fmt.Errorf("route %d of %d: %w", i+1, len(r.Routes), err)
 		}
 	}
 
@@ -146,16 +225,29 @@ var validRoutes = map[string]struct{}{ //nolint:gochecknoglobals
 	http.MethodPut + " /v1/vpn/status":            {},
 	http.MethodGet + " /v1/vpn/settings":          {},
 	http.MethodPut + " /v1/vpn/settings":          {},
+	http.MethodPut + " /v1/vpn/rotate":            {},
 	http.MethodGet + " /v1/openvpn/status":        {},
 	http.MethodPut + " /v1/openvpn/status":        {},
 	http.MethodGet + " /v1/openvpn/portforwarded": {},
 	http.MethodGet + " /v1/openvpn/settings":      {},
 	http.MethodGet + " /v1/dns/status":            {},
 	http.MethodPut + " /v1/dns/status":            {},
+	http.MethodGet + " /v1/dns/stats":             {},
+	http.MethodGet + " /v1/servers":               {},
+	http.MethodGet + " /v1/servers/choices":       {},
+	http.MethodGet + " /v1/servers/blacklist":     {},
+	http.MethodDelete + " /v1/servers/blacklist":  {},
 	http.MethodGet + " /v1/updater/status":        {},
 	http.MethodPut + " /v1/updater/status":        {},
+	http.MethodGet + " /v1/updater/changes":       {},
+	http.MethodPost + " /v1/updater/run":          {},
 	http.MethodGet + " /v1/publicip/ip":           {},
 	http.MethodGet + " /v1/portforward":           {},
+	http.MethodGet + " /v1/events":                {},
+	http.MethodPost + " /v1/leaktest":             {},
+	http.MethodGet + " /v1/socks5/status":         {},
+	http.MethodPut + " /v1/socks5/status":         {},
+	http.MethodGet + " /metrics":                  {},
 }
 
 func (r Role) ToLinesNode() (node *gotree.Node) {
@@ -168,9 +260,34 @@ func (r Role) ToLinesNode() (node *gotree.Node) {
 		node.Appendf("Password: %s", gosettings.ObfuscateKey(r.Password))
 	case AuthAPIKey:
 		node.Appendf("API key: %s", gosettings.ObfuscateKey(r.APIKey))
+	case AuthJWT:
+		if r.JWTSecret != "" {
+			node.Appendf("JWT secret: %s", gosettings.ObfuscateKey(r.JWTSecret))
+		}
+		if r.JWTJWKSFile != "" {
+			node.Appendf("JWKS file: %s", r.JWTJWKSFile)
+		}
+		if r.JWTIssuer != "" {
+			node.Appendf("JWT issuer: %s", r.JWTIssuer)
+		}
+		if r.JWTAudience != "" {
+			node.Appendf("JWT audience: %s", r.JWTAudience)
+		}
+		if len(r.JWTClaims) > 0 {
+			claimsNode := node.Appendf("JWT required claims:")
+			claimNames := slices.Sorted(maps.Keys(r.JWTClaims))
+			for _, name := range claimNames {
+				claimsNode.Appendf("%s: %s", name, r.JWTClaims[name])
+			}
+		}
+	case AuthMTLS:
+		node.Appendf("Client certificate common name: %s", r.MTLSCommonName)
 	default:
 		panic("missing code for authentication method: " + r.Auth)
 	}
+	if r.RateLimit > 0 {
+		node.Appendf("Rate limit: %d requests per minute", r.RateLimit)
+	}
 	node.Appendf("Number of routes covered: %d", len(r.Routes))
 	return node
 }
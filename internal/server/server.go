@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/httpserver"
@@ -12,29 +13,44 @@ import (
 	"github.com/qdm12/gluetun/internal/server/middlewares/auth"
 )
 
+var ErrMTLSRoleWithoutClientCA = errors.New("mtls authentication requires a TLS client CA file")
+
 func New(ctx context.Context, settings settings.ControlServer, logger Logger,
 	buildInfo models.BuildInformation, openvpnLooper VPNLooper,
 	pfGetter PortForwardedGetter, dnsLooper DNSLoop,
-	updaterLooper UpdaterLooper, publicIPLooper PublicIPLoop, storage Storage,
+	updaterLooper UpdaterLooper, publicIPLooper PublicIPLoop,
+	healthChecker HealthChecker, httpProxyLooper HTTPProxyLoop,
+	shadowsocksLooper ShadowsocksLoop, socks5Looper Socks5Loop,
+	eventSubscriber EventSubscriber,
+	leakTester LeakTester,
+	storage Storage,
 	ipv6Supported bool) (
 	server *httpserver.Server, err error,
 ) {
-	authSettings, err := setupAuthMiddleware(settings.AuthFilePath, settings.AuthDefaultRole, logger)
+	authSettings, err := readAuthSettings(settings, logger)
 	if err != nil {
 		return nil, fmt.Errorf("building authentication middleware settings: %w", err)
 	}
 
-	handler, err := newHandler(ctx, logger, *settings.Log, authSettings, buildInfo,
+	authMiddleware, err := auth.New(authSettings, logger)
+	if err != nil {
+		return nil, fmt.Errorf("creating auth middleware: %w", err)
+	}
+
+	handler, err := newHandler(ctx, logger, *settings.Log, authMiddleware, buildInfo,
 		openvpnLooper, pfGetter, dnsLooper, updaterLooper, publicIPLooper,
-		storage, ipv6Supported)
+		healthChecker, httpProxyLooper, shadowsocksLooper, socks5Looper, eventSubscriber, leakTester, storage, ipv6Supported)
 	if err != nil {
 		return nil, fmt.Errorf("creating handler: %w", err)
 	}
 
 	httpServerSettings := httpserver.Settings{
-		Address: *settings.Address,
-		Handler: handler,
-		Logger:  logger,
+		Address:         *settings.Address,
+		Handler:         handler,
+		Logger:          logger,
+		TLSCertPath:     settings.TLSCertPath,
+		TLSKeyPath:      settings.TLSKeyPath,
+		TLSClientCAPath: settings.TLSClientCAPath,
 	}
 
 	server, err = httpserver.New(httpServerSettings)
@@ -42,13 +58,18 @@ func New(ctx context.Context, settings settings.ControlServer, logger Logger,
 		return nil, fmt.Errorf("creating server: %w", err)
 	}
 
+	reloader := newAuthReloader(settings, authSettings, authMiddleware, logger)
+	go reloader.run(ctx)
+
 	return server, nil
 }
 
-func setupAuthMiddleware(authPath, jsonDefaultRole string, logger Logger) (
+// readAuthSettings reads, validates and logs the authentication settings
+// from the control server authentication file and default role.
+func readAuthSettings(settings settings.ControlServer, logger Logger) (
 	authSettings auth.Settings, err error,
 ) {
-	authSettings, err = auth.Read(authPath)
+	authSettings, err = auth.Read(settings.AuthFilePath)
 	switch {
 	case errors.Is(err, os.ErrNotExist): // no auth file present
 	case err != nil:
@@ -56,7 +77,7 @@ func setupAuthMiddleware(authPath, jsonDefaultRole string, logger Logger) (
 	default:
 		logger.Infof("read %d roles from authentication file", len(authSettings.Roles))
 	}
-	err = authSettings.SetDefaultRole(jsonDefaultRole)
+	err = authSettings.SetDefaultRole(settings.AuthDefaultRole)
 	if err != nil {
 		return auth.Settings{}, fmt.Errorf("setting default role: %w", err)
 	}
@@ -64,5 +85,27 @@ func setupAuthMiddleware(authPath, jsonDefaultRole string, logger Logger) (
 	if err != nil {
 		return auth.Settings{}, fmt.Errorf("validating auth settings: %w", err)
 	}
+
+	if settings.TLSClientCAPath == "" {
+		for _, role := range authSettings.Roles {
+			if role.Auth == auth.AuthMTLS {
+				return auth.Settings{}, fmt.Errorf("%w: for role %s", ErrMTLSRoleWithoutClientCA, role.Name)
+			}
+		}
+	}
+
+	expansions, err := authSettings.Expansions()
+	if err != nil {
+		return auth.Settings{}, fmt.Errorf("expanding auth routes: %w", err)
+	}
+	for _, expansion := range expansions {
+		isExactRoute := len(expansion.Routes) == 1 && expansion.Routes[0] == expansion.Pattern
+		if isExactRoute {
+			continue
+		}
+		logger.Infof("role %s: %s expands to %s", expansion.Role,
+			expansion.Pattern, strings.Join(expansion.Routes, ", "))
+	}
+
 	return authSettings, nil
 }
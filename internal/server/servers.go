@@ -0,0 +1,272 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/storage"
+)
+
+func newServersHandler(looper VPNLooper, storage Storage,
+	warner warner,
+) http.Handler {
+	return &serversHandler{
+		looper:  looper,
+		storage: storage,
+		warner:  warner,
+	}
+}
+
+type serversHandler struct {
+	looper  VPNLooper
+	storage Storage
+	warner  warner
+}
+
+func (h *serversHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.RequestURI = strings.TrimPrefix(r.RequestURI, "/servers")
+	path, _, _ := strings.Cut(r.RequestURI, "?")
+	switch path {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			h.getServers(w, r)
+		default:
+			errMethodNotSupported(w, r.Method)
+		}
+	case "/choices":
+		switch r.Method {
+		case http.MethodGet:
+			h.getChoices(w, r)
+		default:
+			errMethodNotSupported(w, r.Method)
+		}
+	case "/blacklist":
+		switch r.Method {
+		case http.MethodGet:
+			h.getBlacklist(w)
+		case http.MethodDelete:
+			h.clearBlacklist(w, r)
+		default:
+			errMethodNotSupported(w, r.Method)
+		}
+	default:
+		errRouteNotSupported(w, r.RequestURI)
+	}
+}
+
+// getServers writes the servers matching the filters given as query
+// parameters. The provider, VPN type and OpenVPN protocol default to
+// the ones currently set in the VPN settings. Location filters such
+// as country accept comma separated values.
+func (h *serversHandler) getServers(w http.ResponseWriter, r *http.Request) {
+	provider, selection, err := h.parseSelection(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	servers, err := h.storage.FilterServers(provider, selection)
+	if err != nil && !errors.Is(err, storage.ErrNoServerFound) {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if servers == nil {
+		servers = []models.Server{}
+	}
+
+	encoder := json.NewEncoder(w)
+	data := struct {
+		Servers []models.Server `json:"servers"`
+	}{Servers: servers}
+	if err := encoder.Encode(data); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// getChoices writes the possible filter values for the provider given
+// as query parameter, defaulting to the current VPN provider.
+func (h *serversHandler) getChoices(w http.ResponseWriter, r *http.Request) {
+	provider, err := h.parseProvider(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	choices := h.storage.GetFilterChoices(provider)
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(choices); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// getBlacklist writes the server IP addresses currently
+// excluded from the server selection due to recent failures.
+func (h *serversHandler) getBlacklist(w http.ResponseWriter) {
+	blacklisted := h.storage.GetBlacklist()
+	if blacklisted == nil {
+		blacklisted = []models.BlacklistedServer{}
+	}
+
+	encoder := json.NewEncoder(w)
+	data := struct {
+		Servers []models.BlacklistedServer `json:"servers"`
+	}{Servers: blacklisted}
+	if err := encoder.Encode(data); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// clearBlacklist removes the failures of the server IP address
+// given as the ip query parameter, or of all servers if no IP
+// address is given.
+func (h *serversHandler) clearBlacklist(w http.ResponseWriter, r *http.Request) {
+	var ip netip.Addr
+	if ipString := r.URL.Query().Get("ip"); ipString != "" {
+		var err error
+		ip, err = netip.ParseAddr(ipString)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	removed, err := h.storage.ClearBlacklist(ip)
+	if err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	outcome := fmt.Sprintf("removed %d server failures", removed)
+	if err := encoder.Encode(outcomeWrapper{Outcome: outcome}); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+var (
+	errProviderNotValid    = errors.New("provider is not valid")
+	errVPNTypeNotValid     = errors.New("VPN type is not valid")
+	errProtocolNotValid    = errors.New("protocol is not valid")
+	errQueryBoolNotValid   = errors.New("query parameter is not a valid boolean")
+	errQueryNumberNotValid = errors.New("query parameter is not a valid number")
+)
+
+func (h *serversHandler) parseProvider(query url.Values) (provider string, err error) {
+	provider = query.Get("provider")
+	if provider == "" {
+		return h.looper.GetSettings().Provider.Name, nil
+	}
+
+	provider = strings.ToLower(provider)
+	if !slices.Contains(providers.AllWithCustom(), provider) {
+		return "", fmt.Errorf("%w: %s", errProviderNotValid, provider)
+	}
+	return provider, nil
+}
+
+func (h *serversHandler) parseSelection(query url.Values) (
+	provider string, selection settings.ServerSelection, err error,
+) {
+	provider, err = h.parseProvider(query)
+	if err != nil {
+		return "", selection, err
+	}
+
+	currentSelection := h.looper.GetSettings().Provider.ServerSelection
+	selection = settings.ServerSelection{
+		VPN:        currentSelection.VPN,
+		Countries:  queryCSV(query, "country"),
+		Categories: queryCSV(query, "category"),
+		Regions:    queryCSV(query, "region"),
+		Cities:     queryCSV(query, "city"),
+		ISPs:       queryCSV(query, "isp"),
+		Names:      queryCSV(query, "name"),
+		Hostnames:  queryCSV(query, "hostname"),
+		OpenVPN: settings.OpenVPNSelection{
+			Protocol: currentSelection.OpenVPN.Protocol,
+		},
+	}
+
+	if vpnType := query.Get("vpn"); vpnType != "" {
+		if vpnType != vpn.OpenVPN && vpnType != vpn.Wireguard {
+			return "", selection, fmt.Errorf("%w: %s", errVPNTypeNotValid, vpnType)
+		}
+		selection.VPN = vpnType
+	}
+
+	if protocol := query.Get("protocol"); protocol != "" {
+		if protocol != constants.TCP && protocol != constants.UDP {
+			return "", selection, fmt.Errorf("%w: %s", errProtocolNotValid, protocol)
+		}
+		selection.OpenVPN.Protocol = protocol
+	}
+
+	for _, number := range queryCSV(query, "number") {
+		const base, bitSize = 10, 16
+		parsed, err := strconv.ParseUint(number, base, bitSize)
+		if err != nil {
+			return "", selection, fmt.Errorf("%w: number: %s", errQueryNumberNotValid, number)
+		}
+		selection.Numbers = append(selection.Numbers, uint16(parsed))
+	}
+
+	boolFilters := map[string]**bool{
+		"owned_only":        &selection.OwnedOnly,
+		"free_only":         &selection.FreeOnly,
+		"stream_only":       &selection.StreamOnly,
+		"multi_hop_only":    &selection.MultiHopOnly,
+		"port_forward_only": &selection.PortForwardOnly,
+		"secure_core_only":  &selection.SecureCoreOnly,
+		"tor_only":          &selection.TorOnly,
+	}
+	for key, field := range boolFilters {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", selection, fmt.Errorf("%w: %s: %s", errQueryBoolNotValid, key, value)
+		}
+		*field = &parsed
+	}
+
+	return provider, selection.WithDefaults(provider), nil
+}
+
+// queryCSV returns the comma separated values of the query
+// parameter key, which can also be repeated.
+func queryCSV(query url.Values, key string) (values []string) {
+	for _, value := range query[key] {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field != "" {
+				values = append(values, field)
+			}
+		}
+	}
+	return values
+}
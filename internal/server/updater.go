@@ -3,10 +3,13 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants/providers"
 	"github.com/qdm12/gluetun/internal/models"
 )
 
@@ -15,29 +18,36 @@ type UpdaterLooper interface {
 	SetStatus(ctx context.Context, status models.LoopStatus) (
 		outcome string, err error)
 	SetSettings(settings settings.Updater) (outcome string)
+	UpdateProviders(ctx context.Context, providers []string) (
+		outcome string, err error)
+	GetProgress() (progress models.UpdaterProgress)
 }
 
 func newUpdaterHandler(
 	ctx context.Context,
 	looper UpdaterLooper,
+	storage Storage,
 	warner warner,
 ) http.Handler {
 	return &updaterHandler{
-		ctx:    ctx,
-		looper: looper,
-		warner: warner,
+		ctx:     ctx,
+		looper:  looper,
+		storage: storage,
+		warner:  warner,
 	}
 }
 
 type updaterHandler struct {
-	ctx    context.Context //nolint:containedctx
-	looper UpdaterLooper
-	warner warner
+	ctx     context.Context //nolint:containedctx
+	looper  UpdaterLooper
+	storage Storage
+	warner  warner
 }
 
 func (h *updaterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	r.RequestURI = strings.TrimPrefix(r.RequestURI, "/updater")
-	switch r.RequestURI {
+	path, _, _ := strings.Cut(r.RequestURI, "?")
+	switch path {
 	case "/status":
 		switch r.Method {
 		case http.MethodGet:
@@ -47,6 +57,20 @@ func (h *updaterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		default:
 			errMethodNotSupported(w, r.Method)
 		}
+	case "/run":
+		switch r.Method {
+		case http.MethodPost:
+			h.run(w, r)
+		default:
+			errMethodNotSupported(w, r.Method)
+		}
+	case "/changes":
+		switch r.Method {
+		case http.MethodGet:
+			h.getChanges(w, r)
+		default:
+			errMethodNotSupported(w, r.Method)
+		}
 	default:
 		errRouteNotSupported(w, r.RequestURI)
 	}
@@ -55,7 +79,13 @@ func (h *updaterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (h *updaterHandler) getStatus(w http.ResponseWriter) {
 	status := h.looper.GetStatus()
 	encoder := json.NewEncoder(w)
-	data := statusWrapper{Status: string(status)}
+	data := struct {
+		Status   string                 `json:"status"`
+		Progress models.UpdaterProgress `json:"progress"`
+	}{
+		Status:   string(status),
+		Progress: h.looper.GetProgress(),
+	}
 	if err := encoder.Encode(data); err != nil {
 		h.warner.Warn(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -87,3 +117,57 @@ func (h *updaterHandler) setStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// run starts updating the servers of the providers
+// given in the request body.
+func (h *updaterHandler) run(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	var data struct {
+		Providers []string `json:"providers"`
+	}
+	if err := decoder.Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i := range data.Providers {
+		data.Providers[i] = strings.ToLower(data.Providers[i])
+	}
+
+	outcome, err := h.looper.UpdateProviders(h.ctx, data.Providers)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(outcomeWrapper{Outcome: outcome}); err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// getChanges writes the most recent servers changes made by the
+// updater, for the provider given as query parameter or for all
+// providers if no provider is given.
+func (h *updaterHandler) getChanges(w http.ResponseWriter, r *http.Request) {
+	provider := strings.ToLower(r.URL.Query().Get("provider"))
+	if provider != "" && !slices.Contains(providers.All(), provider) {
+		http.Error(w, fmt.Sprintf("%s: %s", errProviderNotValid, provider), http.StatusBadRequest)
+		return
+	}
+
+	changes := h.storage.GetServersChanges(provider)
+	if changes == nil {
+		changes = []models.ServersChange{}
+	}
+
+	encoder := json.NewEncoder(w)
+	data := struct {
+		Changes []models.ServersChange `json:"changes"`
+	}{Changes: changes}
+	if err := encoder.Encode(data); err != nil {
+		h.warner.Warn(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
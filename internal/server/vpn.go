@@ -50,6 +50,13 @@ func (h *vpnHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		default:
 			errMethodNotSupported(w, r.Method)
 		}
+	case "/rotate":
+		switch r.Method {
+		case http.MethodPut:
+			h.rotate(w)
+		default:
+			errMethodNotSupported(w, r.Method)
+		}
 	default:
 		errRouteNotSupported(w, r.RequestURI)
 	}
@@ -91,6 +98,20 @@ func (h *vpnHandler) setStatus(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *vpnHandler) rotate(w http.ResponseWriter) {
+	outcome, err := h.looper.Rotate(h.ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(outcomeWrapper{Outcome: outcome}); err != nil {
+		h.warner.Warn(err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (h *vpnHandler) getSettings(w http.ResponseWriter) {
 	settings := h.looper.GetSettings()
 	encoder := json.NewEncoder(w)
@@ -0,0 +1,116 @@
+package socks5
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+)
+
+// Address types, see RFC 1928 section 5.
+const (
+	addressTypeIPv4   byte = 1
+	addressTypeDomain byte = 3
+	addressTypeIPv6   byte = 4
+)
+
+// address is a SOCKS5 address, which is either
+// an IP address or a domain name, and a port.
+type address struct {
+	ip     netip.Addr
+	domain string
+	port   uint16
+}
+
+func (a address) String() string {
+	host := a.domain
+	if a.ip.IsValid() {
+		host = a.ip.String()
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(a.port)))
+}
+
+func addressFromAddrPort(addrPort netip.AddrPort) address {
+	return address{
+		ip:   addrPort.Addr().Unmap(),
+		port: addrPort.Port(),
+	}
+}
+
+var (
+	errAddressTypeNotSupported = errors.New("address type is not supported")
+	errDomainNameEmpty         = errors.New("domain name is empty")
+)
+
+// readAddress reads an address type, address and port from the reader.
+func readAddress(reader io.Reader) (addr address, err error) {
+	var addressType [1]byte
+	_, err = io.ReadFull(reader, addressType[:])
+	if err != nil {
+		return addr, fmt.Errorf("reading address type: %w", err)
+	}
+
+	switch addressType[0] {
+	case addressTypeIPv4:
+		var ip [4]byte
+		_, err = io.ReadFull(reader, ip[:])
+		if err != nil {
+			return addr, fmt.Errorf("reading IPv4 address: %w", err)
+		}
+		addr.ip = netip.AddrFrom4(ip)
+	case addressTypeIPv6:
+		var ip [16]byte
+		_, err = io.ReadFull(reader, ip[:])
+		if err != nil {
+			return addr, fmt.Errorf("reading IPv6 address: %w", err)
+		}
+		addr.ip = netip.AddrFrom16(ip).Unmap()
+	case addressTypeDomain:
+		var length [1]byte
+		_, err = io.ReadFull(reader, length[:])
+		if err != nil {
+			return addr, fmt.Errorf("reading domain name length: %w", err)
+		} else if length[0] == 0 {
+			return addr, errDomainNameEmpty
+		}
+		domain := make([]byte, length[0])
+		_, err = io.ReadFull(reader, domain)
+		if err != nil {
+			return addr, fmt.Errorf("reading domain name: %w", err)
+		}
+		addr.domain = string(domain)
+	default:
+		return addr, fmt.Errorf("%w: %d", errAddressTypeNotSupported, addressType[0])
+	}
+
+	var port [2]byte
+	_, err = io.ReadFull(reader, port[:])
+	if err != nil {
+		return addr, fmt.Errorf("reading port: %w", err)
+	}
+	addr.port = binary.BigEndian.Uint16(port[:])
+
+	return addr, nil
+}
+
+// appendAddress appends the address type, address and port to b.
+// An invalid address is encoded as the IPv4 unspecified address.
+func appendAddress(b []byte, addr address) []byte {
+	switch {
+	case addr.domain != "":
+		b = append(b, addressTypeDomain, byte(len(addr.domain)))
+		b = append(b, addr.domain...)
+	case addr.ip.Is6():
+		b = append(b, addressTypeIPv6)
+		b = append(b, addr.ip.AsSlice()...)
+	case addr.ip.Is4():
+		b = append(b, addressTypeIPv4)
+		b = append(b, addr.ip.AsSlice()...)
+	default:
+		b = append(b, addressTypeIPv4, 0, 0, 0, 0)
+	}
+	return binary.BigEndian.AppendUint16(b, addr.port)
+}
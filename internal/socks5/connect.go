@@ -0,0 +1,87 @@
+package socks5
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+func (s *Server) handleConnect(ctx context.Context, clientConn net.Conn,
+	destination address,
+) (err error) {
+	const dialTimeout = 10 * time.Second
+	dialer := net.Dialer{Timeout: dialTimeout}
+	targetConn, err := dialer.DialContext(ctx, "tcp", destination.String())
+	if err != nil {
+		_ = writeReply(clientConn, dialErrorToReplyCode(err), address{})
+		return fmt.Errorf("connecting to %s for %s: %w",
+			destination, clientConn.RemoteAddr(), err)
+	}
+	defer targetConn.Close()
+	stopClosing := context.AfterFunc(ctx, func() {
+		_ = targetConn.Close()
+	})
+	defer stopClosing()
+
+	bound := address{}
+	if tcpAddr, ok := targetConn.LocalAddr().(*net.TCPAddr); ok {
+		bound = addressFromAddrPort(tcpAddr.AddrPort())
+	}
+	err = writeReply(clientConn, replySucceeded, bound)
+	if err != nil {
+		return fmt.Errorf("writing reply to %s: %w", clientConn.RemoteAddr(), err)
+	}
+
+	s.logConnection("CONNECT " + destination.String() + " from " + clientConn.RemoteAddr().String())
+
+	relay(clientConn, targetConn)
+	return nil
+}
+
+// relay copies data between the two connections in both directions,
+// until both directions are done.
+func relay(a, b net.Conn) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(a, b)
+		closeWrite(a)
+	}()
+	_, _ = io.Copy(b, a)
+	closeWrite(b)
+	<-done
+}
+
+// closeWrite shuts down the writing side of the connection if
+// supported, to signal the end of the data stream to the peer.
+func closeWrite(conn net.Conn) {
+	type writeCloser interface {
+		CloseWrite() error
+	}
+	if conn, ok := conn.(writeCloser); ok {
+		_ = conn.CloseWrite()
+		return
+	}
+	_ = conn.Close()
+}
+
+func dialErrorToReplyCode(err error) (replyCode byte) {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return replyConnectionRefused
+	case errors.Is(err, syscall.ENETUNREACH):
+		return replyNetworkUnreachable
+	case errors.Is(err, syscall.EHOSTUNREACH),
+		errors.As(err, &dnsErr),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return replyHostUnreachable
+	default:
+		return replyGeneralFailure
+	}
+}
@@ -0,0 +1,182 @@
+package socks5
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+const socksVersion byte = 5
+
+// Authentication methods, see RFC 1928 section 3.
+const (
+	methodNoAuthentication    byte = 0
+	methodUsernamePassword    byte = 2
+	methodNoAcceptableMethods byte = 0xff
+)
+
+// Username/password authentication, see RFC 1929 section 2.
+const (
+	authVersion       byte = 1
+	authStatusSuccess byte = 0
+	authStatusFailure byte = 1
+)
+
+// Commands, see RFC 1928 section 4.
+const (
+	commandConnect      byte = 1
+	commandUDPAssociate byte = 3
+)
+
+// Reply codes, see RFC 1928 section 6.
+const (
+	replySucceeded               byte = 0
+	replyGeneralFailure          byte = 1
+	replyNetworkUnreachable      byte = 3
+	replyHostUnreachable         byte = 4
+	replyConnectionRefused       byte = 5
+	replyCommandNotSupported     byte = 7
+	replyAddressTypeNotSupported byte = 8
+)
+
+var (
+	errVersionNotSupported     = errors.New("SOCKS version is not supported")
+	errNoAcceptableMethod      = errors.New("no acceptable authentication method")
+	errAuthVersionNotSupported = errors.New("authentication version is not supported")
+	errCredentialsMismatch     = errors.New("username or password mismatch")
+)
+
+// negotiateAuthentication reads the client greeting, selects an
+// authentication method and authenticates the client if needed.
+func negotiateAuthentication(rw io.ReadWriter, username, password string) (err error) {
+	var header [2]byte
+	_, err = io.ReadFull(rw, header[:])
+	if err != nil {
+		return fmt.Errorf("reading greeting header: %w", err)
+	}
+	version, methodsCount := header[0], header[1]
+	if version != socksVersion {
+		return fmt.Errorf("%w: %d", errVersionNotSupported, version)
+	}
+
+	methods := make([]byte, methodsCount)
+	_, err = io.ReadFull(rw, methods)
+	if err != nil {
+		return fmt.Errorf("reading authentication methods: %w", err)
+	}
+
+	method := methodNoAuthentication
+	if username != "" {
+		method = methodUsernamePassword
+	}
+
+	if !slices.Contains(methods, method) {
+		_, _ = rw.Write([]byte{socksVersion, methodNoAcceptableMethods})
+		return fmt.Errorf("%w: client offered methods %v", errNoAcceptableMethod, methods)
+	}
+
+	_, err = rw.Write([]byte{socksVersion, method})
+	if err != nil {
+		return fmt.Errorf("writing selected method: %w", err)
+	}
+
+	if method == methodNoAuthentication {
+		return nil
+	}
+	return authenticate(rw, username, password)
+}
+
+func authenticate(rw io.ReadWriter, username, password string) (err error) {
+	var version [1]byte
+	_, err = io.ReadFull(rw, version[:])
+	if err != nil {
+		return fmt.Errorf("reading authentication version: %w", err)
+	} else if version[0] != authVersion {
+		return fmt.Errorf("%w: %d", errAuthVersionNotSupported, version[0])
+	}
+
+	clientUsername, err := readLengthPrefixed(rw)
+	if err != nil {
+		return fmt.Errorf("reading username: %w", err)
+	}
+
+	clientPassword, err := readLengthPrefixed(rw)
+	if err != nil {
+		return fmt.Errorf("reading password: %w", err)
+	}
+
+	usernameMatch := subtle.ConstantTimeCompare(clientUsername, []byte(username)) == 1
+	passwordMatch := subtle.ConstantTimeCompare(clientPassword, []byte(password)) == 1
+	if !usernameMatch || !passwordMatch {
+		_, _ = rw.Write([]byte{authVersion, authStatusFailure})
+		return fmt.Errorf("%w: for username %q", errCredentialsMismatch, clientUsername)
+	}
+
+	_, err = rw.Write([]byte{authVersion, authStatusSuccess})
+	if err != nil {
+		return fmt.Errorf("writing authentication status: %w", err)
+	}
+	return nil
+}
+
+func readLengthPrefixed(reader io.Reader) (data []byte, err error) {
+	var length [1]byte
+	_, err = io.ReadFull(reader, length[:])
+	if err != nil {
+		return nil, fmt.Errorf("reading length: %w", err)
+	}
+	data = make([]byte, length[0])
+	_, err = io.ReadFull(reader, data)
+	if err != nil {
+		return nil, fmt.Errorf("reading data: %w", err)
+	}
+	return data, nil
+}
+
+type request struct {
+	command     byte
+	destination address
+}
+
+var errReservedFieldNotZero = errors.New("reserved field is not zero")
+
+// readRequest reads the client request. If an error is returned,
+// the reply code to send to the client is returned as well.
+func readRequest(reader io.Reader) (req request, replyCode byte, err error) {
+	var header [3]byte
+	_, err = io.ReadFull(reader, header[:])
+	if err != nil {
+		return req, replyGeneralFailure, fmt.Errorf("reading request header: %w", err)
+	}
+
+	version, command, reserved := header[0], header[1], header[2]
+	switch {
+	case version != socksVersion:
+		return req, replyGeneralFailure, fmt.Errorf("%w: %d", errVersionNotSupported, version)
+	case reserved != 0:
+		return req, replyGeneralFailure, fmt.Errorf("%w: %d", errReservedFieldNotZero, reserved)
+	}
+	req.command = command
+
+	req.destination, err = readAddress(reader)
+	if err != nil {
+		replyCode = replyGeneralFailure
+		if errors.Is(err, errAddressTypeNotSupported) {
+			replyCode = replyAddressTypeNotSupported
+		}
+		return req, replyCode, fmt.Errorf("reading destination address: %w", err)
+	}
+
+	return req, replySucceeded, nil
+}
+
+func writeReply(writer io.Writer, replyCode byte, bound address) (err error) {
+	const maxReplyLength = 4 + 1 + 255 + 2
+	reply := make([]byte, 0, maxReplyLength)
+	reply = append(reply, socksVersion, replyCode, 0)
+	reply = appendAddress(reply, bound)
+	_, err = writer.Write(reply)
+	return err
+}
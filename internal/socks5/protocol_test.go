@@ -0,0 +1,160 @@
+package socks5
+
+import (
+	"bytes"
+	"io"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type readWriter struct {
+	io.Reader
+	io.Writer
+}
+
+func Test_negotiateAuthentication(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		input      []byte
+		username   string
+		password   string
+		written    []byte
+		errWrapped error
+		errMessage string
+	}{
+		"no_authentication": {
+			input:   []byte{5, 1, methodNoAuthentication},
+			written: []byte{5, methodNoAuthentication},
+		},
+		"version_not_supported": {
+			input:      []byte{4, 1, methodNoAuthentication},
+			errWrapped: errVersionNotSupported,
+			errMessage: "SOCKS version is not supported: 4",
+		},
+		"no_acceptable_method": {
+			input:      []byte{5, 1, methodNoAuthentication},
+			username:   "user",
+			password:   "pass",
+			written:    []byte{5, methodNoAcceptableMethods},
+			errWrapped: errNoAcceptableMethod,
+			errMessage: "no acceptable authentication method: client offered methods [0]",
+		},
+		"credentials_valid": {
+			input: append([]byte{5, 2, methodNoAuthentication, methodUsernamePassword,
+				authVersion, 4}, []byte("user\x04pass")...),
+			username: "user",
+			password: "pass",
+			written:  []byte{5, methodUsernamePassword, authVersion, authStatusSuccess},
+		},
+		"credentials_mismatch": {
+			input: append([]byte{5, 1, methodUsernamePassword,
+				authVersion, 4}, []byte("user\x05wrong")...),
+			username:   "user",
+			password:   "pass",
+			written:    []byte{5, methodUsernamePassword, authVersion, authStatusFailure},
+			errWrapped: errCredentialsMismatch,
+			errMessage: `username or password mismatch: for username "user"`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			written := bytes.NewBuffer(nil)
+			rw := readWriter{
+				Reader: bytes.NewReader(testCase.input),
+				Writer: written,
+			}
+
+			err := negotiateAuthentication(rw, testCase.username, testCase.password)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.written, written.Bytes())
+		})
+	}
+}
+
+func Test_readRequest(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		input      []byte
+		request    request
+		replyCode  byte
+		errWrapped error
+	}{
+		"connect_ipv4": {
+			input: []byte{5, commandConnect, 0, addressTypeIPv4, 1, 2, 3, 4, 0x01, 0xbb},
+			request: request{
+				command: commandConnect,
+				destination: address{
+					ip:   netip.AddrFrom4([4]byte{1, 2, 3, 4}),
+					port: 443,
+				},
+			},
+		},
+		"connect_domain": {
+			input: append(append([]byte{5, commandConnect, 0, addressTypeDomain, 11},
+				[]byte("example.com")...), 0, 80),
+			request: request{
+				command: commandConnect,
+				destination: address{
+					domain: "example.com",
+					port:   80,
+				},
+			},
+		},
+		"address_type_not_supported": {
+			input:      []byte{5, commandConnect, 0, 2},
+			request:    request{command: commandConnect},
+			replyCode:  replyAddressTypeNotSupported,
+			errWrapped: errAddressTypeNotSupported,
+		},
+		"reserved_not_zero": {
+			input:      []byte{5, commandConnect, 1},
+			replyCode:  replyGeneralFailure,
+			errWrapped: errReservedFieldNotZero,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, replyCode, err := readRequest(bytes.NewReader(testCase.input))
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.Equal(t, testCase.request, req)
+			assert.Equal(t, testCase.replyCode, replyCode)
+		})
+	}
+}
+
+func Test_parseUDPDatagram(t *testing.T) {
+	t.Parallel()
+
+	destination := address{
+		ip:   netip.AddrFrom16([16]byte{15: 1}),
+		port: 53,
+	}
+	datagram := appendAddress([]byte{0, 0, 0}, destination)
+	datagram = append(datagram, []byte("payload")...)
+
+	parsed, payload, err := parseUDPDatagram(datagram)
+
+	require.NoError(t, err)
+	assert.Equal(t, destination, parsed)
+	assert.Equal(t, []byte("payload"), payload)
+
+	datagram[2] = 1 // fragment number
+	_, _, err = parseUDPDatagram(datagram)
+	assert.ErrorIs(t, err, errFragmentNotSupported)
+}
@@ -0,0 +1,122 @@
+package socks5
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+type Server struct {
+	address  string
+	logger   Logger
+	verbose  bool
+	username string
+	password string
+	udp      bool
+}
+
+func New(address string, logger Logger, verbose bool,
+	username, password string, udp bool,
+) *Server {
+	return &Server{
+		address:  address,
+		logger:   logger,
+		verbose:  verbose,
+		username: username,
+		password: password,
+		udp:      udp,
+	}
+}
+
+func (s *Server) Run(ctx context.Context, errorCh chan<- error) {
+	listenConfig := net.ListenConfig{}
+	listener, err := listenConfig.Listen(ctx, "tcp", s.address)
+	if err != nil {
+		errorCh <- fmt.Errorf("listening: %w", err)
+		return
+	}
+	stopClosing := context.AfterFunc(ctx, func() {
+		_ = listener.Close()
+	})
+	defer stopClosing()
+
+	s.logger.Info("listening on " + listener.Addr().String())
+
+	wg := &sync.WaitGroup{}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			_ = listener.Close()
+			wg.Wait()
+			if ctx.Err() != nil {
+				errorCh <- nil
+			} else {
+				errorCh <- fmt.Errorf("accepting connection: %w", err)
+			}
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handleConn(ctx, conn)
+		}()
+	}
+}
+
+func (s *Server) handleConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	// Close the connection when the server is stopped
+	stopClosing := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stopClosing()
+
+	clientAddress := conn.RemoteAddr().String()
+
+	const handshakeTimeout = 10 * time.Second
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+
+	err := negotiateAuthentication(conn, s.username, s.password)
+	if err != nil {
+		s.logger.Info("authentication failed for " + clientAddress + ": " + err.Error())
+		return
+	}
+
+	req, replyCode, err := readRequest(conn)
+	if err != nil {
+		_ = writeReply(conn, replyCode, address{})
+		s.logger.Debug("reading request from " + clientAddress + ": " + err.Error())
+		return
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	switch req.command {
+	case commandConnect:
+		err = s.handleConnect(ctx, conn, req.destination)
+	case commandUDPAssociate:
+		if !s.udp {
+			_ = writeReply(conn, replyCommandNotSupported, address{})
+			s.logger.Debug("UDP associate request from " + clientAddress + " refused since UDP is disabled")
+			return
+		}
+		err = s.handleUDPAssociate(ctx, conn, req.destination)
+	default:
+		_ = writeReply(conn, replyCommandNotSupported, address{})
+		s.logger.Debug(fmt.Sprintf("command %d from %s is not supported", req.command, clientAddress))
+		return
+	}
+
+	if err != nil && ctx.Err() == nil {
+		s.logger.Debug(err.Error())
+	}
+}
+
+func (s *Server) logConnection(message string) {
+	if s.verbose {
+		s.logger.Info(message)
+	}
+}
@@ -0,0 +1,150 @@
+package socks5
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string) {}
+func (noopLogger) Info(string)  {}
+func (noopLogger) Warn(string)  {}
+func (noopLogger) Error(string) {}
+
+// startTestServer serves SOCKS5 connections on a local listener
+// and returns its address.
+func startTestServer(t *testing.T, server *Server) (address string) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.handleConn(ctx, conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func dialSocks5(t *testing.T, address string, command byte,
+	destination netip.AddrPort,
+) (conn net.Conn, bound netip.AddrPort) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(time.Second))
+
+	_, err = conn.Write([]byte{socksVersion, 1, methodNoAuthentication})
+	require.NoError(t, err)
+	selected := make([]byte, 2)
+	_, err = io.ReadFull(conn, selected)
+	require.NoError(t, err)
+	require.Equal(t, []byte{socksVersion, methodNoAuthentication}, selected)
+
+	req := []byte{socksVersion, command, 0}
+	req = appendAddress(req, addressFromAddrPort(destination))
+	_, err = conn.Write(req)
+	require.NoError(t, err)
+
+	header := make([]byte, 3)
+	_, err = io.ReadFull(conn, header)
+	require.NoError(t, err)
+	require.Equal(t, []byte{socksVersion, replySucceeded, 0}, header)
+	boundAddress, err := readAddress(conn)
+	require.NoError(t, err)
+
+	_ = conn.SetDeadline(time.Time{})
+	return conn, netip.AddrPortFrom(boundAddress.ip, boundAddress.port)
+}
+
+func Test_Server_connect(t *testing.T) {
+	t.Parallel()
+
+	echoListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = echoListener.Close() })
+	go func() {
+		conn, err := echoListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	server := New("", noopLogger{}, false, "", "", false)
+	address := startTestServer(t, server)
+
+	echoAddress := echoListener.Addr().(*net.TCPAddr).AddrPort() //nolint:forcetypeassert
+	conn, _ := dialSocks5(t, address, commandConnect, echoAddress)
+
+	_ = conn.SetDeadline(time.Now().Add(time.Second))
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	received := make([]byte, len("hello"))
+	_, err = io.ReadFull(conn, received)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(received))
+}
+
+func Test_Server_udpAssociate(t *testing.T) {
+	t.Parallel()
+
+	echoConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = echoConn.Close() })
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, source, err := echoConn.ReadFromUDPAddrPort(buffer)
+			if err != nil {
+				return
+			}
+			_, _ = echoConn.WriteToUDPAddrPort(buffer[:n], source)
+		}
+	}()
+
+	server := New("", noopLogger{}, false, "", "", true)
+	address := startTestServer(t, server)
+
+	_, relayAddress := dialSocks5(t, address, commandUDPAssociate, netip.AddrPort{})
+
+	clientConn, err := net.DialUDP("udp", nil, net.UDPAddrFromAddrPort(relayAddress))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientConn.Close() })
+	_ = clientConn.SetDeadline(time.Now().Add(time.Second))
+
+	echoAddress := addressFromAddrPort(echoConn.LocalAddr().(*net.UDPAddr).AddrPort()) //nolint:forcetypeassert
+	datagram := appendAddress([]byte{0, 0, 0}, echoAddress)
+	datagram = append(datagram, []byte("hello")...)
+	_, err = clientConn.Write(datagram)
+	require.NoError(t, err)
+
+	buffer := make([]byte, 1024)
+	n, err := clientConn.Read(buffer)
+	require.NoError(t, err)
+	source, payload, err := parseUDPDatagram(buffer[:n])
+	require.NoError(t, err)
+	assert.Equal(t, echoAddress, source)
+	assert.Equal(t, "hello", string(payload))
+}
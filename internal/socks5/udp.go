@@ -0,0 +1,226 @@
+package socks5
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"sync"
+)
+
+// handleUDPAssociate relays UDP datagrams between the client and
+// destinations, for as long as the client TCP connection stays open.
+// See RFC 1928 section 7.
+func (s *Server) handleUDPAssociate(ctx context.Context, clientConn net.Conn,
+	expectedSource address,
+) (err error) {
+	clientTCPAddr, ok := clientConn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		_ = writeReply(clientConn, replyGeneralFailure, address{})
+		return fmt.Errorf("%w: %s", errClientAddressNotTCP, clientConn.RemoteAddr())
+	}
+	localTCPAddr, ok := clientConn.LocalAddr().(*net.TCPAddr)
+	if !ok {
+		_ = writeReply(clientConn, replyGeneralFailure, address{})
+		return fmt.Errorf("%w: %s", errClientAddressNotTCP, clientConn.LocalAddr())
+	}
+
+	// Listen on the same IP address the client reached us on.
+	relayConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: localTCPAddr.IP})
+	if err != nil {
+		_ = writeReply(clientConn, replyGeneralFailure, address{})
+		return fmt.Errorf("listening for UDP datagrams from client: %w", err)
+	}
+	defer relayConn.Close()
+
+	targetConn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		_ = writeReply(clientConn, replyGeneralFailure, address{})
+		return fmt.Errorf("listening for UDP datagrams from destinations: %w", err)
+	}
+	defer targetConn.Close()
+
+	bound := addressFromAddrPort(relayConn.LocalAddr().(*net.UDPAddr).AddrPort()) //nolint:forcetypeassert
+	err = writeReply(clientConn, replySucceeded, bound)
+	if err != nil {
+		return fmt.Errorf("writing reply to %s: %w", clientConn.RemoteAddr(), err)
+	}
+
+	s.logConnection("UDP ASSOCIATE from " + clientConn.RemoteAddr().String() +
+		" relaying on " + bound.String())
+
+	association := &udpAssociation{
+		clientIP:  clientTCPAddr.AddrPort().Addr().Unmap(),
+		relayConn: relayConn,
+		target:    targetConn,
+		logger:    s.logger,
+	}
+	if expectedSource.ip.IsValid() && !expectedSource.ip.IsUnspecified() &&
+		expectedSource.port != 0 {
+		association.clientAddr = netip.AddrPortFrom(expectedSource.ip, expectedSource.port)
+	}
+
+	wg := &sync.WaitGroup{}
+	const goroutines = 2
+	wg.Add(goroutines)
+	go func() {
+		defer wg.Done()
+		association.relayFromClient(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		association.relayToClient()
+	}()
+
+	// The association terminates when the TCP connection closes.
+	_, _ = io.Copy(io.Discard, clientConn)
+	_ = relayConn.Close()
+	_ = targetConn.Close()
+	wg.Wait()
+	return nil
+}
+
+var errClientAddressNotTCP = errors.New("client address is not a TCP address")
+
+type udpAssociation struct {
+	clientIP     netip.Addr
+	clientAddr   netip.AddrPort
+	clientAddrMu sync.RWMutex
+	relayConn    *net.UDPConn
+	target       *net.UDPConn
+	logger       Logger
+}
+
+func (a *udpAssociation) getClientAddr() (clientAddr netip.AddrPort) {
+	a.clientAddrMu.RLock()
+	defer a.clientAddrMu.RUnlock()
+	return a.clientAddr
+}
+
+// checkSource returns true if the datagram source is the association client.
+// The first datagram received from the client IP address sets the client port
+// if it was not specified in the request.
+func (a *udpAssociation) checkSource(source netip.AddrPort) (ok bool) {
+	source = netip.AddrPortFrom(source.Addr().Unmap(), source.Port())
+	if source.Addr() != a.clientIP {
+		return false
+	}
+	a.clientAddrMu.Lock()
+	defer a.clientAddrMu.Unlock()
+	if !a.clientAddr.IsValid() {
+		a.clientAddr = source
+		return true
+	}
+	return a.clientAddr == source
+}
+
+func (a *udpAssociation) relayFromClient(ctx context.Context) {
+	const maxDatagramSize = 65535
+	buffer := make([]byte, maxDatagramSize)
+	for {
+		n, source, err := a.relayConn.ReadFromUDPAddrPort(buffer)
+		if err != nil {
+			return
+		}
+
+		if !a.checkSource(source) {
+			continue
+		}
+
+		destination, payload, err := parseUDPDatagram(buffer[:n])
+		if err != nil {
+			a.logger.Debug("dropping UDP datagram from " + source.String() + ": " + err.Error())
+			continue
+		}
+
+		destinationAddr, err := resolveUDPDestination(ctx, destination)
+		if err != nil {
+			a.logger.Debug("dropping UDP datagram from " + source.String() + ": " + err.Error())
+			continue
+		}
+
+		_, err = a.target.WriteToUDPAddrPort(payload, destinationAddr)
+		if err != nil {
+			a.logger.Debug("relaying UDP datagram to " + destinationAddr.String() + ": " + err.Error())
+		}
+	}
+}
+
+func (a *udpAssociation) relayToClient() {
+	const maxDatagramSize = 65535
+	buffer := make([]byte, maxDatagramSize)
+	const maxHeaderSize = 3 + 1 + 16 + 2
+	datagram := make([]byte, 0, maxHeaderSize+maxDatagramSize)
+	for {
+		n, source, err := a.target.ReadFromUDPAddrPort(buffer)
+		if err != nil {
+			return
+		}
+
+		clientAddr := a.getClientAddr()
+		if !clientAddr.IsValid() {
+			continue
+		}
+
+		datagram = append(datagram[:0], 0, 0, 0) // reserved and fragment fields
+		datagram = appendAddress(datagram, addressFromAddrPort(source))
+		datagram = append(datagram, buffer[:n]...)
+		_, err = a.relayConn.WriteToUDPAddrPort(datagram, clientAddr)
+		if err != nil {
+			a.logger.Debug("relaying UDP datagram to client " + clientAddr.String() + ": " + err.Error())
+		}
+	}
+}
+
+var (
+	errDatagramTooShort     = errors.New("datagram is too short")
+	errFragmentNotSupported = errors.New("fragmentation is not supported")
+	errNoIPAddressForDomain = errors.New("no IP address found for domain")
+)
+
+// parseUDPDatagram parses a client UDP datagram made of a header
+// and a payload, as described in RFC 1928 section 7.
+func parseUDPDatagram(datagram []byte) (destination address, payload []byte, err error) {
+	const minHeaderSize = 3
+	if len(datagram) < minHeaderSize {
+		return destination, nil, fmt.Errorf("%w: %d bytes", errDatagramTooShort, len(datagram))
+	}
+
+	reserved := datagram[0:2]
+	if reserved[0] != 0 || reserved[1] != 0 {
+		return destination, nil, fmt.Errorf("%w: %v", errReservedFieldNotZero, reserved)
+	}
+
+	fragment := datagram[2]
+	if fragment != 0 {
+		return destination, nil, fmt.Errorf("%w: fragment number %d", errFragmentNotSupported, fragment)
+	}
+
+	reader := bytes.NewReader(datagram[minHeaderSize:])
+	destination, err = readAddress(reader)
+	if err != nil {
+		return destination, nil, fmt.Errorf("reading destination address: %w", err)
+	}
+
+	payload = datagram[len(datagram)-reader.Len():]
+	return destination, payload, nil
+}
+
+func resolveUDPDestination(ctx context.Context, destination address) (
+	addrPort netip.AddrPort, err error,
+) {
+	if destination.ip.IsValid() {
+		return netip.AddrPortFrom(destination.ip, destination.port), nil
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", destination.domain)
+	if err != nil {
+		return addrPort, fmt.Errorf("resolving %s: %w", destination.domain, err)
+	} else if len(ips) == 0 {
+		return addrPort, fmt.Errorf("%w: %s", errNoIPAddressForDomain, destination.domain)
+	}
+	return netip.AddrPortFrom(ips[0].Unmap(), destination.port), nil
+}
@@ -0,0 +1,19 @@
+package splittunnel
+
+import (
+	"context"
+	"net/netip"
+)
+
+type Firewall interface {
+	SetOutboundSubnets(ctx context.Context, subnets []netip.Prefix) (err error)
+}
+
+type Routing interface {
+	SetOutboundRoutes(outboundSubnets []netip.Prefix) error
+}
+
+type Logger interface {
+	Debug(s string)
+	Error(s string)
+}
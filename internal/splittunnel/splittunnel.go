@@ -0,0 +1,205 @@
+// Package splittunnel routes traffic to IP addresses resolved for
+// particular domain names outside the VPN tunnel, in addition to the
+// static outbound subnets.
+package splittunnel
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minTTL is the minimum time an IP address is routed outside the
+// VPN tunnel after being resolved, such that connections established
+// right after resolution are not cut if the record TTL is small.
+const minTTL = 5 * time.Minute
+
+type SplitTunnel struct {
+	// Fixed injected fields
+	fqdns         []string
+	parents       []string
+	staticSubnets []netip.Prefix
+	firewall      Firewall
+	routing       Routing
+	logger        Logger
+	timeNow       func() time.Time
+
+	// Mutable fields
+	addrToExpiry map[netip.Addr]time.Time
+	mutex        sync.Mutex
+}
+
+// New creates a split tunnel for the domains given, which can be
+// fully qualified domain names or parent domains of the form
+// "*.example.com" matching all subdomains of example.com.
+// The static subnets are always kept as outbound subnets, and
+// resolved IP addresses contained in them are ignored.
+func New(domains []string, staticSubnets []netip.Prefix,
+	firewall Firewall, routing Routing, logger Logger,
+) *SplitTunnel {
+	var fqdns, parents []string
+	for _, domain := range domains {
+		domain = normalizeDomain(domain)
+		parent, isParent := strings.CutPrefix(domain, "*.")
+		if isParent {
+			parents = append(parents, parent)
+		} else {
+			fqdns = append(fqdns, domain)
+		}
+	}
+
+	return &SplitTunnel{
+		fqdns:         fqdns,
+		parents:       parents,
+		staticSubnets: slices.Clone(staticSubnets),
+		firewall:      firewall,
+		routing:       routing,
+		logger:        logger,
+		timeNow:       time.Now,
+		addrToExpiry:  make(map[netip.Addr]time.Time),
+	}
+}
+
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
+
+// Matches returns true if the domain name given, which can be
+// a fully qualified domain name ending with a dot, matches one
+// of the split tunnel domains.
+func (s *SplitTunnel) Matches(name string) bool {
+	if len(s.fqdns) == 0 && len(s.parents) == 0 {
+		return false
+	}
+
+	name = normalizeDomain(name)
+	if slices.Contains(s.fqdns, name) {
+		return true
+	}
+	for _, parent := range s.parents {
+		if strings.HasSuffix(name, "."+parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add routes the IP addresses given outside the VPN tunnel until the
+// ttl given elapses, with a minimum of 5 minutes. Adding an address
+// already present extends its expiry. Unspecified, loopback and
+// addresses already contained in static outbound subnets are ignored.
+// The firewall and routing are updated before this function returns.
+func (s *SplitTunnel) Add(ctx context.Context, addrs []netip.Addr,
+	ttl time.Duration,
+) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expiry := s.timeNow().Add(max(ttl, minTTL))
+	changed := false
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		if addr.IsUnspecified() || addr.IsLoopback() ||
+			s.inStaticSubnets(addr) {
+			continue
+		}
+
+		existingExpiry, exists := s.addrToExpiry[addr]
+		switch {
+		case !exists:
+			s.logger.Debug("routing " + addr.String() + " outside the VPN tunnel")
+			changed = true
+		case existingExpiry.After(expiry):
+			continue
+		}
+		s.addrToExpiry[addr] = expiry
+	}
+
+	if !changed {
+		return nil
+	}
+	return s.apply(ctx)
+}
+
+func (s *SplitTunnel) inStaticSubnets(addr netip.Addr) bool {
+	for _, subnet := range s.staticSubnets {
+		if subnet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run removes expired IP addresses periodically,
+// until the context is canceled.
+func (s *SplitTunnel) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	if len(s.fqdns) == 0 && len(s.parents) == 0 {
+		<-ctx.Done()
+		return
+	}
+
+	const period = 30 * time.Second
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.removeExpired(ctx)
+			if err != nil {
+				s.logger.Error(err.Error())
+			}
+		}
+	}
+}
+
+func (s *SplitTunnel) removeExpired(ctx context.Context) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.timeNow()
+	changed := false
+	for addr, expiry := range s.addrToExpiry {
+		if now.Before(expiry) {
+			continue
+		}
+		s.logger.Debug("routing " + addr.String() + " back through the VPN tunnel")
+		delete(s.addrToExpiry, addr)
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return s.apply(ctx)
+}
+
+// apply sets the outbound subnets in the firewall and routing
+// to the static subnets and the IP addresses resolved.
+// It must be called with the mutex locked.
+func (s *SplitTunnel) apply(ctx context.Context) (err error) {
+	subnets := make([]netip.Prefix, 0, len(s.staticSubnets)+len(s.addrToExpiry))
+	subnets = append(subnets, s.staticSubnets...)
+	for addr := range s.addrToExpiry {
+		subnets = append(subnets, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	err = s.firewall.SetOutboundSubnets(ctx, subnets)
+	if err != nil {
+		return fmt.Errorf("setting firewall outbound subnets: %w", err)
+	}
+
+	err = s.routing.SetOutboundRoutes(subnets)
+	if err != nil {
+		return fmt.Errorf("setting outbound routes: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package splittunnel
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SplitTunnel_Matches(t *testing.T) {
+	t.Parallel()
+
+	splitTunnel := New([]string{"Example.com", "*.example.org"},
+		nil, nil, nil, nil)
+
+	testCases := map[string]bool{
+		"example.com":      true,
+		"EXAMPLE.com.":     true,
+		"sub.example.com":  false,
+		"example.org.":     false,
+		"a.example.org.":   true,
+		"a.b.example.org":  true,
+		"notexample.org":   false,
+		"example.org.evil": false,
+	}
+
+	for name, expected := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, expected, splitTunnel.Matches(name))
+		})
+	}
+}
+
+type fakeOutbound struct {
+	subnets []netip.Prefix
+	calls   int
+}
+
+func (f *fakeOutbound) SetOutboundSubnets(_ context.Context, subnets []netip.Prefix) error {
+	f.subnets = subnets
+	f.calls++
+	return nil
+}
+
+func (f *fakeOutbound) SetOutboundRoutes(subnets []netip.Prefix) error {
+	f.subnets = subnets
+	f.calls++
+	return nil
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string) {}
+func (noopLogger) Error(string) {}
+
+func Test_SplitTunnel_Add_removeExpired(t *testing.T) {
+	t.Parallel()
+
+	firewall := &fakeOutbound{}
+	routing := &fakeOutbound{}
+	staticSubnet := netip.MustParsePrefix("10.0.0.0/8")
+	splitTunnel := New([]string{"example.com"}, []netip.Prefix{staticSubnet},
+		firewall, routing, noopLogger{})
+	now := time.Unix(1700000000, 0)
+	splitTunnel.timeNow = func() time.Time { return now }
+	ctx := context.Background()
+
+	err := splitTunnel.Add(ctx, []netip.Addr{
+		netip.MustParseAddr("1.2.3.4"),
+		netip.MustParseAddr("10.1.2.3"), // in static subnet
+		netip.MustParseAddr("127.0.0.1"),
+	}, time.Hour)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []netip.Prefix{
+		staticSubnet, netip.MustParsePrefix("1.2.3.4/32"),
+	}, firewall.subnets)
+	assert.Equal(t, firewall.subnets, routing.subnets)
+
+	// Short TTL for an existing address does not
+	// reduce its expiry nor update the firewall.
+	err = splitTunnel.Add(ctx, []netip.Addr{netip.MustParseAddr("1.2.3.4")}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, firewall.calls)
+
+	err = splitTunnel.Add(ctx, []netip.Addr{netip.MustParseAddr("::1:2")}, time.Second)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []netip.Prefix{
+		staticSubnet,
+		netip.MustParsePrefix("1.2.3.4/32"),
+		netip.MustParsePrefix("::1:2/128"),
+	}, firewall.subnets)
+
+	// IPv6 address expires after the minimum TTL
+	now = now.Add(minTTL)
+	err = splitTunnel.removeExpired(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []netip.Prefix{
+		staticSubnet, netip.MustParsePrefix("1.2.3.4/32"),
+	}, firewall.subnets)
+
+	now = now.Add(time.Hour)
+	err = splitTunnel.removeExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{staticSubnet}, firewall.subnets)
+	assert.Equal(t, firewall.subnets, routing.subnets)
+}
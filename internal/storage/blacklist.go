@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// blacklist records VPN server failures to exclude server IP
+// addresses with recent failures from the servers filtered.
+// The failures are persisted to a file so they survive restarts.
+type blacklist struct {
+	// duration is the duration failures are kept for, and
+	// the duration a server IP address is blacklisted for
+	// after its last failure. It is 0 if the blacklist is disabled.
+	duration    time.Duration
+	maxFailures uint
+	filepath    string
+	failures    []models.ServerFailure
+	timeNow     func() time.Time
+	mutex       sync.RWMutex
+}
+
+type blacklistKey struct {
+	provider string
+	ip       netip.Addr
+}
+
+type blacklistData struct {
+	Failures []models.ServerFailure `json:"failures"`
+}
+
+// SetBlacklistSettings enables the server blacklist if duration is not
+// zero, with server IP addresses being blacklisted after maxFailures
+// failures within the duration. The failures are read from and written
+// to the file blacklist.json in the directory of the servers file.
+func (s *Storage) SetBlacklistSettings(duration time.Duration, maxFailures uint) (err error) {
+	blacklist := &blacklist{
+		duration:    duration,
+		maxFailures: maxFailures,
+		timeNow:     time.Now,
+	}
+	if s.filepath != "" {
+		blacklist.filepath = filepath.Join(filepath.Dir(s.filepath), "blacklist.json")
+	}
+
+	if duration > 0 {
+		err = blacklist.read()
+		if err != nil {
+			return fmt.Errorf("reading blacklist: %w", err)
+		}
+	}
+
+	s.blacklistMutex.Lock()
+	defer s.blacklistMutex.Unlock()
+	s.blacklist = blacklist
+	return nil
+}
+
+func (s *Storage) getBlacklist() *blacklist {
+	s.blacklistMutex.RLock()
+	defer s.blacklistMutex.RUnlock()
+	return s.blacklist
+}
+
+// RecordServerFailure records the failure of a server,
+// which is ignored if the blacklist is disabled.
+func (s *Storage) RecordServerFailure(failure models.ServerFailure) (err error) {
+	blacklist := s.getBlacklist()
+	if blacklist == nil || blacklist.duration == 0 || !failure.IP.IsValid() {
+		return nil
+	}
+	return blacklist.record(failure)
+}
+
+// GetBlacklist returns the server IP addresses currently blacklisted.
+func (s *Storage) GetBlacklist() (servers []models.BlacklistedServer) {
+	blacklist := s.getBlacklist()
+	if blacklist == nil {
+		return nil
+	}
+	return blacklist.list()
+}
+
+// ClearBlacklist removes the failures of the server IP address given,
+// or of all servers if the IP address is not valid. It returns the
+// number of failures removed.
+func (s *Storage) ClearBlacklist(ip netip.Addr) (removed int, err error) {
+	blacklist := s.getBlacklist()
+	if blacklist == nil {
+		return 0, nil
+	}
+	return blacklist.clear(ip)
+}
+
+func (b *blacklist) read() (err error) {
+	if b.filepath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(b.filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var decoded blacklistData
+	err = json.Unmarshal(data, &decoded)
+	if err != nil {
+		return fmt.Errorf("decoding file: %w", err)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failures = decoded.Failures
+	b.pruneLocked()
+	return nil
+}
+
+func (b *blacklist) record(failure models.ServerFailure) (err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failures = append(b.failures, failure)
+	b.pruneLocked()
+	return b.writeLocked()
+}
+
+func (b *blacklist) clear(ip netip.Addr) (removed int, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	countBefore := len(b.failures)
+	b.failures = slices.DeleteFunc(b.failures, func(failure models.ServerFailure) bool {
+		return !ip.IsValid() || failure.IP == ip
+	})
+	removed = countBefore - len(b.failures)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, b.writeLocked()
+}
+
+// pruneLocked removes failures older than the blacklist duration.
+// It must be called with the mutex locked.
+func (b *blacklist) pruneLocked() {
+	oldest := b.timeNow().Add(-b.duration)
+	b.failures = slices.DeleteFunc(b.failures, func(failure models.ServerFailure) bool {
+		return failure.Time.Before(oldest)
+	})
+}
+
+// writeLocked must be called with the mutex locked.
+func (b *blacklist) writeLocked() (err error) {
+	if b.filepath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(blacklistData{Failures: b.failures}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding blacklist: %w", err)
+	}
+
+	const permission = 0o644
+	err = os.MkdirAll(filepath.Dir(b.filepath), permission)
+	if err != nil {
+		return fmt.Errorf("creating blacklist directory: %w", err)
+	}
+
+	err = os.WriteFile(b.filepath, data, permission)
+	if err != nil {
+		return fmt.Errorf("writing blacklist file: %w", err)
+	}
+	return nil
+}
+
+// blacklistedLocked returns the server IP addresses blacklisted at
+// the current time. It must be called with the mutex at least read locked.
+func (b *blacklist) blacklistedLocked() (keyToServer map[blacklistKey]*models.BlacklistedServer) {
+	now := b.timeNow()
+	oldest := now.Add(-b.duration)
+	keyToFailures := make(map[blacklistKey][]models.ServerFailure)
+	for _, failure := range b.failures {
+		if failure.Time.Before(oldest) {
+			continue
+		}
+		key := blacklistKey{provider: failure.Provider, ip: failure.IP}
+		keyToFailures[key] = append(keyToFailures[key], failure)
+	}
+
+	keyToServer = make(map[blacklistKey]*models.BlacklistedServer)
+	for key, failures := range keyToFailures {
+		if uint(len(failures)) < b.maxFailures {
+			continue
+		}
+		lastFailure := failures[len(failures)-1]
+		keyToServer[key] = &models.BlacklistedServer{
+			Provider: key.provider,
+			Hostname: lastFailure.Hostname,
+			IP:       key.ip,
+			Until:    lastFailure.Time.Add(b.duration),
+			Failures: failures,
+		}
+	}
+	return keyToServer
+}
+
+func (b *blacklist) list() (servers []models.BlacklistedServer) {
+	if b.duration == 0 {
+		return nil
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	keyToServer := b.blacklistedLocked()
+	servers = make([]models.BlacklistedServer, 0, len(keyToServer))
+	for _, server := range keyToServer {
+		servers = append(servers, *server)
+	}
+	slices.SortFunc(servers, func(a, b models.BlacklistedServer) int {
+		return a.Until.Compare(b.Until)
+	})
+	return servers
+}
+
+// filter returns the servers given without their blacklisted IP
+// addresses, removing servers for which all IP addresses are blacklisted.
+func (b *blacklist) filter(provider string, servers []models.Server) (
+	filtered []models.Server,
+) {
+	if b.duration == 0 {
+		return servers
+	}
+
+	b.mutex.RLock()
+	keyToServer := b.blacklistedLocked()
+	b.mutex.RUnlock()
+	if len(keyToServer) == 0 {
+		return servers
+	}
+
+	filtered = make([]models.Server, 0, len(servers))
+	for _, server := range servers {
+		if len(server.IPs) == 0 {
+			filtered = append(filtered, server)
+			continue
+		}
+		server.IPs = slices.DeleteFunc(slices.Clone(server.IPs), func(ip netip.Addr) bool {
+			_, blacklisted := keyToServer[blacklistKey{provider: provider, ip: ip}]
+			return blacklisted
+		})
+		if len(server.IPs) > 0 {
+			filtered = append(filtered, server)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_blacklist(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0).UTC()
+	blacklistPath := filepath.Join(t.TempDir(), "blacklist.json")
+	newBlacklist := func() *blacklist {
+		return &blacklist{
+			duration:    time.Hour,
+			maxFailures: 2,
+			filepath:    blacklistPath,
+			timeNow:     func() time.Time { return now },
+		}
+	}
+
+	ipA := netip.MustParseAddr("1.1.1.1")
+	ipB := netip.MustParseAddr("2.2.2.2")
+	servers := []models.Server{
+		{Hostname: "a", IPs: []netip.Addr{ipA, ipB}},
+		{Hostname: "b", IPs: []netip.Addr{ipB}},
+	}
+
+	b := newBlacklist()
+	failures := []models.ServerFailure{
+		{Provider: "x", IP: ipB, Time: now.Add(-2 * time.Hour), Type: models.ServerFailureConnection},
+		{Provider: "x", IP: ipB, Time: now.Add(-time.Minute), Type: models.ServerFailureConnection},
+		{Provider: "y", IP: ipB, Time: now.Add(-time.Minute), Type: models.ServerFailureHealthcheck},
+	}
+	for _, failure := range failures {
+		err := b.record(failure)
+		require.NoError(t, err)
+	}
+
+	// The oldest failure is pruned, so ipB is not yet blacklisted.
+	assert.Empty(t, b.list())
+	assert.Equal(t, servers, b.filter("x", servers))
+
+	err := b.record(models.ServerFailure{Provider: "x", Hostname: "b", IP: ipB, Time: now})
+	require.NoError(t, err)
+
+	// Failures are persisted and read back.
+	b = newBlacklist()
+	err = b.read()
+	require.NoError(t, err)
+
+	blacklisted := b.list()
+	require.Len(t, blacklisted, 1)
+	assert.Equal(t, "b", blacklisted[0].Hostname)
+	assert.Equal(t, ipB, blacklisted[0].IP)
+	assert.Equal(t, now.Add(time.Hour), blacklisted[0].Until)
+	assert.Len(t, blacklisted[0].Failures, 2)
+
+	expectedServers := []models.Server{{Hostname: "a", IPs: []netip.Addr{ipA}}}
+	assert.Equal(t, expectedServers, b.filter("x", servers))
+	assert.Equal(t, servers, b.filter("y", servers))
+	// The servers given are not mutated.
+	assert.Equal(t, []netip.Addr{ipA, ipB}, servers[0].IPs)
+
+	removed, err := b.clear(ipB)
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed)
+	assert.Empty(t, b.list())
+}
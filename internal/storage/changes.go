@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// maxServersChanges is the maximum number of changes
+// kept for each provider.
+const maxServersChanges = 10
+
+// GetServers returns a deep copy of the servers
+// for the provider given.
+func (s *Storage) GetServers(provider string) (servers []models.Server) {
+	if provider == providers.Custom {
+		return nil
+	}
+
+	s.mergedMutex.RLock()
+	defer s.mergedMutex.RUnlock()
+
+	serversObject := s.getMergedServersObject(provider)
+	servers = make([]models.Server, len(serversObject.Servers))
+	for i, server := range serversObject.Servers {
+		servers[i] = copyServer(server)
+	}
+	return servers
+}
+
+// AddServersChange adds the servers change given to the changes
+// of its provider, keeping only the most recent changes, and
+// saves all the servers to file.
+func (s *Storage) AddServersChange(change models.ServersChange) (err error) {
+	if change.Provider == providers.Custom {
+		return nil
+	}
+
+	s.mergedMutex.Lock()
+	defer s.mergedMutex.Unlock()
+
+	serversObject := s.getMergedServersObject(change.Provider)
+	serversObject.Changes = append(serversObject.Changes, change)
+	if len(serversObject.Changes) > maxServersChanges {
+		serversObject.Changes = serversObject.Changes[len(serversObject.Changes)-maxServersChanges:]
+	}
+	s.mergedServers.ProviderToServers[change.Provider] = serversObject
+
+	err = s.flushToFile(s.filepath)
+	if err != nil {
+		return fmt.Errorf("saving servers to file: %w", err)
+	}
+	return nil
+}
+
+// GetServersChanges returns the servers changes for the provider
+// given, or for all providers if the provider is empty, sorted
+// from newest to oldest.
+func (s *Storage) GetServersChanges(provider string) (changes []models.ServersChange) {
+	if provider == providers.Custom {
+		return nil
+	}
+
+	s.mergedMutex.RLock()
+	defer s.mergedMutex.RUnlock()
+
+	if provider != "" {
+		serversObject := s.getMergedServersObject(provider)
+		changes = append(changes, serversObject.Changes...)
+	} else {
+		for _, serversObject := range s.mergedServers.ProviderToServers {
+			changes = append(changes, serversObject.Changes...)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Timestamp > changes[j].Timestamp
+	})
+	return changes
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// ExportServers returns the servers of the providers given
+// encoded as JSON, in the same format as the servers file.
+// The servers changes recorded by the updater are not exported.
+func (s *Storage) ExportServers(providerNames []string) (data []byte, err error) {
+	s.mergedMutex.RLock()
+	defer s.mergedMutex.RUnlock()
+
+	exported := models.AllServers{
+		Version:           s.mergedServers.Version,
+		ProviderToServers: make(map[string]models.Servers, len(providerNames)),
+	}
+	for _, provider := range providerNames {
+		if provider == providers.Custom {
+			continue
+		}
+		serversObject := s.getMergedServersObject(provider)
+		serversObject.Servers = slices.Clone(serversObject.Servers)
+		sort.Sort(models.SortableServers(serversObject.Servers))
+		serversObject.Changes = nil
+		exported.ProviderToServers[provider] = serversObject
+	}
+
+	data, err = json.Marshal(&exported)
+	if err != nil {
+		return nil, fmt.Errorf("encoding servers: %w", err)
+	}
+	return data, nil
+}
+
+// ImportServers merges the servers encoded as JSON with the servers
+// in storage, using the same rules as when merging the servers file
+// with the hardcoded servers: servers with a version different from
+// the hardcoded servers version are ignored, more recent servers
+// replace the current servers and otherwise only imported servers
+// marked to be kept are merged. All the servers are then saved to file.
+// It returns the providers found in the data given.
+func (s *Storage) ImportServers(data []byte) (importedProviders []string, err error) {
+	hardcodedVersions := make(map[string]uint16, len(s.hardcodedServers.ProviderToServers))
+	for provider, servers := range s.hardcodedServers.ProviderToServers {
+		hardcodedVersions[provider] = servers.Version
+	}
+
+	imported, err := s.extractServersFromBytes(data, hardcodedVersions)
+	if err != nil {
+		return nil, fmt.Errorf("extracting servers: %w", err)
+	}
+
+	s.mergedMutex.Lock()
+	defer s.mergedMutex.Unlock()
+
+	for provider, importedServers := range imported.ProviderToServers {
+		current := s.getMergedServersObject(provider)
+		merged := s.mergeProviderServers(provider, current, importedServers)
+		merged.Changes = current.Changes
+		s.mergedServers.ProviderToServers[provider] = merged
+		importedProviders = append(importedProviders, provider)
+	}
+	sort.Strings(importedProviders)
+
+	err = s.flushToFile(s.filepath)
+	if err != nil {
+		return nil, fmt.Errorf("saving servers to file: %w", err)
+	}
+	return importedProviders, nil
+}
@@ -44,6 +44,15 @@ func (s *Storage) FilterServers(provider string, selection settings.ServerSelect
 		return nil, noServerFoundError(selection)
 	}
 
+	if blacklist := s.getBlacklist(); blacklist != nil {
+		notBlacklisted := blacklist.filter(provider, servers)
+		if len(notBlacklisted) == 0 {
+			s.logger.Warn("all filtered servers are blacklisted, ignoring the blacklist")
+		} else {
+			servers = notBlacklisted
+		}
+	}
+
 	return servers, nil
 }
 
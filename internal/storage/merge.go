@@ -56,6 +56,7 @@ func (s *Storage) mergeProviderServers(provider string,
 	}
 
 	merged = hardcoded // use all fields from hardcoded
+	merged.Changes = persisted.Changes
 	merged.Servers = make([]models.Server, 0, len(hardcoded.Servers)+len(persistedServerKeyToServer))
 
 	for _, hardcodedServer := range hardcoded.Servers {
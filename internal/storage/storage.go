@@ -15,6 +15,9 @@ type Storage struct {
 	hardcodedServers models.AllServers
 	logger           Logger
 	filepath         string
+	// blacklist is nil until SetBlacklistSettings is called.
+	blacklist      *blacklist
+	blacklistMutex sync.RWMutex
 }
 
 type Logger interface {
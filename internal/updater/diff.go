@@ -0,0 +1,99 @@
+package updater
+
+import (
+	"maps"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// diffServers returns the servers added, removed and changed between
+// the old and new servers of a provider. Servers are matched using
+// their key, with a numbered suffix for servers sharing the same key.
+func diffServers(oldServers, newServers []models.Server) (change models.ServersChange) {
+	oldKeyToServer := keyServers(oldServers)
+	newKeyToServer := keyServers(newServers)
+
+	for _, key := range slices.Sorted(maps.Keys(newKeyToServer)) {
+		newServer := newKeyToServer[key]
+		oldServer, ok := oldKeyToServer[key]
+		if !ok {
+			change.Added = append(change.Added, key)
+			continue
+		}
+		serverChange, changed := diffServer(key, oldServer, newServer)
+		if changed {
+			change.Changed = append(change.Changed, serverChange)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(oldKeyToServer)) {
+		if _, ok := newKeyToServer[key]; !ok {
+			change.Removed = append(change.Removed, key)
+		}
+	}
+
+	return change
+}
+
+func keyServers(servers []models.Server) (keyToServer map[string]models.Server) {
+	keyToServer = make(map[string]models.Server, len(servers))
+	keyToCount := make(map[string]int, len(servers))
+	for _, server := range servers {
+		key := server.Key()
+		if server.Hostname == "" && server.ServerName != "" {
+			key += server.ServerName
+		}
+		keyToCount[key]++
+		if count := keyToCount[key]; count > 1 {
+			key += "#" + strconv.Itoa(count)
+		}
+		keyToServer[key] = server
+	}
+	return keyToServer
+}
+
+func diffServer(key string, oldServer, newServer models.Server) (
+	change models.ServerChange, changed bool,
+) {
+	change.Key = key
+	change.IPsAdded = ipsDifference(newServer.IPs, oldServer.IPs)
+	change.IPsRemoved = ipsDifference(oldServer.IPs, newServer.IPs)
+
+	oldValue := reflect.ValueOf(oldServer)
+	newValue := reflect.ValueOf(newServer)
+	serverType := oldValue.Type()
+	for i := range serverType.NumField() {
+		field := serverType.Field(i)
+		if field.Name == "IPs" {
+			continue
+		}
+		if reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		change.Fields = append(change.Fields, name)
+	}
+
+	changed = len(change.IPsAdded) > 0 || len(change.IPsRemoved) > 0 ||
+		len(change.Fields) > 0
+	return change, changed
+}
+
+// ipsDifference returns the IP addresses of a not present in b.
+func ipsDifference(a, b []netip.Addr) (difference []netip.Addr) {
+	bSet := make(map[netip.Addr]struct{}, len(b))
+	for _, ip := range b {
+		bSet[ip] = struct{}{}
+	}
+	for _, ip := range a {
+		if _, ok := bSet[ip]; !ok {
+			difference = append(difference, ip)
+		}
+	}
+	return difference
+}
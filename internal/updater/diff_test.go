@@ -0,0 +1,83 @@
+package updater
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_diffServers(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		oldServers []models.Server
+		newServers []models.Server
+		change     models.ServersChange
+	}{
+		"no_servers": {},
+		"same_servers": {
+			oldServers: []models.Server{
+				{VPN: "openvpn", UDP: true, Hostname: "a", IPs: []netip.Addr{netip.MustParseAddr("1.1.1.1")}},
+			},
+			newServers: []models.Server{
+				{VPN: "openvpn", UDP: true, Hostname: "a", IPs: []netip.Addr{netip.MustParseAddr("1.1.1.1")}},
+			},
+		},
+		"added_removed_and_changed": {
+			oldServers: []models.Server{
+				{VPN: "openvpn", UDP: true, Hostname: "a", IPs: []netip.Addr{
+					netip.MustParseAddr("1.1.1.1"), netip.MustParseAddr("2.2.2.2"),
+				}},
+				{VPN: "openvpn", UDP: true, Hostname: "b"},
+				{VPN: "wireguard", Hostname: "c", WgPubKey: "x"},
+			},
+			newServers: []models.Server{
+				{VPN: "openvpn", UDP: true, Hostname: "a", PortForward: true, IPs: []netip.Addr{
+					netip.MustParseAddr("2.2.2.2"), netip.MustParseAddr("3.3.3.3"),
+				}},
+				{VPN: "wireguard", Hostname: "c", WgPubKey: "y"},
+				{VPN: "wireguard", Hostname: "d"},
+			},
+			change: models.ServersChange{
+				Added:   []string{"wireguard--d"},
+				Removed: []string{"openvpn-udp-b"},
+				Changed: []models.ServerChange{
+					{
+						Key:        "openvpn-udp-a",
+						IPsAdded:   []netip.Addr{netip.MustParseAddr("3.3.3.3")},
+						IPsRemoved: []netip.Addr{netip.MustParseAddr("1.1.1.1")},
+						Fields:     []string{"port_forward"},
+					},
+					{
+						Key:    "wireguard--c",
+						Fields: []string{"wgpubkey"},
+					},
+				},
+			},
+		},
+		"duplicate_keys": {
+			oldServers: []models.Server{
+				{VPN: "openvpn", TCP: true, Hostname: "a", City: "x"},
+			},
+			newServers: []models.Server{
+				{VPN: "openvpn", TCP: true, Hostname: "a", City: "x"},
+				{VPN: "openvpn", TCP: true, Hostname: "a", City: "y"},
+			},
+			change: models.ServersChange{
+				Added: []string{"openvpn-tcp-a#2"},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			change := diffServers(testCase.oldServers, testCase.newServers)
+
+			assert.Equal(t, testCase.change, change)
+		})
+	}
+}
@@ -16,6 +16,8 @@ type Storage interface {
 	SetServers(provider string, servers []models.Server) (err error)
 	GetServersCount(provider string) (count int)
 	ServersAreEqual(provider string, servers []models.Server) (equal bool)
+	GetServers(provider string) (servers []models.Server)
+	AddServersChange(change models.ServersChange) (err error)
 	// Extra methods to match the provider.New storage interface
 	FilterServers(provider string, selection settings.ServerSelection) (filtered []models.Server, err error)
 }
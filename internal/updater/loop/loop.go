@@ -8,12 +8,14 @@ import (
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/events"
 	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/updater"
 )
 
 type Updater interface {
 	UpdateServers(ctx context.Context, providers []string, minRatio float64) (err error)
+	Progress() (progress models.UpdaterProgress)
 }
 
 type Loop struct {
@@ -21,17 +23,27 @@ type Loop struct {
 	// Objects
 	updater Updater
 	logger  Logger
+	events  EventPublisher
 	// Internal channels and locks
 	loopLock     sync.Mutex
-	start        chan struct{}
+	start        chan startRequest
 	running      chan models.LoopStatus
 	stop         chan struct{}
 	stopped      chan struct{}
 	updateTicker chan struct{}
 	backoffTime  time.Duration
 	// Mock functions
-	timeNow   func() time.Time
-	timeSince func(time.Time) time.Duration
+	timeNow func() time.Time
+}
+
+// startRequest is sent to the run loop to start updating servers.
+type startRequest struct {
+	// providers are the providers to update, and default
+	// to the providers from the settings if empty.
+	providers []string
+	// waitRunning is true if the sender waits to
+	// receive the running status from the run loop.
+	waitRunning bool
 }
 
 const defaultBackoffTime = 5 * time.Second
@@ -42,8 +54,13 @@ type Logger interface {
 	Error(s string)
 }
 
+type EventPublisher interface {
+	Publish(eventType string, data any)
+}
+
 func NewLoop(settings settings.Updater, providers updater.Providers,
-	storage updater.Storage, client *http.Client, logger Logger,
+	storage updater.Storage, client *http.Client,
+	eventPublisher EventPublisher, logger Logger,
 ) *Loop {
 	return &Loop{
 		state: state{
@@ -52,13 +69,13 @@ func NewLoop(settings settings.Updater, providers updater.Providers,
 		},
 		updater:      updater.New(client, storage, providers, logger),
 		logger:       logger,
-		start:        make(chan struct{}),
+		events:       eventPublisher,
+		start:        make(chan startRequest),
 		running:      make(chan models.LoopStatus),
 		stop:         make(chan struct{}),
 		stopped:      make(chan struct{}),
 		updateTicker: make(chan struct{}),
 		timeNow:      time.Now,
-		timeSince:    time.Since,
 		backoffTime:  defaultBackoffTime,
 	}
 }
@@ -82,8 +99,9 @@ func (l *Loop) logAndWait(ctx context.Context, err error) {
 func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 	defer close(done)
 	crashed := false
+	var request startRequest
 	select {
-	case <-l.start:
+	case request = <-l.start:
 	case <-ctx.Done():
 		return
 	}
@@ -92,28 +110,37 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 		updateCtx, updateCancel := context.WithCancel(ctx)
 
 		settings := l.GetSettings()
+		providers := request.providers
+		if len(providers) == 0 {
+			providers = settings.Providers
+		}
 
 		errorCh := make(chan error)
 		runWg := &sync.WaitGroup{}
 		runWg.Add(1)
 		go func() {
 			defer runWg.Done()
-			err := l.updater.UpdateServers(updateCtx, settings.Providers, settings.MinRatio)
+			err := l.updater.UpdateServers(updateCtx, providers, settings.MinRatio)
 			if err != nil {
 				if updateCtx.Err() == nil {
+					l.events.Publish(events.TypeUpdaterCompleted,
+						events.UpdaterCompleted{Error: err.Error()})
 					errorCh <- err
 				}
 				return
 			}
 			l.state.setStatusWithLock(constants.Completed)
+			l.events.Publish(events.TypeUpdaterCompleted, events.UpdaterCompleted{})
 		}()
 
-		if !crashed {
-			l.running <- constants.Running
-			crashed = false
-		} else {
+		switch {
+		case crashed:
 			l.backoffTime = defaultBackoffTime
 			l.state.setStatusWithLock(constants.Running)
+		case request.waitRunning:
+			l.running <- constants.Running
+		default: // started by the ticker
+			l.state.setStatusWithLock(constants.Running)
 		}
 
 		stayHere := true
@@ -124,10 +151,11 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 				runWg.Wait()
 				close(errorCh)
 				return
-			case <-l.start:
+			case request = <-l.start:
 				l.logger.Info("starting")
 				updateCancel()
 				runWg.Wait()
+				crashed = false
 				stayHere = false
 			case <-l.stop:
 				l.logger.Info("stopping")
@@ -147,16 +175,19 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 	}
 }
 
+// RunRestartTicker starts updating the servers of each provider
+// once its update period has elapsed since its last update.
 func (l *Loop) RunRestartTicker(ctx context.Context, done chan<- struct{}) {
 	defer close(done)
 	timer := time.NewTimer(time.Hour)
 	timer.Stop()
 	timerIsStopped := true
-	if period := *l.GetSettings().Period; period > 0 {
+	lastTicks := make(map[string]time.Time)
+	nextTicks := l.nextTicks(lastTicks)
+	if next, ok := earliestTick(nextTicks); ok {
 		timerIsStopped = false
-		timer.Reset(period)
+		timer.Reset(next.Sub(l.timeNow()))
 	}
-	lastTick := time.Unix(0, 0)
 	for {
 		select {
 		case <-ctx.Done():
@@ -165,25 +196,65 @@ func (l *Loop) RunRestartTicker(ctx context.Context, done chan<- struct{}) {
 			}
 			return
 		case <-timer.C:
-			lastTick = l.timeNow()
-			l.start <- struct{}{}
-			timer.Reset(*l.GetSettings().Period)
+			timerIsStopped = true
+			now := l.timeNow()
+			var dueProviders []string
+			for _, provider := range l.GetSettings().Providers {
+				next, ok := nextTicks[provider]
+				if ok && !next.After(now) {
+					dueProviders = append(dueProviders, provider)
+					lastTicks[provider] = now
+				}
+			}
+			if len(dueProviders) > 0 {
+				l.start <- startRequest{providers: dueProviders}
+			}
 		case <-l.updateTicker:
 			if !timerIsStopped && !timer.Stop() {
 				<-timer.C
 			}
 			timerIsStopped = true
-			period := *l.GetSettings().Period
-			if period == 0 {
-				continue
-			}
-			var waited time.Duration
-			if lastTick.UnixNano() > 0 {
-				waited = l.timeSince(lastTick)
-			}
-			leftToWait := period - waited
-			timer.Reset(leftToWait)
+		}
+
+		nextTicks = l.nextTicks(lastTicks)
+		if next, ok := earliestTick(nextTicks); ok {
 			timerIsStopped = false
+			timer.Reset(next.Sub(l.timeNow()))
+		}
+	}
+}
+
+// nextTicks returns the next update time of each provider
+// to update periodically, given their last update times.
+// Providers without a last update time are given the current
+// time as their last update time.
+func (l *Loop) nextTicks(lastTicks map[string]time.Time) (
+	nextTicks map[string]time.Time,
+) {
+	settings := l.GetSettings()
+	now := l.timeNow()
+	nextTicks = make(map[string]time.Time, len(settings.Providers))
+	for _, provider := range settings.Providers {
+		period := settings.ProviderPeriod(provider)
+		if period == 0 {
+			continue
+		}
+		lastTick, ok := lastTicks[provider]
+		if !ok {
+			lastTick = now
+			lastTicks[provider] = now
+		}
+		nextTicks[provider] = lastTick.Add(period)
+	}
+	return nextTicks
+}
+
+func earliestTick(ticks map[string]time.Time) (earliest time.Time, ok bool) {
+	for _, tick := range ticks {
+		if !ok || tick.Before(earliest) {
+			earliest = tick
+			ok = true
 		}
 	}
+	return earliest, ok
 }
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"sync"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/providers"
 	"github.com/qdm12/gluetun/internal/models"
 )
 
@@ -44,20 +46,7 @@ func (l *Loop) SetStatus(ctx context.Context, status models.LoopStatus) (outcome
 		case constants.Starting, constants.Running, constants.Stopping, constants.Crashed:
 			return fmt.Sprintf("already %s", existingStatus), nil
 		}
-		l.loopLock.Lock()
-		defer l.loopLock.Unlock()
-		l.state.status = constants.Starting
-		l.state.statusMu.Unlock()
-		l.start <- struct{}{}
-
-		newStatus := constants.Starting // for canceled context
-		select {
-		case <-ctx.Done():
-		case newStatus = <-l.running:
-		}
-		l.state.statusMu.Lock()
-		l.state.status = newStatus
-		return newStatus.String(), nil
+		return l.startLocked(ctx, nil), nil
 	case constants.Stopped:
 		switch existingStatus {
 		case constants.Stopped, constants.Stopping, constants.Starting, constants.Crashed:
@@ -84,6 +73,61 @@ func (l *Loop) SetStatus(ctx context.Context, status models.LoopStatus) (outcome
 	}
 }
 
+// startLocked starts updating the servers of the providers given,
+// or of the providers from the settings if providers is empty.
+// It must be called with the status mutex locked, and returns
+// with the status mutex locked.
+func (l *Loop) startLocked(ctx context.Context, providers []string) (outcome string) {
+	l.loopLock.Lock()
+	defer l.loopLock.Unlock()
+	l.state.status = constants.Starting
+	l.state.statusMu.Unlock()
+	l.start <- startRequest{providers: providers, waitRunning: true}
+
+	newStatus := constants.Starting // for canceled context
+	select {
+	case <-ctx.Done():
+	case newStatus = <-l.running:
+	}
+	l.state.statusMu.Lock()
+	l.state.status = newStatus
+	return newStatus.String()
+}
+
+var (
+	ErrNoProviderToUpdate   = errors.New("no provider to update")
+	ErrProviderNotUpdatable = errors.New("provider cannot be updated")
+)
+
+// UpdateProviders starts updating the servers of the providers given,
+// which can be different from the providers from the settings.
+func (l *Loop) UpdateProviders(ctx context.Context, providerNames []string) (
+	outcome string, err error,
+) {
+	if len(providerNames) == 0 {
+		return "", fmt.Errorf("%w", ErrNoProviderToUpdate)
+	}
+	validProviders := providers.All()
+	for _, provider := range providerNames {
+		if !slices.Contains(validProviders, provider) {
+			return "", fmt.Errorf("%w: %s", ErrProviderNotUpdatable, provider)
+		}
+	}
+
+	l.state.statusMu.Lock()
+	defer l.state.statusMu.Unlock()
+	switch existingStatus := l.state.status; existingStatus {
+	case constants.Starting, constants.Running, constants.Stopping, constants.Crashed:
+		return fmt.Sprintf("already %s", existingStatus), nil
+	}
+	return l.startLocked(ctx, providerNames), nil
+}
+
+// GetProgress returns the progress of the current or last servers update.
+func (l *Loop) GetProgress() (progress models.UpdaterProgress) {
+	return l.updater.Progress()
+}
+
 func (l *Loop) GetSettings() (settings settings.Updater) {
 	l.state.periodMu.RLock()
 	defer l.state.periodMu.RUnlock()
@@ -0,0 +1,57 @@
+package updater
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+type progress struct {
+	data  models.UpdaterProgress
+	mutex sync.RWMutex
+}
+
+func (p *progress) reset(providers []string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.data = models.UpdaterProgress{
+		Providers:      slices.Clone(providers),
+		ServersFetched: make(map[string]int, len(providers)),
+		Errors:         make(map[string]string),
+	}
+}
+
+func (p *progress) setCurrentProvider(provider string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.data.CurrentProvider = provider
+}
+
+func (p *progress) setServersFetched(provider string, count int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.data.ServersFetched[provider] = count
+}
+
+func (p *progress) setError(provider string, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.data.Errors[provider] = err.Error()
+}
+
+func (p *progress) get() (data models.UpdaterProgress) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	data = p.data
+	data.Providers = slices.Clone(data.Providers)
+	data.ServersFetched = maps.Clone(data.ServersFetched)
+	data.Errors = maps.Clone(data.Errors)
+	return data
+}
+
+// Progress returns the progress of the current or last servers update.
+func (u *Updater) Progress() (progress models.UpdaterProgress) {
+	return u.progress.get()
+}
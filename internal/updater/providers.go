@@ -31,6 +31,7 @@ func (u *Updater) updateProvider(ctx context.Context, provider Provider,
 		}
 		return fmt.Errorf("getting %s servers: %w", providerName, err)
 	}
+	u.progress.setServersFetched(providerName, len(servers))
 
 	for _, server := range servers {
 		err := server.HasMinimumInformation()
@@ -47,6 +48,10 @@ func (u *Updater) updateProvider(ctx context.Context, provider Provider,
 		return nil
 	}
 
+	change := diffServers(u.storage.GetServers(providerName), servers)
+	change.Provider = providerName
+	change.Timestamp = u.timeNow().Unix()
+
 	// Note the servers variable must NOT BE MUTATED after this call,
 	// since the implementation does not deep copy the servers.
 	// TODO set in storage in provider updater directly, server by server,
@@ -55,5 +60,15 @@ func (u *Updater) updateProvider(ctx context.Context, provider Provider,
 	if err != nil {
 		return fmt.Errorf("setting servers to storage: %w", err)
 	}
+
+	if change.IsEmpty() {
+		return nil
+	}
+	u.logger.Info(fmt.Sprintf("%s servers: %d added, %d removed, %d changed",
+		providerName, len(change.Added), len(change.Removed), len(change.Changed)))
+	err = u.storage.AddServersChange(change)
+	if err != nil {
+		return fmt.Errorf("adding servers change to storage: %w", err)
+	}
 	return nil
 }
@@ -16,7 +16,8 @@ type Updater struct {
 	providers Providers
 
 	// state
-	storage Storage
+	storage  Storage
+	progress progress
 
 	// Functions for tests
 	logger   Logger
@@ -42,14 +43,21 @@ func New(httpClient *http.Client, storage Storage,
 func (u *Updater) UpdateServers(ctx context.Context, providers []string,
 	minRatio float64,
 ) (err error) {
+	u.progress.reset(providers)
+	defer u.progress.setCurrentProvider("")
+
 	caser := cases.Title(language.English)
 	for _, providerName := range providers {
 		u.logger.Info("updating " + caser.String(providerName) + " servers...")
+		u.progress.setCurrentProvider(providerName)
 
 		fetcher := u.providers.Get(providerName)
 		// TODO support servers offering only TCP or only UDP
 		// for NordVPN and PureVPN
 		err := u.updateProvider(ctx, fetcher, minRatio)
+		if err != nil {
+			u.progress.setError(providerName, err)
+		}
 		switch {
 		case err == nil:
 			continue
@@ -3,9 +3,15 @@ package vpn
 import (
 	"context"
 	"errors"
+
+	"github.com/qdm12/gluetun/internal/events"
 )
 
 func (l *Loop) cleanup() {
+	if l.tunnelUp.Swap(false) {
+		l.events.Publish(events.TypeTunnelDown, nil)
+	}
+
 	for _, vpnPort := range l.vpnInputPorts {
 		err := l.fw.RemoveAllowedPort(context.Background(), vpnPort)
 		if err != nil {
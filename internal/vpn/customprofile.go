@@ -0,0 +1,45 @@
+package vpn
+
+import (
+	"fmt"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/provider"
+	"github.com/qdm12/gluetun/internal/provider/custom"
+)
+
+type customProfileSelector interface {
+	SelectProfile(vpnSettings settings.VPN) (
+		selected settings.VPN, profile custom.Profile, err error)
+}
+
+// selectCustomProfile returns the settings given modified to use a
+// profile of the custom configuration directory, if it is set.
+func (l *Loop) selectCustomProfile(providerConf provider.Provider,
+	vpnSettings settings.VPN,
+) (selected settings.VPN, err error) {
+	if *vpnSettings.Provider.ServerSelection.CustomConfigDir == "" {
+		return vpnSettings, nil
+	}
+
+	selector, ok := providerConf.(customProfileSelector)
+	if !ok {
+		// settings validation only allows the directory for the custom provider
+		panic(fmt.Sprintf("provider %s does not support custom profiles", providerConf.Name()))
+	}
+
+	selected, profile, err := selector.SelectProfile(vpnSettings)
+	if err != nil {
+		return vpnSettings, fmt.Errorf("selecting custom profile: %w", err)
+	}
+
+	message := "selected custom profile " + profile.Name
+	if profile.Country != "" {
+		message += " in country " + profile.Country
+	}
+	if profile.City != "" {
+		message += " in city " + profile.City
+	}
+	l.logger.Info(message + " from file " + profile.Filepath)
+	return selected, nil
+}
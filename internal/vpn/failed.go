@@ -0,0 +1,66 @@
+package vpn
+
+import (
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/models"
+)
+
+// failedServers is the set of VPN server IP addresses which
+// failed to connect or failed the healthcheck, so they can be
+// skipped by the latency selection strategy.
+type failedServers struct {
+	ips   map[netip.Addr]struct{}
+	mutex sync.RWMutex
+}
+
+func newFailedServers() *failedServers {
+	return &failedServers{
+		ips: make(map[netip.Addr]struct{}),
+	}
+}
+
+func (f *failedServers) add(ip netip.Addr) {
+	if !ip.IsValid() {
+		return
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.ips[ip] = struct{}{}
+}
+
+func (f *failedServers) contains(ip netip.Addr) (ok bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	_, ok = f.ips[ip]
+	return ok
+}
+
+func (f *failedServers) clear() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	clear(f.ips)
+}
+
+// recordFailure records the failure of a VPN server, so it is skipped
+// by the latency selection strategy and blacklisted by the storage
+// after repeated failures.
+func (l *Loop) recordFailure(provider, hostname string, ip netip.Addr,
+	failureType string, failureErr error,
+) {
+	l.failedServers.add(ip)
+	failure := models.ServerFailure{
+		Provider: provider,
+		Hostname: hostname,
+		IP:       ip,
+		Time:     time.Now(),
+		Type:     failureType,
+		Error:    failureErr.Error(),
+	}
+	err := l.storage.RecordServerFailure(failure)
+	if err != nil {
+		l.logger.Warn("recording server failure: " + err.Error())
+	}
+}
@@ -18,6 +18,8 @@ type Firewall interface {
 	SetVPNConnection(ctx context.Context, connection models.Connection, interfaceName string) error
 	SetAllowedPort(ctx context.Context, port uint16, interfaceName string) error
 	RemoveAllowedPort(ctx context.Context, port uint16) error
+	TempAllowOutputToIPs(ctx context.Context, ips []netip.Addr) (
+		revert func(ctx context.Context) error, err error)
 	tcp.Firewall
 }
 
@@ -57,6 +59,7 @@ type PortForwarder interface {
 
 type Storage interface {
 	FilterServers(provider string, selection settings.ServerSelection) (servers []models.Server, err error)
+	RecordServerFailure(failure models.ServerFailure) (err error)
 }
 
 type NetLinker interface {
@@ -97,6 +100,11 @@ type DNSLoop interface {
 type PublicIPLoop interface {
 	RunOnce(ctx context.Context) (err error)
 	ClearData() (err error)
+	GetData() (data models.PublicIP)
+}
+
+type EventPublisher interface {
+	Publish(eventType string, data any)
 }
 
 type CmdStarter interface {
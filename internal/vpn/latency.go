@@ -0,0 +1,236 @@
+package vpn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/healthcheck/icmp"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider"
+)
+
+type latencyCandidate struct {
+	hostname   string
+	serverName string
+	ip         netip.Addr
+	latency    time.Duration
+}
+
+var errNoServerResponded = errors.New("no server responded to the latency probe")
+
+// selectLowestLatency returns a copy of the server selection given,
+// narrowed down to the filtered server with the lowest latency.
+// Servers which previously failed are skipped, unless all the
+// filtered servers failed in which case the failed set is cleared.
+// If no server can be probed, the selection is returned unchanged
+// so the provider picks a random connection as usual.
+func (l *Loop) selectLowestLatency(ctx context.Context,
+	providerConf provider.Provider, vpnSettings settings.VPN,
+) (selection settings.ServerSelection) {
+	selection = vpnSettings.Provider.ServerSelection
+	if selection.Strategy != settings.SelectionStrategyLatency {
+		return selection
+	}
+
+	endpointIP := selection.OpenVPN.EndpointIP
+	if vpnSettings.Type == vpn.Wireguard {
+		endpointIP = selection.Wireguard.EndpointIP
+	}
+	if endpointIP.IsValid() && !endpointIP.IsUnspecified() {
+		// the user already pinned the server IP address
+		return selection
+	}
+
+	candidate, err := l.probeLowestLatency(ctx, providerConf, selection)
+	if err != nil {
+		if ctx.Err() == nil {
+			l.logger.Warn("selecting server by latency: " + err.Error() +
+				" (falling back to a random server)")
+		}
+		return selection
+	}
+
+	l.logger.Infof("selected server %s with the lowest latency of %s",
+		candidate, candidate.latency.Round(time.Millisecond))
+
+	return pinSelection(selection, candidate)
+}
+
+// pinSelection returns the server selection given narrowed down
+// to the server hostname or name and IP address of the candidate.
+func pinSelection(selection settings.ServerSelection,
+	candidate latencyCandidate,
+) (pinned settings.ServerSelection) {
+	// Note assigning new slices and values does not mutate
+	// the settings state shared with the selection given.
+	pinned = selection
+	switch {
+	case candidate.hostname != "":
+		pinned.Hostnames = []string{candidate.hostname}
+	case candidate.serverName != "":
+		pinned.Names = []string{candidate.serverName}
+	}
+	if pinned.VPN == vpn.OpenVPN {
+		pinned.OpenVPN.EndpointIP = candidate.ip
+	} else {
+		pinned.Wireguard.EndpointIP = candidate.ip
+	}
+	return pinned
+}
+
+func (l *Loop) probeLowestLatency(ctx context.Context,
+	providerConf provider.Provider, selection settings.ServerSelection,
+) (best latencyCandidate, err error) {
+	// Get a reference connection to find the protocol and port to probe,
+	// since these depend on provider specific defaults.
+	reference, err := providerConf.GetConnection(selection, l.ipv6Supported)
+	if err != nil {
+		return best, fmt.Errorf("finding a reference connection: %w", err)
+	}
+
+	servers, err := l.storage.FilterServers(providerConf.Name(), selection)
+	if err != nil {
+		return best, fmt.Errorf("filtering servers: %w", err)
+	}
+
+	candidates := l.buildLatencyCandidates(servers, selection)
+	if len(candidates) == 0 {
+		l.logger.Info("all filtered servers previously failed, clearing failed servers")
+		l.failedServers.clear()
+		candidates = l.buildLatencyCandidates(servers, selection)
+		if len(candidates) == 0 {
+			return best, errNoServerResponded
+		}
+	}
+
+	const maxCandidates = 10
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	candidates = candidates[:min(len(candidates), maxCandidates)]
+
+	ips := make([]netip.Addr, len(candidates))
+	for i, candidate := range candidates {
+		ips[i] = candidate.ip
+	}
+	revert, err := l.fw.TempAllowOutputToIPs(ctx, ips)
+	if err != nil {
+		return best, fmt.Errorf("allowing probe traffic through firewall: %w", err)
+	}
+	defer func() {
+		// use a fresh context to remove the firewall rules
+		// even if the parent context is canceled.
+		revertErr := revert(context.Background())
+		if revertErr != nil {
+			l.logger.Error("reverting probe firewall rules: " + revertErr.Error())
+		}
+	}()
+
+	const probeTimeout = 2 * time.Second
+	probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := range candidates {
+		wg.Add(1)
+		go func(candidate *latencyCandidate) {
+			defer wg.Done()
+			latency, err := probeLatency(probeCtx, candidate.ip,
+				reference.Protocol, reference.Port, l.logger)
+			if err != nil {
+				l.logger.Debugf("probing server %s: %s", candidate, err)
+				return
+			}
+			candidate.latency = latency
+		}(&candidates[i])
+	}
+	wg.Wait()
+
+	for _, candidate := range candidates {
+		if candidate.latency == 0 {
+			continue
+		}
+		if best.latency == 0 || candidate.latency < best.latency {
+			best = candidate
+		}
+	}
+	if best.latency == 0 {
+		return best, fmt.Errorf("%w: out of %d servers", errNoServerResponded, len(candidates))
+	}
+	return best, nil
+}
+
+func (l *Loop) buildLatencyCandidates(servers []models.Server,
+	selection settings.ServerSelection,
+) (candidates []latencyCandidate) {
+	for _, server := range servers {
+		if server.Hostname == "" && server.ServerName == "" &&
+			selection.VPN == vpn.OpenVPN {
+			// the server cannot be pinned without changing the
+			// hostname used for TLS verification.
+			continue
+		}
+		for _, ip := range server.IPs {
+			if (ip.Is6() && !l.ipv6Supported) || l.failedServers.contains(ip) {
+				continue
+			}
+			candidates = append(candidates, latencyCandidate{
+				hostname:   server.Hostname,
+				serverName: server.ServerName,
+				ip:         ip,
+			})
+		}
+	}
+	return candidates
+}
+
+func (c latencyCandidate) String() string {
+	switch {
+	case c.hostname != "":
+		return c.hostname + " (" + c.ip.String() + ")"
+	case c.serverName != "":
+		return c.serverName + " (" + c.ip.String() + ")"
+	default:
+		return c.ip.String()
+	}
+}
+
+// probeLatency measures the latency to the IP address given.
+// If the protocol is TCP, the latency is the time taken to establish
+// a TCP connection to the port given, otherwise it is the round trip
+// time of an ICMP echo request, since UDP VPN servers do not reply
+// to arbitrary packets.
+func probeLatency(ctx context.Context, ip netip.Addr,
+	protocol string, port uint16, logger icmp.Logger,
+) (latency time.Duration, err error) {
+	start := time.Now()
+	if protocol == constants.TCP {
+		dialer := net.Dialer{}
+		address := net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return 0, fmt.Errorf("dialing TCP: %w", err)
+		}
+		latency = time.Since(start)
+		_ = conn.Close()
+		return latency, nil
+	}
+
+	echoer := icmp.NewEchoer(logger)
+	echoer.Reset()
+	err = echoer.Echo(ctx, ip)
+	if err != nil {
+		return 0, fmt.Errorf("sending ICMP echo: %w", err)
+	}
+	return time.Since(start), nil
+}
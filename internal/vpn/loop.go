@@ -2,10 +2,12 @@ package vpn
 
 import (
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/qdm12/gluetun/internal/configuration/settings"
 	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/events"
 	"github.com/qdm12/gluetun/internal/loopstate"
 	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/vpn/state"
@@ -33,6 +35,7 @@ type Loop struct {
 	portForward PortForward
 	publicip    PublicIPLoop
 	dnsLooper   DNSLoop
+	events      EventPublisher
 	// Other objects
 	starter CmdStarter // for OpenVPN
 	logger  log.LoggerInterface
@@ -43,6 +46,18 @@ type Loop struct {
 	start       <-chan struct{}
 	running     chan<- models.LoopStatus
 	userTrigger bool
+	// reconnects counts the number of VPN connections
+	// started after the first one, for metrics purposes.
+	reconnects atomic.Uint64
+	// tunnelUp is true once the tunnel is up, and is
+	// set back to false when the connection is cleaned up.
+	tunnelUp atomic.Bool
+	// failedServers contains server IP addresses which failed
+	// to connect or failed the healthcheck, and is used by the
+	// latency server selection strategy.
+	failedServers *failedServers
+	// rotation is the state of server rotations.
+	rotation rotation
 	// Internal constant values
 	backoffTime time.Duration
 }
@@ -56,7 +71,7 @@ func NewLoop(vpnSettings settings.VPN, ipv6Supported bool, vpnInputPorts []uint1
 	healthChecker HealthChecker, healthServer HealthServer, openvpnConf OpenVPN,
 	netLinker NetLinker, fw Firewall, routing Routing,
 	portForward PortForward, starter CmdStarter,
-	publicip PublicIPLoop, dnsLooper DNSLoop,
+	publicip PublicIPLoop, dnsLooper DNSLoop, eventPublisher EventPublisher,
 	logger log.LoggerInterface, client *http.Client,
 	buildInfo models.BuildInformation, versionInfo bool,
 ) *Loop {
@@ -65,7 +80,10 @@ func NewLoop(vpnSettings settings.VPN, ipv6Supported bool, vpnInputPorts []uint1
 	stop := make(chan struct{})
 	stopped := make(chan struct{})
 
-	statusManager := loopstate.New(constants.Stopped, start, running, stop, stopped)
+	onStatusChange := func(status models.LoopStatus) {
+		eventPublisher.Publish(events.TypeVPNStatus, events.VPNStatus{Status: status})
+	}
+	statusManager := loopstate.New(constants.Stopped, start, running, stop, stopped, onStatusChange)
 	state := state.New(statusManager, vpnSettings)
 
 	return &Loop{
@@ -87,6 +105,7 @@ func NewLoop(vpnSettings settings.VPN, ipv6Supported bool, vpnInputPorts []uint1
 		portForward:    portForward,
 		publicip:       publicip,
 		dnsLooper:      dnsLooper,
+		events:         eventPublisher,
 		starter:        starter,
 		logger:         logger,
 		client:         client,
@@ -95,6 +114,7 @@ func NewLoop(vpnSettings settings.VPN, ipv6Supported bool, vpnInputPorts []uint1
 		stop:           stop,
 		stopped:        stopped,
 		userTrigger:    true,
+		failedServers:  newFailedServers(),
 		backoffTime:    defaultBackoffTime,
 	}
 }
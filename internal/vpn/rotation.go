@@ -0,0 +1,242 @@
+package vpn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qdm12/gluetun/internal/configuration/settings"
+	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/constants/providers"
+	"github.com/qdm12/gluetun/internal/constants/vpn"
+	"github.com/qdm12/gluetun/internal/models"
+	"github.com/qdm12/gluetun/internal/provider"
+)
+
+// rotation contains the state of server rotations, which
+// reconnect the VPN to a server different from the current one.
+type rotation struct {
+	// serverIP is the IP address of the server
+	// the tunnel is currently up with.
+	serverIP netip.Addr
+	// pending is true from the time a rotation is triggered until
+	// the public IP data is checked once the tunnel is up again.
+	pending bool
+	// excludedIPs are the server IP addresses used before the
+	// rotation, including the ones from retried rotations.
+	excludedIPs []netip.Addr
+	// previousPublicIP is the public IP data before the rotation.
+	previousPublicIP models.PublicIP
+	// attempts is the number of rotations done for the pending rotation.
+	attempts uint
+	mutex    sync.Mutex
+}
+
+func (r *rotation) setServerIP(ip netip.Addr) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.serverIP = ip
+}
+
+// begin records the current server and public IP data, which are kept
+// from the first attempt if a rotation is already pending.
+func (r *rotation) begin(publicIP models.PublicIP) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.pending {
+		r.pending = true
+		r.excludedIPs = nil
+		r.previousPublicIP = publicIP
+		r.attempts = 0
+	}
+	if r.serverIP.IsValid() {
+		r.excludedIPs = append(r.excludedIPs, r.serverIP)
+	}
+	r.attempts++
+}
+
+func (r *rotation) end() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.pending = false
+	r.excludedIPs = nil
+}
+
+func (r *rotation) get() (pending bool, excludedIPs []netip.Addr,
+	previousPublicIP models.PublicIP, attempts uint,
+) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.pending, slices.Clone(r.excludedIPs), r.previousPublicIP, r.attempts
+}
+
+var ErrVPNNotRunning = errors.New("VPN is not running")
+
+// Rotate reconnects the VPN to a server different from the
+// current one, picked from the filtered servers.
+func (l *Loop) Rotate(ctx context.Context) (outcome string, err error) {
+	return l.rotate(ctx, "rotation requested")
+}
+
+// rotate must be called in a goroutine other than the VPN loop goroutine.
+func (l *Loop) rotate(ctx context.Context, reason string) (outcome string, err error) {
+	if l.GetStatus() != constants.Running {
+		return "", fmt.Errorf("%w", ErrVPNNotRunning)
+	}
+
+	l.rotation.begin(l.publicip.GetData())
+	l.logger.Info(reason + ", reconnecting to a different server")
+	_, err = l.ApplyStatus(ctx, constants.Stopped)
+	if err != nil {
+		return "", fmt.Errorf("stopping VPN: %w", err)
+	}
+	outcome, err = l.ApplyStatus(ctx, constants.Running)
+	if err != nil {
+		return "", fmt.Errorf("starting VPN: %w", err)
+	}
+	return outcome, nil
+}
+
+// rotateOnSchedule rotates the VPN server at the next rotation time,
+// if the rotation is enabled. It returns when the context is canceled,
+// which happens when the VPN reconnects for any reason.
+func (l *Loop) rotateOnSchedule(ctx, loopCtx context.Context) {
+	rotationSettings := l.state.GetSettings().Rotation
+	next := rotationSettings.NextRotation(time.Now())
+	if next.IsZero() {
+		return
+	}
+	l.logger.Infof("next server rotation at %s", next.Format(time.DateTime))
+
+	timer := time.NewTimer(time.Until(next))
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return
+	case <-timer.C:
+	}
+
+	_, err := l.rotate(loopCtx, "scheduled server rotation")
+	if err != nil && loopCtx.Err() == nil {
+		l.logger.Error("rotating server: " + err.Error())
+	}
+}
+
+// checkRotation checks the public IP data changed as required after
+// a rotation, and rotates again if it did not, up to a few attempts.
+// It must be called once the public IP data is fetched after the tunnel is up.
+func (l *Loop) checkRotation(loopCtx context.Context) {
+	pending, _, previous, attempts := l.rotation.get()
+	if !pending {
+		return
+	}
+
+	current := l.publicip.GetData()
+	var field, value string
+	switch l.state.GetSettings().Rotation.RequireDifferent {
+	case settings.RotationRequireDifferentIP:
+		if current.IP.IsValid() && current.IP == previous.IP {
+			field, value = "IP address", current.IP.String()
+		}
+	case settings.RotationRequireDifferentCity:
+		if current.City != "" && strings.EqualFold(current.City, previous.City) {
+			field, value = "city", current.City
+		}
+	}
+
+	if field == "" {
+		l.rotation.end()
+		if previous.IP.IsValid() && current.IP.IsValid() {
+			l.logger.Infof("public IP address rotated from %s to %s", previous.IP, current.IP)
+		}
+		return
+	}
+
+	const maxAttempts = 3
+	if attempts >= maxAttempts {
+		l.rotation.end()
+		l.logger.Warnf("public %s is still %s after %d server rotations",
+			field, value, attempts)
+		return
+	}
+
+	reason := fmt.Sprintf("public %s is still %s after server rotation", field, value)
+	_, err := l.rotate(loopCtx, reason)
+	if err != nil && loopCtx.Err() == nil {
+		l.logger.Error("rotating server: " + err.Error())
+	}
+}
+
+// selectRotationServer returns a copy of the server selection given,
+// narrowed down to a random filtered server different from the servers
+// used before a pending rotation. The selection is returned unchanged if
+// no rotation is pending, or if no other server is available.
+func (l *Loop) selectRotationServer(providerConf provider.Provider,
+	vpnSettings settings.VPN,
+) (selection settings.ServerSelection) {
+	selection = vpnSettings.Provider.ServerSelection
+	pending, excludedIPs, previousPublicIP, _ := l.rotation.get()
+	if !pending || vpnSettings.Provider.Name == providers.Custom {
+		// the custom provider rotates its profiles on each connection.
+		return selection
+	}
+
+	endpointIP := selection.OpenVPN.EndpointIP
+	if vpnSettings.Type == vpn.Wireguard {
+		endpointIP = selection.Wireguard.EndpointIP
+	}
+	if endpointIP.IsValid() && !endpointIP.IsUnspecified() {
+		// the user already pinned the server IP address
+		return selection
+	}
+
+	servers, err := l.storage.FilterServers(providerConf.Name(), selection)
+	if err != nil {
+		l.logger.Warn("filtering servers for rotation: " + err.Error())
+		return selection
+	}
+
+	if previousPublicIP.IP.IsValid() {
+		excludedIPs = append(excludedIPs, previousPublicIP.IP)
+	}
+	requireDifferentCity := vpnSettings.Rotation.RequireDifferent == settings.RotationRequireDifferentCity &&
+		previousPublicIP.City != ""
+
+	var candidates []latencyCandidate
+	for _, server := range servers {
+		if server.Hostname == "" && server.ServerName == "" &&
+			selection.VPN == vpn.OpenVPN {
+			// the server cannot be pinned without changing the
+			// hostname used for TLS verification.
+			continue
+		} else if requireDifferentCity && strings.EqualFold(server.City, previousPublicIP.City) {
+			continue
+		}
+		for _, ip := range server.IPs {
+			if (ip.Is6() && !l.ipv6Supported) || slices.Contains(excludedIPs, ip) {
+				continue
+			}
+			candidates = append(candidates, latencyCandidate{
+				hostname:   server.Hostname,
+				serverName: server.ServerName,
+				ip:         ip,
+			})
+		}
+	}
+
+	if len(candidates) == 0 {
+		l.logger.Warnf("no other server available to rotate to, out of %d filtered servers",
+			len(servers))
+		return selection
+	}
+
+	candidate := candidates[rand.IntN(len(candidates))] //nolint:gosec
+	l.logger.Infof("rotating to server %s", candidate)
+	return pinSelection(selection, candidate)
+}
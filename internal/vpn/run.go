@@ -18,11 +18,27 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 		return
 	}
 
+	firstConnection := true
 	for ctx.Err() == nil {
+		if firstConnection {
+			firstConnection = false
+		} else {
+			l.reconnects.Add(1)
+		}
+
 		settings := l.state.GetSettings()
 
 		providerConf := l.providers.Get(settings.Provider.Name)
 
+		settings.Provider.ServerSelection = l.selectRotationServer(providerConf, settings)
+		settings.Provider.ServerSelection = l.selectLowestLatency(ctx, providerConf, settings)
+
+		settings, err := l.selectCustomProfile(providerConf, settings)
+		if err != nil {
+			l.crashed(ctx, err)
+			continue
+		}
+
 		portForwarder := getPortForwarder(providerConf, l.providers,
 			*settings.Provider.PortForwarding.Provider)
 
@@ -31,7 +47,6 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 		}
 		var vpnInterface string
 		var connection models.Connection
-		var err error
 		subLogger := l.logger.New(log.SetComponent(settings.Type))
 		if settings.Type == vpn.OpenVPN {
 			vpnInterface = settings.OpenVPN.Interface
@@ -54,7 +69,9 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 				icmpAddrs: settings.PMTUD.ICMPAddresses,
 				tcpAddrs:  settings.PMTUD.TCPAddresses,
 			},
+			provider:       settings.Provider.Name,
 			serverIP:       connection.IP,
+			serverHostname: connection.Hostname,
 			serverName:     connection.ServerName,
 			canPortForward: connection.PortForward,
 			portForwarder:  portForwarder,
@@ -71,6 +88,8 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 
 		if err := l.waitForError(ctx, waitError); err != nil {
 			vpnCancel()
+			l.recordFailure(settings.Provider.Name, connection.Hostname, connection.IP,
+				models.ServerFailureConnection, err)
 			l.crashed(ctx, err)
 			continue
 		}
@@ -107,6 +126,8 @@ func (l *Loop) Run(ctx context.Context, done chan<- struct{}) {
 
 				l.cleanup()
 				vpnCancel()
+				l.recordFailure(settings.Provider.Name, connection.Hostname, connection.IP,
+					models.ServerFailureConnection, err)
 				l.statusManager.SetStatus(constants.Crashed)
 				l.logAndWait(ctx, err)
 				stayHere = false
@@ -15,3 +15,10 @@ func (l *Loop) ApplyStatus(ctx context.Context, status models.LoopStatus) (
 ) {
 	return l.statusManager.ApplyStatus(ctx, status)
 }
+
+// GetReconnects returns the number of VPN connections started
+// after the first one, either due to a crash, a failed healthcheck
+// or a user triggered restart.
+func (l *Loop) GetReconnects() (reconnects uint64) {
+	return l.reconnects.Load()
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/qdm12/dns/v2/pkg/check"
 	"github.com/qdm12/gluetun/internal/constants"
+	"github.com/qdm12/gluetun/internal/events"
+	"github.com/qdm12/gluetun/internal/models"
 	"github.com/qdm12/gluetun/internal/pmtud"
 	pconstants "github.com/qdm12/gluetun/internal/pmtud/constants"
 	"github.com/qdm12/gluetun/internal/pmtud/tcp"
@@ -17,8 +19,10 @@ import (
 
 type tunnelUpData struct {
 	// Healthcheck
-	serverIP netip.Addr
-	pmtud    tunnelUpPMTUDData
+	provider       string
+	serverIP       netip.Addr
+	serverHostname string
+	pmtud          tunnelUpPMTUDData
 	// Port forwarding
 	vpnIntf        string
 	serverName     string // used for PIA
@@ -48,6 +52,14 @@ type tunnelUpPMTUDData struct {
 func (l *Loop) onTunnelUp(ctx, loopCtx context.Context, data tunnelUpData) {
 	l.client.CloseIdleConnections()
 
+	l.tunnelUp.Store(true)
+	l.rotation.setServerIP(data.serverIP)
+	l.events.Publish(events.TypeTunnelUp, events.TunnelUp{
+		ServerIP:   data.serverIP,
+		ServerName: data.serverName,
+		Interface:  data.vpnIntf,
+	})
+
 	for _, vpnPort := range l.vpnInputPorts {
 		err := l.fw.SetAllowedPort(ctx, vpnPort, data.vpnIntf)
 		if err != nil {
@@ -75,10 +87,11 @@ func (l *Loop) onTunnelUp(ctx, loopCtx context.Context, data tunnelUpData) {
 	healthErrCh, err := l.healthChecker.Start(ctx)
 	l.healthServer.SetError(err)
 	if err != nil {
+		l.events.Publish(events.TypeHealthCheck, events.HealthCheck{Error: err.Error()})
 		if *l.healthSettings.RestartVPN {
 			// Note this restart call must be done in a separate goroutine
 			// from the VPN loop goroutine.
-			l.restartVPN(loopCtx, err)
+			l.restartVPN(loopCtx, data, err)
 			return
 		}
 		l.logger.Warnf("(ignored) healthchecker start failed: %s", err)
@@ -88,7 +101,7 @@ func (l *Loop) onTunnelUp(ctx, loopCtx context.Context, data tunnelUpData) {
 	// Start collecting health errors asynchronously, since
 	// we should not wait for the code below to complete
 	// to start monitoring health and auto-healing.
-	go l.collectHealthErrors(ctx, loopCtx, healthErrCh)
+	go l.collectHealthErrors(ctx, loopCtx, data, healthErrCh)
 
 	if *l.dnsLooper.GetSettings().ServerEnabled {
 		_, _ = l.dnsLooper.ApplyStatus(ctx, constants.Running)
@@ -104,6 +117,9 @@ func (l *Loop) onTunnelUp(ctx, loopCtx context.Context, data tunnelUpData) {
 		l.logger.Error("getting public IP address information: " + err.Error())
 	}
 
+	l.checkRotation(loopCtx)
+	go l.rotateOnSchedule(ctx, loopCtx)
+
 	if l.versionInfo {
 		l.versionInfo = false // only get the version information once
 		message, err := version.GetMessage(ctx, l.buildInfo, l.client)
@@ -120,7 +136,9 @@ func (l *Loop) onTunnelUp(ctx, loopCtx context.Context, data tunnelUpData) {
 	}
 }
 
-func (l *Loop) collectHealthErrors(ctx, loopCtx context.Context, healthErrCh <-chan error) {
+func (l *Loop) collectHealthErrors(ctx, loopCtx context.Context,
+	data tunnelUpData, healthErrCh <-chan error,
+) {
 	var previousHealthErr error
 	for {
 		select {
@@ -130,16 +148,18 @@ func (l *Loop) collectHealthErrors(ctx, loopCtx context.Context, healthErrCh <-c
 		case healthErr := <-healthErrCh:
 			l.healthServer.SetError(healthErr)
 			if healthErr != nil {
+				l.events.Publish(events.TypeHealthCheck, events.HealthCheck{Error: healthErr.Error()})
 				if *l.healthSettings.RestartVPN {
 					// Note this restart call must be done in a separate goroutine
 					// from the VPN loop goroutine.
 					_ = l.healthChecker.Stop()
-					l.restartVPN(loopCtx, healthErr)
+					l.restartVPN(loopCtx, data, healthErr)
 					return
 				}
 				l.logger.Warnf("(ignored) healthcheck failed: %s", healthErr)
 				l.logger.Info("👉 See https://github.com/qdm12/gluetun-wiki/blob/main/faq/healthcheck.md")
 			} else if previousHealthErr != nil {
+				l.events.Publish(events.TypeHealthCheck, events.HealthCheck{Healthy: true})
 				l.logger.Info("healthcheck passed successfully after previous failure(s)")
 			}
 			previousHealthErr = healthErr
@@ -147,7 +167,9 @@ func (l *Loop) collectHealthErrors(ctx, loopCtx context.Context, healthErrCh <-c
 	}
 }
 
-func (l *Loop) restartVPN(ctx context.Context, healthErr error) {
+func (l *Loop) restartVPN(ctx context.Context, data tunnelUpData, healthErr error) {
+	l.recordFailure(data.provider, data.serverHostname, data.serverIP,
+		models.ServerFailureHealthcheck, healthErr)
 	l.logger.Warnf("restarting VPN because it failed to pass the healthcheck: %s", healthErr)
 	l.logger.Info("👉 See https://github.com/qdm12/gluetun-wiki/blob/main/faq/healthcheck.md")
 	l.logger.Info("DO NOT OPEN AN ISSUE UNLESS YOU HAVE READ AND TRIED EVERY POSSIBLE SOLUTION")